@@ -3,66 +3,666 @@ package main
 import (
 	"container/list"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"os"
 	"os/signal"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/cli"
+	"github.com/iamramtin/binance-trader/internal/config"
+	"github.com/iamramtin/binance-trader/internal/control"
+	"github.com/iamramtin/binance-trader/internal/dashboard"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/execution"
+	"github.com/iamramtin/binance-trader/internal/logging"
+	"github.com/iamramtin/binance-trader/internal/metrics"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/secrets"
+	"github.com/iamramtin/binance-trader/internal/shutdown"
 	"github.com/iamramtin/binance-trader/internal/trader"
 	"github.com/iamramtin/binance-trader/internal/utils"
+	"github.com/iamramtin/binance-trader/internal/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/term"
 )
 
+// Time allowed for a one-off command, including connecting
+const commandTimeout = 30 * time.Second
+
+// Time allowed for control API requests to finish on exit
+const controlShutdownTimeout = 5 * time.Second
+
+// Clock offset from the exchange worth a warning; signed requests are rejected
+// once it exceeds the receive window
+const maxTimeOffset = time.Second
+
 type Timers struct {
 	OrderBook    *time.Ticker
 	ManualTrade  *time.Ticker
 	ManualCancel *time.Ticker
 	OrderSummary *time.Ticker
+	TimeSync     *time.Ticker
 }
 
-type Config struct {
-	Symbol           string
-	Quantity         float64
-	SpreadPercentage float64
-	Price            string
-	TickSize         string
-	OrderbookDepth   int
-	WebSocketURL     string
-	APIKey           string
-	SecretKey        string
+type TradingComponents struct {
+	ManualOrderQueue *list.List
+	ManualMutex      sync.Mutex
+	Runner           *trader.Runner
 }
 
-type TradingComponents struct {
-	ManualOrderQueue  *list.List
-	ManualMutex       sync.Mutex
-	MarketMaker       *trader.MarketMaker
-	MarketMakerActive bool
+// Order placed in manual mode, queued for later cancellation
+type ManualOrder struct {
+	Symbol  string
+	OrderID int64
 }
 
 func main() {
-	log.Println("Starting Binance WebSocket trading application...")
+	cfg, args, err := config.Load(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr)
+		cli.Usage(os.Stderr)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	setupLogging(cfg)
+
+	// Without a command, run the configured strategy as before
+	command := "run"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "run":
+		os.Exit(runStrategy(cfg, args))
+	case "help":
+		cli.Usage(os.Stdout)
+	case "keystore":
+		os.Exit(createKeystore(cfg, args))
+	case "signer":
+		os.Exit(runSigner(cfg, args))
+	case "execute":
+		os.Exit(runExecution(cfg, args))
+	default:
+		os.Exit(runCommand(cfg, command, args))
+	}
+}
+
+// Log at the configured level and format, keeping credentials out of every line
+func setupLogging(cfg *config.Config) {
+	logging.Setup(logging.Options{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	logging.AddSecrets(cfg.APIKey, cfg.SecretKey, cfg.ControlToken)
+
+	// Mark every line so a dry run's logs are never mistaken for real trading
+	if cfg.DryRun {
+		slog.SetDefault(slog.Default().With("simulated", true))
+	}
+}
+
+// Run a one-off command and return the process exit code
+func runCommand(cfg *config.Config, name string, args []string) int {
+	command, ok := cli.Lookup(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		cli.Usage(os.Stderr)
+		return 2
+	}
+
+	err := cfg.Validate()
+	if command.Signed {
+		err = errors.Join(err, cfg.RequireCredentials())
+	}
+	if command.Trades(args) {
+		err = errors.Join(err, cfg.RequireRiskLimits())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error:\n%v\n", err)
+		return 1
+	}
+
+	if command.Trades(args) {
+		if err := confirmMainnet(cfg, command.TradedSymbols(args)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	client, closeClient, err := connect(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer closeClient()
+
+	var commandClient cli.Client = client
+	if limits, ok := riskLimits(cfg); ok {
+		commandClient = riskCheckedClient{BinanceClient: client, risk: api.NewRiskExchange(client, limits)}
+	}
+
+	err = command.Run(commandClient, args, cli.Options{
+		Out:    os.Stdout,
+		Format: cli.Format(cfg.Output),
+		Depth:  cfg.OrderbookDepth,
+	})
+
+	if errors.Is(err, cli.ErrUsage) {
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		cli.Usage(os.Stderr)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// Connect a client for the configuration, returning a function that closes it.
+// Nothing is left open when connecting fails.
+func connect(ctx context.Context, cfg *config.Config) (*api.BinanceClient, func(), error) {
+	var clientOptions []api.Option
+	var recorder *websocket.Recorder
+
+	// Optionally record the wire-level session for later replay
+	if path := cfg.RecordFile; path != "" {
+		var err error
+		recorder, err = websocket.NewRecorder(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start session recorder: %w", err)
+		}
+
+		slog.Info("Recording session", "path", path)
+		clientOptions = append(clientOptions, api.WithWebSocketOptions(websocket.WithRecorder(recorder)))
+	}
+
+	closeRecorder := func() {
+		if recorder != nil {
+			recorder.Close()
+		}
+	}
+
+	slog.Info("Using exchange environment", "profile", cfg.Profile, "url", cfg.WebSocketURL)
+
+	if cfg.DryRun {
+		slog.Warn("Dry run: orders are validated with order.test and filled locally, nothing reaches the book")
+		clientOptions = append(clientOptions, api.WithDryRun())
+	}
+
+	credentials, err := openCredentials(cfg)
+	if err != nil {
+		closeRecorder()
+		return nil, nil, fmt.Errorf("failed to load credentials: %w", err)
+	}
+	if credentials != nil {
+		clientOptions = append(clientOptions, api.WithCredentials(credentials))
+	}
+
+	// One client, connection pool and rate limit budget shared by every symbol
+	client := api.New(cfg.WebSocketURL, cfg.APIKey, cfg.SecretKey, clientOptions...)
 
-	config := &Config{
-		WebSocketURL:     "wss://testnet.binance.vision/ws-api/v3",
-		APIKey:           os.Getenv("BINANCE_API_KEY"),
-		SecretKey:        os.Getenv("BINANCE_SECRET_KEY"),
-		Symbol:           "BTCTUSD",
-		Quantity:         0.001,
-		SpreadPercentage: 0.0001,
-		OrderbookDepth:   5,
-		Price:            "0.01",
-		TickSize:         "0.01",
+	closeClient := func() {
+		client.Close()
+
+		if agent, ok := credentials.(*secrets.Agent); ok {
+			agent.Close()
+		}
+
+		closeRecorder()
 	}
 
-	choice := getUserPrompt(config)
+	if err := client.Connect(ctx); err != nil {
+		closeClient()
+		return nil, nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
 
-	if err := validateConfig(config); err != nil {
-		log.Fatalf("Configuration error: %v", err)
+	return client, closeClient, nil
+}
+
+// Credentials for keys kept out of the configuration, in a keystore or a
+// signing agent; nil when the configured keys are used directly
+func openCredentials(cfg *config.Config) (api.Credentials, error) {
+	switch {
+	case cfg.SignerSocket != "":
+		agent, err := secrets.DialAgent(cfg.SignerSocket)
+		if err != nil {
+			return nil, err
+		}
+
+		logging.AddSecrets(agent.APIKey())
+		slog.Info("Signing requests with agent", "socket", cfg.SignerSocket)
+		return agent, nil
+	case cfg.Keystore != "":
+		keys, err := loadKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return secrets.NewStatic(keys), nil
+	}
+
+	return nil, nil
+}
+
+// Keys from the keystore when one is configured, otherwise from the configuration
+func loadKeys(cfg *config.Config) (secrets.Keys, error) {
+	if cfg.Keystore == "" {
+		return secrets.Keys{APIKey: cfg.APIKey, SecretKey: cfg.SecretKey}, nil
+	}
+
+	passphrase, err := readPassphrase(cfg, false)
+	if err != nil {
+		return secrets.Keys{}, err
+	}
+
+	keys, err := secrets.OpenKeystore(cfg.Keystore, passphrase)
+	if err != nil {
+		return secrets.Keys{}, fmt.Errorf("keystore %s: %w", cfg.Keystore, err)
+	}
+
+	logging.AddSecrets(keys.APIKey, keys.SecretKey)
+	return keys, nil
+}
+
+// Keystore passphrase from keystore_passphrase_file, or typed without echo,
+// twice when confirm is set
+func readPassphrase(cfg *config.Config, confirm bool) (string, error) {
+	if cfg.PassphraseFile != "" {
+		return secrets.ReadFile(cfg.PassphraseFile)
+	}
+
+	if cfg.NonInteractive || !isTerminal(os.Stdin) {
+		return "", errors.New("keystore passphrase needed, set keystore_passphrase_file or run interactively")
+	}
+
+	prompts := []string{"Keystore passphrase: "}
+	if confirm {
+		prompts = append(prompts, "Repeat passphrase: ")
+	}
+
+	var entered []string
+	for _, prompt := range prompts {
+		fmt.Fprint(os.Stderr, prompt)
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+
+		entered = append(entered, string(passphrase))
+	}
+
+	if confirm && entered[0] != entered[1] {
+		return "", errors.New("passphrases do not match")
+	}
+
+	return entered[0], nil
+}
+
+// Encrypt the configured keys into a new keystore file
+func createKeystore(cfg *config.Config, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: binance-trader keystore <path>")
+		return 2
+	}
+
+	keys := secrets.Keys{APIKey: cfg.APIKey, SecretKey: cfg.SecretKey}
+	if keys.APIKey == "" || keys.SecretKey == "" {
+		fmt.Fprintln(os.Stderr, "Configuration error: api_key, secret_key: both are required to create a keystore")
+		return 1
+	}
+
+	passphrase, err := readPassphrase(cfg, true)
+	if err == nil {
+		err = secrets.CreateKeystore(args[0], keys, passphrase)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Created keystore %s\n", args[0])
+	fmt.Println("Set keystore to use it, and remove the plain text keys from the environment and config file.")
+	return 0
+}
+
+// Hold the keys and sign requests for traders on a Unix socket until
+// interrupted, so the secret key never enters a trader process
+func runSigner(cfg *config.Config, args []string) int {
+	socket := cfg.SignerSocket
+	if len(args) == 1 {
+		socket = args[0]
+	}
+	if len(args) > 1 || socket == "" {
+		fmt.Fprintln(os.Stderr, "Usage: binance-trader signer [socket]")
+		return 2
+	}
+
+	keys, err := loadKeys(cfg)
+	if err == nil && (keys.APIKey == "" || keys.SecretKey == "") {
+		err = errors.New("api_key, secret_key: both are required, or use a keystore")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		slog.Error("Failed to listen for signing requests", "socket", socket, "error", err)
+		return 1
+	}
+
+	// Only this user may ask for signatures
+	if err := os.Chmod(socket, 0o600); err != nil {
+		listener.Close()
+		slog.Error("Failed to restrict signing agent socket", "socket", socket, "error", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	slog.Info("Signing agent listening", "socket", socket)
+	if err := secrets.Serve(listener, secrets.NewStatic(keys)); err != nil {
+		slog.Error("Signing agent failed", "error", err)
+		return 1
+	}
+
+	slog.Info("Signing agent stopped")
+	return 0
+}
+
+const executeUsage = "Usage: binance-trader execute <symbol> <BUY|SELL> <quantity> [--algo twap|vwap|pov] [--duration D] [--interval D]\n" +
+	"       [--limit-price P] [--participation F] [--max-participation F] [--style passive|aggressive] [--profile-days N]"
+
+// Work a parent order into the market with an execution algorithm until it
+// completes, its duration ends or it is interrupted
+func runExecution(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("execute", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	algorithm := fs.String("algo", string(execution.TWAP), "twap, vwap or pov")
+	duration := fs.Duration("duration", time.Hour, "time to work the order over")
+	interval := fs.Duration("interval", 30*time.Second, "longest slice")
+	limitPrice := fs.String("limit-price", "0", "worst price any child may trade at, 0 for none")
+	participation := fs.String("participation", "0.1", "pov share of market volume")
+	maxParticipation := fs.String("max-participation", "0", "cap on any child as a share of market volume, 0 for none")
+	style := fs.String("style", string(execution.Passive), "passive or aggressive")
+	profileDays := fs.Int("profile-days", 5, "earlier days in the vwap volume profile")
+
+	positional, err := cli.ParseArgs(fs, args, 3, 3)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s\n", err, executeUsage)
+		return 2
+	}
+
+	quantity, errQuantity := decimal.NewFromString(positional[2])
+	limit, errLimit := decimal.NewFromString(*limitPrice)
+	share, errShare := decimal.NewFromString(*participation)
+	maxShare, errMaxShare := decimal.NewFromString(*maxParticipation)
+	if err := errors.Join(errQuantity, errLimit, errShare, errMaxShare); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
+		return 2
+	}
+
+	parent := execution.ParentOrder{
+		Symbol:     strings.ToUpper(positional[0]),
+		Side:       strings.ToUpper(positional[1]),
+		Quantity:   quantity,
+		Duration:   *duration,
+		LimitPrice: limit,
+	}
+	params := execution.Params{
+		Algorithm:        execution.Algorithm(strings.ToLower(*algorithm)),
+		Style:            execution.Style(strings.ToLower(*style)),
+		Interval:         *interval,
+		Participation:    share,
+		MaxParticipation: maxShare,
+		ProfileDays:      *profileDays,
+	}
+
+	// A typo in a flag is reported before the mainnet prompt or connecting
+	if err := params.Validate(parent); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid execution:\n%v\n", err)
+		return 2
+	}
+
+	if err := errors.Join(cfg.Validate(), cfg.RequireCredentials(), cfg.RequireRiskLimits()); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error:\n%v\n", err)
+		return 1
+	}
+
+	if err := confirmMainnet(cfg, []string{parent.Symbol}); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	client, closeClient, err := connect(connectCtx, cfg)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer closeClient()
+
+	rules := symbolRules(client, cfg, parent.Symbol)[parent.Symbol]
+	params.TickSize, params.StepSize, params.MinNotional = rules.TickSize, rules.StepSize, rules.MinNotional
+
+	var exchange execution.Exchange = client
+	if limits, ok := riskLimits(cfg); ok {
+		exchange = riskCheckedClient{BinanceClient: client, risk: api.NewRiskExchange(client, limits)}
+	}
+
+	executor, err := execution.New(exchange, parent, params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid execution:\n%v\n", err)
+		return 2
+	}
+
+	report, err := executor.Run(ctx)
+
+	printer := cli.NewPrinter(os.Stdout, cli.Format(cfg.Output))
+	printErr := printer.Print(report, func(w io.Writer) {
+		fmt.Fprintf(w, "Symbol\t%s\n", report.Symbol)
+		fmt.Fprintf(w, "Side\t%s\n", report.Side)
+		fmt.Fprintf(w, "Algorithm\t%s\n", report.Algorithm)
+		fmt.Fprintf(w, "Executed\t%s of %s\n", report.Executed, report.Quantity)
+		fmt.Fprintf(w, "Average price\t%s\n", report.AveragePrice)
+		fmt.Fprintf(w, "Arrival price\t%s\n", report.ArrivalPrice)
+		fmt.Fprintf(w, "Slippage\t%.2f bps\n", report.SlippageBps)
+		fmt.Fprintf(w, "Children\t%d\n", report.Children)
+		fmt.Fprintf(w, "Elapsed\t%s\n", report.Elapsed.Round(time.Second))
+	})
+
+	if err = errors.Join(err, printErr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// Price tick, quantity step and minimum notional of a symbol
+type tradingRules struct {
+	TickSize    string
+	StepSize    string
+	MinNotional decimal.Decimal // Zero when the symbol has no notional filter
+}
+
+// Read each symbol's price tick, quantity step and minimum notional from
+// exchangeInfo. Symbols it does not return keep the configured tick size
+// and the finest quantity step a decimal holds.
+func symbolRules(client *api.BinanceClient, cfg *config.Config, symbols ...string) map[string]tradingRules {
+	rules := make(map[string]tradingRules, len(symbols))
+	for _, symbol := range symbols {
+		rules[symbol] = tradingRules{TickSize: cfg.TickSize, StepSize: "0.00000001"}
+	}
+
+	info, err := client.GetExchangeInfo(symbols...)
+	if err != nil {
+		slog.Warn("Failed to get trading rules, using the configured tick size", "symbols", symbols, "error", err)
+		return rules
+	}
+
+	found := make(map[string]models.SymbolInfo, len(info.Symbols))
+	for _, symbolInfo := range info.Symbols {
+		found[symbolInfo.Symbol] = symbolInfo
+	}
+
+	for _, symbol := range symbols {
+		symbolInfo, ok := found[symbol]
+		if !ok {
+			slog.Warn("No trading rules for symbol, using the configured tick size", "symbol", symbol)
+			continue
+		}
+
+		symbolRules := rules[symbol]
+		if price, ok := symbolInfo.Filter("PRICE_FILTER"); ok && price.TickSize != "" {
+			symbolRules.TickSize = price.TickSize
+		}
+		if lot, ok := symbolInfo.Filter("LOT_SIZE"); ok && lot.StepSize != "" {
+			symbolRules.StepSize = lot.StepSize
+		}
+
+		notional, ok := symbolInfo.Filter("NOTIONAL")
+		if !ok {
+			notional, ok = symbolInfo.Filter("MIN_NOTIONAL")
+		}
+		if ok {
+			symbolRules.MinNotional, _ = decimal.NewFromString(notional.MinNotional)
+		}
+
+		rules[symbol] = symbolRules
+	}
+
+	return rules
+}
+
+// Shown before anything can trade on mainnet
+const mainnetBanner = `
+################################################################
+#  MAINNET: orders placed from here on use REAL FUNDS
+#  Endpoint:           %s
+#  Symbols:            %s
+#  Max order notional: %s
+#  Max open orders:    %d per symbol
+################################################################
+
+`
+
+// Refuse to trade the symbols on mainnet unless the user confirmed it, with
+// confirm_mainnet or by typing the profile name. Dry runs show the warning
+// without asking. No symbols means every symbol with open orders.
+func confirmMainnet(cfg *config.Config, symbols []string) error {
+	if cfg.Profile != config.ProfileMainnet {
+		return nil
+	}
+
+	traded := strings.Join(symbols, ", ")
+	if len(symbols) == 0 {
+		traded = "every symbol with open orders"
+	}
+
+	fmt.Fprintf(os.Stderr, mainnetBanner, cfg.WebSocketURL, traded, cfg.MaxOrderNotional, cfg.MaxOpenOrders)
+
+	if cfg.DryRun || cfg.ConfirmMainnet {
+		return nil
+	}
+
+	if cfg.NonInteractive || !isTerminal(os.Stdin) {
+		return errors.New("refusing to trade on mainnet without confirmation, check the settings above and set confirm_mainnet, --confirm-mainnet or BINANCE_CONFIRM_MAINNET")
+	}
+
+	fmt.Fprintf(os.Stderr, "Type %s to continue: ", config.ProfileMainnet)
+	var input string
+	fmt.Scanln(&input)
+
+	if strings.TrimSpace(input) != config.ProfileMainnet {
+		return errors.New("mainnet trading not confirmed")
+	}
+
+	return nil
+}
+
+// Order limits from the configuration, and whether any is set
+func riskLimits(cfg *config.Config) (api.RiskLimits, bool) {
+	limits := api.RiskLimits{
+		MaxOrderQuantity: cfg.MaxOrderQuantity,
+		MaxOrderNotional: cfg.MaxOrderNotional,
+		MaxOpenOrders:    cfg.MaxOpenOrders,
+	}
+
+	return limits, limits.MaxOrderQuantity.IsPositive() || limits.MaxOrderNotional.IsPositive() || limits.MaxOpenOrders > 0
+}
+
+// Client for one-off commands whose orders pass the risk limits
+type riskCheckedClient struct {
+	*api.BinanceClient
+	risk *api.RiskExchange
+}
+
+func (c riskCheckedClient) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	return c.risk.PlaceOrder(symbol, side, orderType, price, quantity)
+}
+
+// Run a strategy until interrupted and return the process exit code.
+// An optional argument overrides the configured mode.
+func runStrategy(cfg *config.Config, args []string) int {
+	slog.Info("Starting Binance WebSocket trading application")
+
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: binance-trader run [mode]")
+		return 2
+	}
+
+	if len(args) == 1 {
+		cfg.Mode = config.Mode(args[0])
+	}
+
+	// Containers and orchestrators usually run without a terminal to prompt on
+	if !cfg.NonInteractive && !isTerminal(os.Stdin) {
+		slog.Info("stdin is not a terminal, running non-interactively")
+		cfg.NonInteractive = true
+	}
+
+	if !cfg.NonInteractive {
+		promptForConfig(cfg)
+	}
+
+	if err := cfg.ValidateRun(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error:\n%v\n", err)
+		return 1
+	}
+
+	if err := confirmMainnet(cfg, cfg.Symbols); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
 	}
 
 	sigCh := make(chan os.Signal, 1)
@@ -71,180 +671,330 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	client := api.New(config.WebSocketURL, config.APIKey, config.SecretKey, config.Symbol)
-	if err := client.Connect(ctx); err != nil {
-		log.Fatalf("Failed to connect to WebSocket: %v", err)
+	client, closeClient, err := connect(ctx, cfg)
+	if err != nil {
+		slog.Error("Failed to connect", "error", err)
+		return 1
 	}
-	defer client.Close()
+	defer closeClient()
 
 	// Test the signature if API keys are provided
-	if err := testAuthentication(client, config); err != nil {
-		log.Fatalf("Authentication failed: %v", err)
-		os.Exit(1)
+	if err := testAuthentication(client, cfg); err != nil {
+		slog.Error("Authentication failed", "error", err)
+		return 1
 	}
 
 	printAccountBalance(client)
 
+	// Strategies and manual trading only see the Exchange interface
+	var exchange api.Exchange = client
+	if limits, ok := riskLimits(cfg); ok {
+		exchange = api.NewRiskExchange(client, limits)
+	}
+
 	timers := setupTimers()
 	defer stopTimers(timers)
 
-	components := initTradingComponents(choice, client, config)
+	components, err := initTradingComponents(exchange, client, cfg, symbolRules(client, cfg, cfg.Symbols...))
+	if err != nil {
+		slog.Error("Failed to start trading", "error", err)
+		return 1
+	}
+
+	// Stopped by shutdownTrading before orders are canceled, so nothing can restart a strategy
+	var controlServer *control.Server
 
-	log.Printf("Application running. Trading %s. Press Ctrl+C to exit.", config.Symbol)
+	if cfg.ControlAddr != "" {
+		controlServer = control.New(client, components.Runner, control.Options{
+			Addr:        cfg.ControlAddr,
+			Token:       cfg.ControlToken,
+			Connections: client.GetConnectionPool().Stats,
+			DryRun:      client.DryRun(),
+		})
+
+		if err := controlServer.Start(); err != nil {
+			slog.Error("Failed to start the control server", "error", err)
+			return shutdownTrading(client, components, cfg, nil)
+		}
+	}
+
+	if cfg.MetricsAddr != "" {
+		server, err := startMetrics(client, components, cfg.MetricsAddr)
+		if err != nil {
+			slog.Error("Failed to start the metrics server", "error", err)
+			return shutdownTrading(client, components, cfg, controlServer)
+		}
+
+		// Metrics stay up until every order is confirmed canceled
+		defer stopServer("metrics", server)
+	}
+
+	syncTime(client)
+
+	slog.Info("Application running, press Ctrl+C to exit", "symbols", cfg.Symbols)
+
+	// Receives once the dashboard closes, never when there is none
+	var dashboardDone <-chan error
+	if cfg.Dashboard {
+		dashboardDone = startDashboard(ctx, client, components, cfg)
+	}
 
 	for {
 		select {
 		case <-timers.OrderBook.C:
-			printOrderBook(client, config.OrderbookDepth)
+			if cfg.Dashboard {
+				continue
+			}
+
+			for _, symbol := range cfg.Symbols {
+				printOrderBook(client, symbol, cfg.OrderbookDepth)
+			}
 
 		case <-timers.OrderSummary.C:
+			if cfg.Dashboard {
+				continue
+			}
+
 			client.GetOrderManager().PrintOrderSummary()
 
+		case <-timers.TimeSync.C:
+			syncTime(client)
+
 		case <-timers.ManualTrade.C:
-			if choice != "1" {
+			if cfg.Mode != config.ModeManual {
 				continue
 			}
 
-			orderID := placeTestOrder(client, "MARKET", config.Symbol, fmt.Sprintf("%f", config.Quantity), config.OrderbookDepth, ctx)
-			if orderID != -1 {
-				components.ManualMutex.Lock()
-				components.ManualOrderQueue.PushBack(orderID)
-				components.ManualMutex.Unlock()
+			for _, symbol := range cfg.Symbols {
+				orderID := placeTestOrder(exchange, "MARKET", symbol, cfg.Quantity.String(), cfg.OrderbookDepth, ctx)
+				if orderID != -1 {
+					components.ManualMutex.Lock()
+					components.ManualOrderQueue.PushBack(ManualOrder{Symbol: symbol, OrderID: orderID})
+					components.ManualMutex.Unlock()
+				}
 			}
 
 		case <-timers.ManualCancel.C:
-			if choice != "1" {
+			if cfg.Mode != config.ModeManual {
 				continue
 			}
 
-			handleManualOrderCancellation(components, client, ctx)
+			handleManualOrderCancellation(components, exchange, ctx)
 
-		case <-sigCh:
-			log.Println("Shutdown signal received, exiting...")
+		case err := <-dashboardDone:
+			if err != nil {
+				slog.Error("Dashboard failed", "error", err)
+			}
 
-			if choice != "1" && components.MarketMakerActive && components.MarketMaker != nil {
-				log.Println("Stopping market maker strategy...")
-				components.MarketMaker.Stop()
+			slog.Info("Dashboard closed, exiting")
+			return shutdownTrading(client, components, cfg, controlServer)
+
+		case <-sigCh:
+			// Give the terminal back before logging to it
+			if dashboardDone != nil {
+				cancel()
+				<-dashboardDone
 			}
 
-			return
+			slog.Info("Shutdown signal received, exiting")
+			return shutdownTrading(client, components, cfg, controlServer)
 		}
 	}
 }
 
-func promptForConfig(config *Config) *Config {
-	fmt.Println("\nEnter trading parameters (press Enter to use defaults):")
-
-	fmt.Printf("Symbol [%s]: ", config.Symbol)
-	var input string
-	fmt.Scanln(&input)
-	if strings.TrimSpace(input) != "" {
-		config.Symbol = strings.ToUpper(strings.TrimSpace(input))
+// Stop the control API, the strategies and every open order on the traded
+// symbols, including manual orders. Returns a failing exit code when orders
+// may still be live on the exchange.
+func shutdownTrading(client *api.BinanceClient, components *TradingComponents, cfg *config.Config, controlServer *control.Server) int {
+	if controlServer != nil {
+		stopServer("control", controlServer)
 	}
 
-	fmt.Printf("Quantity [%f]: ", config.Quantity)
-	fmt.Scanln(&input)
-	if strings.TrimSpace(input) != "" {
-		if val, err := strconv.ParseFloat(input, 64); err == nil && val > 0 {
-			config.Quantity = val
-		} else {
-			log.Printf("Invalid quantity, using default: %f", config.Quantity)
-		}
-	}
+	coordinator := shutdown.New(client, components.Runner, shutdown.Options{
+		Symbols:   cfg.Symbols,
+		Timeout:   cfg.ShutdownTimeout,
+		StateFile: cfg.StateFile,
+		DryRun:    client.DryRun(),
+	})
 
-	fmt.Printf("Spread Percentage [%f]: ", config.SpreadPercentage)
-	fmt.Scanln(&input)
-	fmt.Println()
-	if strings.TrimSpace(input) != "" {
-		if val, err := strconv.ParseFloat(input, 64); err == nil && val > 0 {
-			config.SpreadPercentage = val
-		} else {
-			log.Printf("Invalid spread, using default: %f", config.SpreadPercentage)
-		}
+	if report := coordinator.Run(context.Background()); !report.Clean() {
+		slog.Error("Exiting with orders that may still be open, check them on the exchange")
+		return 1
 	}
 
-	return config
+	return 0
 }
 
-func validateConfig(config *Config) error {
-	if config.Symbol == "" {
-		return fmt.Errorf("trading symbol cannot be empty")
-	}
+type server interface {
+	Shutdown(ctx context.Context) error
+}
 
-	if config.Quantity <= 0 {
-		return fmt.Errorf("quantity must be greater than 0")
+// Stop an HTTP server, waiting up to controlShutdownTimeout for active requests
+func stopServer(name string, s server) {
+	ctx, cancel := context.WithTimeout(context.Background(), controlShutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		slog.Warn("Failed to stop the server", "server", name, "error", err)
 	}
+}
 
-	if config.SpreadPercentage <= 0 {
-		return fmt.Errorf("spread percentage must be greater than 0")
+// Run the dashboard on the terminal, sending logs to its log pane until it closes.
+// Falls back to plain log output without a terminal.
+func startDashboard(ctx context.Context, client *api.BinanceClient, components *TradingComponents, cfg *config.Config) <-chan error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		slog.Warn("The dashboard needs a terminal, logging instead")
+		cfg.Dashboard = false
+		return nil
 	}
 
-	return utils.AuthenticateAPIKeys(config.APIKey, config.SecretKey)
+	logs := dashboard.NewLogBuffer(500)
+	logging.SetOutput(logs)
+
+	d := dashboard.New(client, components.Runner, dashboard.Options{
+		Symbols:     cfg.Symbols,
+		Depth:       cfg.OrderbookDepth,
+		Logs:        logs,
+		Connections: client.GetConnectionPool().Stats,
+		DryRun:      client.DryRun(),
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		err := d.Run(ctx, os.Stdin, os.Stdout)
+		logging.SetOutput(os.Stderr)
+		done <- err
+	}()
+
+	return done
 }
 
-func testAuthentication(client *api.BinanceClient, config *Config) error {
-	log.Println("Testing API key and signature...")
+func testAuthentication(client *api.BinanceClient, cfg *config.Config) error {
+	slog.Info("Testing API key and signature")
 	if err := client.TestSignature(); err != nil {
 		return err
 	}
 
-	log.Println("Signature test passed")
+	slog.Info("Signature test passed")
 
-	// Get the orderbook to verify connectivity
-	orderbook, err := client.GetOrderbook(config.OrderbookDepth)
-	if err != nil {
-		return fmt.Errorf("failed to get orderbook: %v", err)
+	// Get the orderbooks to verify connectivity and that every symbol exists
+	for _, symbol := range cfg.Symbols {
+		orderbook, err := client.GetOrderbook(symbol, cfg.OrderbookDepth)
+		if err != nil {
+			return fmt.Errorf("failed to get %s orderbook: %v", symbol, err)
+		}
+
+		client.DisplayOrderbook(orderbook, cfg.OrderbookDepth)
 	}
 
-	client.DisplayOrderbook(orderbook, config.OrderbookDepth)
 	return nil
 }
 
-func getUserPrompt(config *Config) string {
+// Ask for trading parameters on stdin, keeping the loaded values as defaults
+func promptForConfig(cfg *config.Config) {
 	fmt.Println("\nEnter trading parameters (press Enter to use default values):")
 
-	// Symbol
-	fmt.Printf("Symbol [%s]: ", config.Symbol)
+	// Symbols
+	fmt.Printf("Symbols, comma separated [%s]: ", strings.Join(cfg.Symbols, ","))
 	var input string
 	fmt.Scanln(&input)
-	if strings.TrimSpace(input) != "" {
-		config.Symbol = strings.ToUpper(strings.TrimSpace(input))
+	if symbols := config.ParseSymbols(input); len(symbols) > 0 {
+		cfg.Symbols = symbols
 	}
 
 	// Quantity
-	fmt.Printf("Quantity [%f]: ", config.Quantity)
+	fmt.Printf("Quantity [%s]: ", cfg.Quantity)
+	input = ""
 	fmt.Scanln(&input)
 	if strings.TrimSpace(input) != "" {
-		if val, err := strconv.ParseFloat(input, 64); err == nil && val > 0 {
-			config.Quantity = val
+		if val, err := decimal.NewFromString(input); err == nil && val.IsPositive() {
+			cfg.Quantity = val
 		} else {
-			fmt.Printf("Invalid quantity '%s', using default: %f\n", input, config.Quantity)
+			fmt.Printf("Invalid quantity '%s', using default: %s\n", input, cfg.Quantity)
 		}
 	}
 
-	fmt.Println("\nChoose operating mode:")
-	fmt.Println("1. Manual mode - Place individual test market orders")
-	fmt.Println("2. Basic market maker - Continuously place bid/ask orders at a fixed spread")
-	fmt.Print("Enter choice (1 or 2): ")
-
-	var choice string
-	fmt.Scanln(&choice)
-	fmt.Println()
+	// Mode, unless already chosen by file, environment or flag
+	if cfg.Mode == "" {
+		fmt.Println("\nChoose operating mode:")
+		fmt.Println("1. Manual mode - Place individual test market orders")
+		fmt.Println("2. Basic market maker - Continuously place bid/ask orders at a fixed spread")
+		fmt.Println("3. Avellaneda-Stoikov - Skew quotes against inventory with a volatility-driven spread")
+		fmt.Println("4. Grid - Keep buy and sell orders between grid_lower and grid_upper")
+		fmt.Print("Enter choice (1, 2, 3 or 4): ")
+
+		var choice string
+		fmt.Scanln(&choice)
+		fmt.Println()
+
+		switch choice {
+		case "1":
+			cfg.Mode = config.ModeManual
+		case "2":
+			cfg.Mode = config.ModeMarketMaker
+		case "3":
+			cfg.Mode = config.ModeAvellanedaStoikov
+		case "4":
+			cfg.Mode = config.ModeGrid
+		}
+	}
 
-	if choice == "2" || choice == "3" {
-		fmt.Printf("Spread Percentage [%f]: ", config.SpreadPercentage)
-		var input string
+	if cfg.Mode.Quotes() {
+		fmt.Printf("Spread Percentage [%s]: ", cfg.SpreadPercentage)
+		input = ""
 		fmt.Scanln(&input)
 		if strings.TrimSpace(input) != "" {
-			if val, err := strconv.ParseFloat(input, 64); err == nil && val > 0 {
-				config.SpreadPercentage = val
+			if val, err := decimal.NewFromString(input); err == nil && val.IsPositive() {
+				cfg.SpreadPercentage = val
 			} else {
-				fmt.Printf("Invalid spread percentage '%s', using default: %f\n", input, config.SpreadPercentage)
+				fmt.Printf("Invalid spread percentage '%s', using default: %s\n", input, cfg.SpreadPercentage)
 			}
 		}
 	}
+}
 
-	return choice
+// Whether a file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// Register the components read at scrape time and serve /metrics
+func startMetrics(client *api.BinanceClient, components *TradingComponents, addr string) (*metrics.Server, error) {
+	collectors := []prometheus.Collector{
+		client.GetConnectionPool(),
+		client.GetRateLimiter(),
+		client.GetOrderManager(),
+	}
+
+	if components.Runner != nil {
+		collectors = append(collectors, components.Runner)
+	}
+
+	for _, collector := range collectors {
+		if err := metrics.Registry.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+
+	server := metrics.NewServer(addr)
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// Measure the clock offset from the exchange, exported as a metric
+func syncTime(client *api.BinanceClient) {
+	offset, err := client.SyncTime()
+	if err != nil {
+		slog.Warn("Failed to sync time with the exchange", "error", err)
+		return
+	}
+
+	if offset.Abs() > maxTimeOffset {
+		slog.Warn("Local clock is off the exchange clock", "offset", offset.Round(time.Millisecond))
+	}
 }
 
 func setupTimers() *Timers {
@@ -253,6 +1003,7 @@ func setupTimers() *Timers {
 		OrderSummary: time.NewTicker(10 * time.Second),
 		ManualTrade:  time.NewTicker(15 * time.Second),
 		ManualCancel: time.NewTicker(30 * time.Second),
+		TimeSync:     time.NewTicker(time.Minute),
 	}
 }
 
@@ -269,97 +1020,180 @@ func stopTimers(timers *Timers) {
 	if timers.OrderSummary != nil {
 		timers.OrderSummary.Stop()
 	}
+	if timers.TimeSync != nil {
+		timers.TimeSync.Stop()
+	}
 }
 
-func initTradingComponents(choice string, client *api.BinanceClient, config *Config) *TradingComponents {
-	components := &TradingComponents{
-		MarketMakerActive: false,
-	}
+func initTradingComponents(exchange api.Exchange, trades trader.RecentTrades, cfg *config.Config, rules map[string]tradingRules) (*TradingComponents, error) {
+	components := &TradingComponents{}
 
-	if choice == "2" {
-		log.Println("\nStarting basic market maker strategy...")
-		log.Printf("Using spread percentage: %f, quantity: %f", config.SpreadPercentage, config.Quantity)
+	switch {
+	case cfg.Mode == config.ModeGrid:
+		components.Runner = trader.NewRunner()
 
-		components.MarketMaker = trader.New(
-			client,
-			config.Symbol,
-			config.SpreadPercentage,
-			fmt.Sprintf("%f", config.Quantity),
-			config.TickSize,
-		)
+		symbol := cfg.Symbols[0]
+		quantity, err := orderQuantity(cfg.Quantity, rules[symbol])
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up grid on %s: %w", symbol, err)
+		}
 
-		components.MarketMaker.Start()
-		components.MarketMakerActive = true
-	} else {
-		log.Println("\nRunning in manual mode - placing test orders")
+		grid, err := trader.NewGrid(exchange, symbol, trader.GridParams{
+			Lower:     cfg.GridLower,
+			Upper:     cfg.GridUpper,
+			Levels:    cfg.GridLevels,
+			Spacing:   trader.GridSpacing(cfg.GridSpacing),
+			Quantity:  quantity,
+			TickSize:  rules[symbol].TickSize,
+			StateFile: cfg.GridStateFile,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up grid: %w", err)
+		}
+
+		if err := components.Runner.Add(grid); err != nil {
+			return nil, fmt.Errorf("failed to add grid: %w", err)
+		}
+
+		components.Runner.StartAll()
+
+	case cfg.Mode.Quotes():
+		slog.Info("Starting market maker strategy", "mode", cfg.Mode, "spreadPercentage", cfg.SpreadPercentage, "quantity", cfg.Quantity, "fairValue", cfg.FairValue)
+
+		components.Runner = trader.NewRunner()
+
+		for _, symbol := range cfg.Symbols {
+			quantity, err := orderQuantity(cfg.Quantity, rules[symbol])
+			if err != nil {
+				slog.Warn("Skipping symbol", "symbol", symbol, "error", err)
+				continue
+			}
+
+			slog.Info("Trading rules", "symbol", symbol, "tickSize", rules[symbol].TickSize, "stepSize", rules[symbol].StepSize, "quantity", quantity)
+
+			marketMaker := trader.New(
+				exchange,
+				symbol,
+				cfg.SpreadPercentage,
+				quantity.String(),
+				rules[symbol].TickSize,
+				trader.WithQuoteModel(quoteModel(cfg, trades)),
+			)
+
+			if err := components.Runner.Add(marketMaker); err != nil {
+				slog.Warn("Skipping symbol", "symbol", symbol, "error", err)
+			}
+		}
+
+		components.Runner.StartAll()
+
+	default:
+		slog.Info("Running in manual mode, placing test orders")
 		components.ManualOrderQueue = list.New()
 	}
 
-	return components
+	return components, nil
+}
+
+// Configured order quantity rounded down onto the symbol's lot step
+func orderQuantity(quantity decimal.Decimal, rules tradingRules) (decimal.Decimal, error) {
+	rounded, err := decimal.NewFromString(utils.FormatQuantity(quantity, rules.StepSize))
+	if err != nil || !rounded.IsPositive() {
+		return decimal.Decimal{}, fmt.Errorf("quantity %s is below the lot step %s", quantity, rules.StepSize)
+	}
+
+	return rounded, nil
+}
+
+// Quote model for one symbol selected by mode, holding its own estimates
+func quoteModel(cfg *config.Config, trades trader.RecentTrades) trader.QuoteModel {
+	if cfg.Mode == config.ModeAvellanedaStoikov {
+		return trader.NewAvellanedaStoikov(trader.AvellanedaStoikovParams{
+			Gamma:   cfg.ASGamma,
+			Kappa:   cfg.ASKappa,
+			Horizon: cfg.ASHorizon,
+		}, trades)
+	}
+
+	return trader.NewFixedSpread(fairValue(cfg, trades))
+}
+
+// Fair value estimator selected by fair_value
+func fairValue(cfg *config.Config, trades trader.RecentTrades) trader.FairValue {
+	switch cfg.FairValue {
+	case config.FairValueMicroprice:
+		return trader.Microprice{}
+	case config.FairValueDepth:
+		return trader.NewDepthWeighted(cfg.FairValueLevels)
+	case config.FairValueTradeFlow:
+		return trader.NewTradeFlow(trader.MidPrice{}, trades, cfg.TradeFlowWindow, cfg.TradeFlowWeight)
+	default:
+		return trader.MidPrice{}
+	}
 }
 
 func printAccountBalance(client *api.BinanceClient) {
 	balance, err := client.GetAccountBalance()
 	if err != nil {
-		log.Printf("Failed to get account balance: %v", err)
+		slog.Warn("Failed to get account balance", "error", err)
 		return
 	}
 
 	client.DisplayAccountBalance(balance)
 }
 
-func printOrderBook(client *api.BinanceClient, depth int) {
-	orderbook, err := client.GetOrderbook(depth)
+func printOrderBook(client *api.BinanceClient, symbol string, depth int) {
+	orderbook, err := client.GetOrderbook(symbol, depth)
 	if err != nil {
-		log.Printf("Failed to get orderbook: %v", err)
+		slog.Warn("Failed to get orderbook", "symbol", symbol, "error", err)
 		return
 	}
 
 	client.DisplayOrderbook(orderbook, depth)
 }
 
-func handleManualOrderCancellation(components *TradingComponents, client *api.BinanceClient, ctx context.Context) {
+func handleManualOrderCancellation(components *TradingComponents, exchange api.Exchange, ctx context.Context) {
 	components.ManualMutex.Lock()
 	defer components.ManualMutex.Unlock()
 
 	if components.ManualOrderQueue.Len() > 0 {
 		oldestOrder := components.ManualOrderQueue.Front()
-		orderID, ok := oldestOrder.Value.(int64)
+		order, ok := oldestOrder.Value.(ManualOrder)
 		if !ok {
-			fmt.Println("Failed to convert order ID to int64")
+			slog.Error("Failed to convert queued order to ManualOrder")
 			return
 		}
 
-		fmt.Println("Dequeuing oldest order:", orderID)
-		go cancelTestOrder(client, orderID, ctx)
+		slog.Info("Dequeuing oldest order", "symbol", order.Symbol, "orderId", order.OrderID)
+		go cancelTestOrder(exchange, order.Symbol, order.OrderID, ctx)
 		components.ManualOrderQueue.Remove(oldestOrder)
 	}
 }
 
-func placeTestOrder(client *api.BinanceClient, orderType string, symbol string, quantity string, limit int, ctx context.Context) int64 {
+func placeTestOrder(client api.Exchange, orderType string, symbol string, quantity string, limit int, ctx context.Context) int64 {
 	select {
 	case <-ctx.Done():
 		return -1
 	default:
 	}
 
-	orderbook, err := client.GetOrderbook(limit)
+	orderbook, err := client.GetOrderbook(symbol, limit)
 	if err != nil {
-		log.Printf("Failed to get orderbook: %v", err)
+		slog.Warn("Failed to get orderbook", "symbol", symbol, "error", err)
 		return -1
 	}
 
 	if len(orderbook.Asks) > 0 {
 		askPrice := orderbook.Asks[0].Price
-		buyPrice := utils.FormatPrice(askPrice*0.99, "0.01") // 1% below the lowest ask
+		buyPrice := utils.FormatPrice(askPrice.Mul(decimal.RequireFromString("0.99")), "0.01", decimal.Floor) // 1% below the lowest ask
 
-		order, err := client.PlaceOrder("BUY", orderType, buyPrice, quantity)
+		order, err := client.PlaceOrder(symbol, "BUY", orderType, buyPrice, quantity)
 		if err != nil {
-			log.Printf("Failed to place order: %v", err)
+			slog.Warn("Failed to place order", "symbol", symbol, "error", err)
 			return -1
 		}
 
-		log.Printf("%s order placed successfully: ID=%d, Status=%s", orderType, order.OrderID, order.Status)
+		slog.Info("Order placed", "symbol", symbol, "type", orderType, "orderId", order.OrderID, "status", order.Status)
 
 		client.GetOrderManager().PrintOrderSummary()
 
@@ -369,7 +1203,7 @@ func placeTestOrder(client *api.BinanceClient, orderType string, symbol string,
 	return -1
 }
 
-func cancelTestOrder(client *api.BinanceClient, orderID int64, ctx context.Context) {
+func cancelTestOrder(client api.Exchange, symbol string, orderID int64, ctx context.Context) {
 	select {
 	case <-ctx.Done():
 		return
@@ -377,23 +1211,23 @@ func cancelTestOrder(client *api.BinanceClient, orderID int64, ctx context.Conte
 	}
 
 	// Check if the order is still active
-	order, err := client.GetOrderStatus(orderID)
+	order, err := client.GetOrderStatus(symbol, orderID)
 	if err != nil {
-		log.Printf("Failed to get order status: %v", err)
+		slog.Warn("Failed to get order status", "symbol", symbol, "orderId", orderID, "error", err)
 		return
 	}
 
 	if order.Status == "NEW" || order.Status == "PARTIALLY_FILLED" {
-		log.Printf("Canceling test order: %d", orderID)
+		slog.Info("Canceling test order", "symbol", symbol, "orderId", orderID)
 
-		canceledOrder, err := client.CancelOrder(orderID)
+		canceledOrder, err := client.CancelOrder(symbol, orderID)
 		if err != nil {
-			log.Printf("Failed to cancel order: %v", err)
+			slog.Warn("Failed to cancel order", "symbol", symbol, "orderId", orderID, "error", err)
 			return
 		}
 
-		log.Printf("Order canceled: ID=%d, Status=%s", canceledOrder.OrderID, canceledOrder.Status)
+		slog.Info("Order canceled", "symbol", symbol, "orderId", canceledOrder.OrderID, "status", canceledOrder.Status)
 	} else {
-		log.Printf("Order %d is already in final state: %s", orderID, order.Status)
+		slog.Info("Order is already in a final state", "symbol", symbol, "orderId", orderID, "status", order.Status)
 	}
 }
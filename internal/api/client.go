@@ -2,158 +2,427 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
-	"math"
-	"strconv"
+	"log/slog"
 	"time"
 
+	"github.com/iamramtin/binance-trader/internal/decimal"
 	"github.com/iamramtin/binance-trader/internal/models"
 	"github.com/iamramtin/binance-trader/internal/ordermanager"
 	"github.com/iamramtin/binance-trader/internal/utils"
 	"github.com/iamramtin/binance-trader/internal/websocket"
 )
 
+// Timeout for a single WebSocket API request
+const requestTimeout = 5 * time.Second
+
 type BinanceClient struct {
-	wsClient     *websocket.Client     // WebSocket client
+	pool         *ConnectionPool       // WebSocket connections routed by request class
 	orderManager *ordermanager.Manager // Order manager
+	limiter      *RateLimiter          // Order rate budget shared by all symbols
+	sim          *simulator            // Simulates order entry in dry runs, nil when trading for real
 	apiKey       string                // API key
-	secretKey    string                // Secret key
-	symbol       string                // Trading symbol
+	signer       utils.Signer          // Signs requests, nil without a secret key
+}
+
+// Source of the API key and request signatures, for secret keys that are
+// kept out of the configuration or out of the process entirely
+type Credentials interface {
+	APIKey() string
+	Sign(payload string) (string, error)
+}
+
+// Configure optional client behaviour
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	pool        PoolConfig
+	limiter     *RateLimiter
+	wsOptions   []websocket.Option
+	dryRun      bool
+	credentials Credentials
+}
+
+// Override the default connection pool layout
+func WithPoolConfig(config PoolConfig) Option {
+	return func(o *clientOptions) {
+		o.pool = config
+	}
 }
 
-func New(wsURL, apiKey, secretKey, symbol string) *BinanceClient {
-	return &BinanceClient{
-		wsClient:     websocket.New(wsURL, apiKey, secretKey),
+// Sign with credentials instead of the secret key given to New
+func WithCredentials(credentials Credentials) Option {
+	return func(o *clientOptions) {
+		o.credentials = credentials
+	}
+}
+
+// Pass options through to every underlying WebSocket client
+func WithWebSocketOptions(opts ...websocket.Option) Option {
+	return func(o *clientOptions) {
+		o.wsOptions = append(o.wsOptions, opts...)
+	}
+}
+
+// Create a client for any number of symbols sharing one connection pool and rate limit budget
+func New(wsURL, apiKey, secretKey string, opts ...Option) *BinanceClient {
+	options := clientOptions{pool: DefaultPoolConfig()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.limiter == nil {
+		options.limiter = NewRateLimiter(defaultOrdersPerSecond, defaultOrderBurst)
+	}
+
+	var signer utils.Signer
+	if secretKey != "" {
+		signer = utils.HMACSigner(secretKey)
+	}
+
+	if options.credentials != nil {
+		apiKey, secretKey, signer = options.credentials.APIKey(), "", options.credentials
+	}
+
+	client := &BinanceClient{
+		pool:         NewConnectionPool(wsURL, apiKey, secretKey, options.pool, options.wsOptions...),
 		orderManager: ordermanager.New(),
+		limiter:      options.limiter,
 		apiKey:       apiKey,
-		secretKey:    secretKey,
-		symbol:       symbol,
+		signer:       signer,
+	}
+
+	if options.dryRun {
+		client.sim = &simulator{rates: make(map[string]models.OrderTestResult)}
 	}
+
+	return client
 }
 
 func (c *BinanceClient) Connect(ctx context.Context) error {
-	return c.wsClient.Connect(ctx)
+	return c.pool.Connect(ctx)
 }
 
 func (c *BinanceClient) Close() {
-	c.wsClient.Close()
+	c.pool.Close()
 }
 
+// Primary trading connection
 func (c *BinanceClient) GetWSClient() *websocket.Client {
-	return c.wsClient
+	return c.pool.conns[ClassTrading][0].client
+}
+
+func (c *BinanceClient) GetConnectionPool() *ConnectionPool {
+	return c.pool
 }
 
 func (c *BinanceClient) GetOrderManager() *ordermanager.Manager {
 	return c.orderManager
 }
 
-func (c *BinanceClient) TestSignature() error {
-	timestamp := fmt.Sprintf("%d", time.Now().UnixMilli())
+func (c *BinanceClient) GetRateLimiter() *RateLimiter {
+	return c.limiter
+}
 
-	params := map[string]string{
-		"timestamp": timestamp,
-		"apiKey":    c.apiKey,
+// Send a request on the healthiest connection for its class and wait for the response
+func (c *BinanceClient) call(class RequestClass, method string, params any) (*models.WebSocketResponse, error) {
+	if c.pool.Hedging(class) {
+		return c.callHedged(class, method, params)
 	}
 
-	params["signature"] = utils.GenerateSignature(c.secretKey, params)
+	return c.send(c.pool.acquire(class, 1)[0], method, params)
+}
 
-	requestParams := make(map[string]any)
-	for k, v := range params {
-		requestParams[k] = v
+// Send a read-only request on two connections of its class and return the
+// first successful response. Orders go through placeHedged instead, since a
+// plain copy of an order is a second order.
+func (c *BinanceClient) callHedged(class RequestClass, method string, params any) (*models.WebSocketResponse, error) {
+	type result struct {
+		response *models.WebSocketResponse
+		err      error
 	}
 
-	resultCh := make(chan bool, 1)
-	errCh := make(chan error, 1)
+	conns := c.pool.acquire(class, 2)
+	results := make(chan result, len(conns))
 
-	logParams, _ := json.Marshal(requestParams)
-	log.Printf("Sending test request: %s", string(logParams))
+	for _, pc := range conns {
+		go func() {
+			response, err := c.send(pc, method, params)
+			results <- result{response, err}
+		}()
+	}
 
-	_, err := c.wsClient.SendRequest("account.status", requestParams, func(response []byte) {
-		var wsResponse models.WebSocketResponse
-		if err := json.Unmarshal(response, &wsResponse); err != nil {
-			errCh <- fmt.Errorf("error parsing test response: %w", err)
-			return
+	var first result
+	for i := range conns {
+		r := <-results
+		if r.err == nil && r.response.Error == nil {
+			return r.response, nil
 		}
 
-		if wsResponse.Error != nil {
-			errCh <- fmt.Errorf("API error: %s", wsResponse.Error.Msg)
-			return
+		if i == 0 {
+			first = r
 		}
+	}
 
-		resultCh <- true
-	})
+	return first.response, first.err
+}
+
+// Outcome of one copy of a hedged order
+type placement struct {
+	order    *models.Order
+	err      error
+	answered bool // Whether the exchange's answer shows if the copy was placed
+}
+
+// Send a limit order on two trading connections and return the first copy
+// the exchange accepts. Both copies carry the same client order ID, so the
+// exchange rejects the slower one as a duplicate while the first is open.
+// An order that fills at once frees its ID for the slower copy, so that copy
+// is reconciled by the ID in the background and canceled if it was accepted.
+func (c *BinanceClient) placeHedged(params map[string]string) (*models.Order, error) {
+	conns := c.pool.acquire(ClassTrading, 2)
+	results := make(chan placement, len(conns))
+
+	for _, pc := range conns {
+		go func() {
+			results <- c.placeOn(pc, params)
+		}()
+	}
+
+	var first placement
+	answered := true
+	for i := range conns {
+		r := <-results
+		if r.err == nil {
+			c.orderManager.TrackOrder(r.order)
+
+			if i < len(conns)-1 {
+				go func() {
+					c.reconcileHedged(r.order, params, <-results)
+				}()
+			}
+
+			return r.order, nil
+		}
 
+		if i == 0 {
+			first = r
+		}
+		answered = answered && r.answered
+	}
+
+	// A copy that went unanswered may still have reached the exchange
+	if !answered {
+		if order, err := c.orderByClientID(params["symbol"], params["newClientOrderId"]); err == nil {
+			slog.Warn("Found hedged order after both copies failed", "symbol", order.Symbol, "orderId", order.OrderID, "clientOrderId", order.ClientOrderID)
+			c.orderManager.TrackOrder(order)
+			return order, nil
+		}
+	}
+
+	return nil, first.err
+}
+
+// Place one copy of a hedged order on a specific connection
+func (c *BinanceClient) placeOn(pc *pooledConn, params map[string]string) placement {
+	wsResponse, err := c.send(pc, "order.place", params)
 	if err != nil {
-		return err
+		return placement{err: err}
 	}
 
-	select {
-	case <-resultCh:
-		return nil
-	case err := <-errCh:
-		return err
-	case <-time.After(5 * time.Second):
-		return fmt.Errorf("timeout waiting for test response")
+	if wsResponse.Error != nil {
+		return placement{err: fmt.Errorf("API error: %s", wsResponse.Error.Msg), answered: true}
 	}
+
+	var order models.Order
+	if err := json.Unmarshal(wsResponse.Result, &order); err != nil {
+		// Accepted but unreadable, so the order has to be found by its ID
+		return placement{err: fmt.Errorf("error parsing order data: %w", err)}
+	}
+
+	return placement{order: &order, answered: true}
 }
 
-func (c *BinanceClient) GetAccountBalance() (*models.AccountResponse, error) {
-	resultCh := make(chan *models.AccountResponse, 1)
-	errCh := make(chan error, 1)
+// Cancel the slower copy of a hedged order if the exchange accepted it as a
+// second order. One that filled before the cancel is kept in the order
+// manager so positions still count it.
+func (c *BinanceClient) reconcileHedged(winner *models.Order, params map[string]string, slower placement) {
+	duplicate := slower.order
+	if !slower.answered {
+		duplicate, _ = c.orderByClientID(winner.Symbol, params["newClientOrderId"])
+	}
 
-	timestamp := utils.GenerateTimestampString()
+	// Rejected as a duplicate, or the lookup found the winning copy
+	if duplicate == nil || duplicate.OrderID == winner.OrderID {
+		return
+	}
 
+	logger := slog.With("symbol", winner.Symbol, "clientOrderId", params["newClientOrderId"], "orderId", winner.OrderID, "duplicateOrderId", duplicate.OrderID)
+	logger.Warn("Exchange accepted both copies of a hedged order, canceling the slower one", "status", duplicate.Status)
+
+	c.orderManager.TrackOrder(duplicate)
+
+	if isOpen(duplicate.Status) {
+		_, err := c.CancelOrder(duplicate.Symbol, duplicate.OrderID)
+		if err == nil {
+			return
+		}
+
+		logger.Error("Failed to cancel duplicate hedged order", "error", err)
+	}
+
+	if order, err := c.orderManager.GetOrder(duplicate.Symbol, duplicate.OrderID); err == nil {
+		if executed, err := decimal.NewFromString(order.ExecutedQty); err == nil && executed.IsPositive() {
+			logger.Error("Duplicate hedged order filled", "executedQty", order.ExecutedQty)
+		}
+	}
+}
+
+// Look up an order by the client order ID it was placed with
+func (c *BinanceClient) orderByClientID(symbol, clientOrderID string) (*models.Order, error) {
 	params := map[string]string{
-		"timestamp": timestamp,
-		"apiKey":    c.apiKey,
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderID,
+		"timestamp":         utils.GenerateTimestampString(),
+		"apiKey":            c.apiKey,
+	}
+
+	if err := c.addSignature(params); err != nil {
+		return nil, err
+	}
+
+	wsResponse, err := c.call(ClassAccount, "order.status", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if wsResponse.Error != nil {
+		return nil, fmt.Errorf("API error: %s", wsResponse.Error.Msg)
+	}
+
+	var order models.Order
+	if err := json.Unmarshal(wsResponse.Result, &order); err != nil {
+		return nil, fmt.Errorf("error parsing order data: %w", err)
+	}
+
+	return &order, nil
+}
+
+// Random client order ID shared by both copies of a hedged order
+func newClientOrderID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// Send a request on a specific pooled connection and record its health
+func (c *BinanceClient) send(pc *pooledConn, method string, params any) (*models.WebSocketResponse, error) {
+	responseCh := make(chan []byte, 1)
+	start := time.Now()
+
+	requestID, err := pc.client.SendRequest(method, params, func(response []byte) {
+		responseCh <- response
+	})
+	if err != nil {
+		c.pool.release(pc, 0, err)
+		observeRequest(method, start, false, false)
+		return nil, err
 	}
 
-	params["signature"] = utils.GenerateSignature(c.secretKey, params)
+	select {
+	case response := <-responseCh:
+		c.pool.release(pc, time.Since(start), nil)
 
-	_, err := c.wsClient.SendRequest("account.status", params, func(response []byte) {
 		var wsResponse models.WebSocketResponse
 		if err := json.Unmarshal(response, &wsResponse); err != nil {
-			errCh <- fmt.Errorf("error parsing WebSocket response: %w", err)
-			return
+			observeRequest(method, start, true, true)
+			return nil, fmt.Errorf("error parsing %s response: %w", method, err)
 		}
 
-		if wsResponse.Status != 200 {
-			errCh <- fmt.Errorf("API error: %d - %s", wsResponse.Error.Code, wsResponse.Error.Msg)
-			return
-		}
+		observeRequest(method, start, true, wsResponse.Error != nil)
+		c.limiter.Update(wsResponse.RateLimits)
 
-		var accountInfo models.AccountInfo
-		if err := json.Unmarshal(wsResponse.Result, &accountInfo); err != nil {
-			errCh <- fmt.Errorf("error parsing account data: %w", err)
-			return
-		}
+		return &wsResponse, nil
 
-		accountResp := models.AccountResponse{
-			Status:      wsResponse.Status,
-			AccountInfo: accountInfo,
-		}
+	case <-time.After(requestTimeout):
+		// A late response has nowhere to go
+		pc.client.CancelRequest(requestID)
 
-		resultCh <- &accountResp
-	})
+		err := fmt.Errorf("timeout waiting for %s response", method)
+		c.pool.release(pc, 0, err)
+		observeRequest(method, start, false, false)
+		return nil, err
+	}
+}
+
+func (c *BinanceClient) TestSignature() error {
+	timestamp := fmt.Sprintf("%d", time.Now().UnixMilli())
+
+	params := map[string]string{
+		"timestamp": timestamp,
+		"apiKey":    c.apiKey,
+	}
+
+	if err := c.addSignature(params); err != nil {
+		return err
+	}
+
+	requestParams := make(map[string]any)
+	for k, v := range params {
+		requestParams[k] = v
+	}
 
+	slog.Debug("Sending test request", "method", "account.status")
+
+	wsResponse, err := c.call(ClassAccount, "account.status", requestParams)
 	if err != nil {
+		return err
+	}
+
+	if wsResponse.Error != nil {
+		return fmt.Errorf("API error: %s", wsResponse.Error.Msg)
+	}
+
+	return nil
+}
+
+func (c *BinanceClient) GetAccountBalance() (*models.AccountResponse, error) {
+	timestamp := utils.GenerateTimestampString()
+
+	params := map[string]string{
+		"timestamp": timestamp,
+		"apiKey":    c.apiKey,
+	}
+
+	if err := c.addSignature(params); err != nil {
 		return nil, err
 	}
 
-	select {
-	case result := <-resultCh:
-		return result, nil
-	case err := <-errCh:
+	wsResponse, err := c.call(ClassAccount, "account.status", params)
+	if err != nil {
 		return nil, err
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for order response")
 	}
+
+	if wsResponse.Status != 200 {
+		return nil, fmt.Errorf("API error: %d - %s", wsResponse.Error.Code, wsResponse.Error.Msg)
+	}
+
+	var accountInfo models.AccountInfo
+	if err := json.Unmarshal(wsResponse.Result, &accountInfo); err != nil {
+		return nil, fmt.Errorf("error parsing account data: %w", err)
+	}
+
+	return &models.AccountResponse{
+		Status:      wsResponse.Status,
+		AccountInfo: accountInfo,
+	}, nil
 }
 
-// Retrieve and returns balance information for a specific trading pair
-func (c *BinanceClient) GetTradingPairBalance(baseAsset string, quoteAsset string) (map[string]float64, error) {
+func (c *BinanceClient) GetTradingPairBalance(baseAsset string, quoteAsset string) (map[string]decimal.Decimal, error) {
 	accountResp, err := c.GetAccountBalance()
 	if err != nil {
 		return nil, fmt.Errorf("error getting account balance: %w", err)
@@ -165,29 +434,15 @@ func (c *BinanceClient) GetTradingPairBalance(baseAsset string, quoteAsset strin
 	}
 
 	// Extract balances for these specific assets
-	balances := make(map[string]float64)
-	balances[baseAsset] = 0
-	balances[quoteAsset] = 0
+	balances := make(map[string]decimal.Decimal)
+	balances[baseAsset] = decimal.Zero
+	balances[quoteAsset] = decimal.Zero
 
 	// Find the assets in the account balances
 	for _, balance := range accountResp.AccountInfo.Balances {
 		if balance.Asset == baseAsset || balance.Asset == quoteAsset {
-			// Parse the free balance as a float
-			freeAmount, err := strconv.ParseFloat(balance.Free, 64)
-			if err != nil {
-				log.Printf("Warning: Could not parse free balance for %s: %v", balance.Asset, err)
-				freeAmount = 0
-			}
-
-			// Parse the locked balance as a float
-			lockedAmount, err := strconv.ParseFloat(balance.Locked, 64)
-			if err != nil {
-				log.Printf("Warning: Could not parse locked balance for %s: %v", balance.Asset, err)
-				lockedAmount = 0
-			}
-
 			// Store the total balance (free + locked)
-			balances[balance.Asset] = freeAmount + lockedAmount
+			balances[balance.Asset] = balance.Free.Add(balance.Locked)
 		}
 	}
 
@@ -203,19 +458,19 @@ func (c *BinanceClient) DisplayTradingPairBalance(baseAsset string, quoteAsset s
 	}
 
 	fmt.Printf("\n=== BALANCE FOR %s ===\n", symbol)
-	fmt.Printf("Base Asset (%s): %.8f\n", baseAsset, balances[baseAsset])
-	fmt.Printf("Quote Asset (%s): %.8f\n", quoteAsset, balances[quoteAsset])
+	fmt.Printf("Base Asset (%s): %s\n", baseAsset, balances[baseAsset].StringFixed(decimal.Places))
+	fmt.Printf("Quote Asset (%s): %s\n", quoteAsset, balances[quoteAsset].StringFixed(decimal.Places))
 
 	// If we have market price information, we can calculate the total value
-	orderbook, err := c.GetOrderbook(1)
+	orderbook, err := c.GetOrderbook(symbol, 1)
 	if err == nil && len(orderbook.Bids) > 0 {
 		midPrice := orderbook.Bids[0].Price
-		baseValue := balances[baseAsset] * midPrice
-		totalValue := baseValue + balances[quoteAsset]
+		baseValue := balances[baseAsset].Mul(midPrice)
+		totalValue := baseValue.Add(balances[quoteAsset])
 
-		fmt.Printf("\nCurrent Price: %.8f %s/%s\n", midPrice, baseAsset, quoteAsset)
-		fmt.Printf("Base Asset Value: %.8f %s\n", baseValue, quoteAsset)
-		fmt.Printf("Total Value: %.8f %s\n", totalValue, quoteAsset)
+		fmt.Printf("\nCurrent Price: %s %s/%s\n", midPrice.StringFixed(decimal.Places), baseAsset, quoteAsset)
+		fmt.Printf("Base Asset Value: %s %s\n", baseValue.StringFixed(decimal.Places), quoteAsset)
+		fmt.Printf("Total Value: %s %s\n", totalValue.StringFixed(decimal.Places), quoteAsset)
 	}
 
 	fmt.Println("========================")
@@ -223,75 +478,49 @@ func (c *BinanceClient) DisplayTradingPairBalance(baseAsset string, quoteAsset s
 }
 
 // Get current order book
-func (c *BinanceClient) GetOrderbook(limit int) (*models.ParsedOrderBook, error) {
-	resultCh := make(chan *models.ParsedOrderBook, 1)
-	errCh := make(chan error, 1)
-
-	// Send the request
-	_, err := c.wsClient.SendRequest("depth", map[string]any{
-		"symbol": c.symbol,
+func (c *BinanceClient) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
+	wsResponse, err := c.call(ClassMarketData, "depth", map[string]any{
+		"symbol": symbol,
 		"limit":  limit,
-	}, func(response []byte) {
-		var wsResponse models.WebSocketResponse
-		if err := json.Unmarshal(response, &wsResponse); err != nil {
-			errCh <- fmt.Errorf("error parsing orderbook response: %w", err)
-			return
-		}
-
-		if wsResponse.Error != nil {
-			errCh <- fmt.Errorf("API error: %s", wsResponse.Error.Msg)
-			return
-		}
-
-		resultJSON, err := json.Marshal(wsResponse.Result)
-		if err != nil {
-			errCh <- fmt.Errorf("error marshaling result: %w", err)
-			return
-		}
-
-		var orderbook models.OrderbookDepth
-		if err := json.Unmarshal(resultJSON, &orderbook); err != nil {
-			errCh <- fmt.Errorf("error parsing orderbook data: %w", err)
-			return
-		}
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		parsedBook, err := parseOrderbook(&orderbook)
-		if err != nil {
-			errCh <- fmt.Errorf("error parsing orderbook values: %w", err)
-			return
-		}
+	if wsResponse.Error != nil {
+		return nil, fmt.Errorf("API error: %s", wsResponse.Error.Msg)
+	}
 
-		parsedBook.Symbol = c.symbol
-		resultCh <- parsedBook
-	})
+	var orderbook models.OrderbookDepth
+	if err := json.Unmarshal(wsResponse.Result, &orderbook); err != nil {
+		return nil, fmt.Errorf("error parsing orderbook data: %w", err)
+	}
 
+	parsedBook, err := parseOrderbook(&orderbook)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error parsing orderbook values: %w", err)
 	}
 
-	select {
-	case result := <-resultCh:
-		return result, nil
-	case err := <-errCh:
-		return nil, err
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for orderbook response")
+	parsedBook.Symbol = symbol
+
+	// Every fresh book may fill resting simulated orders
+	if c.sim != nil {
+		c.matchSimulated(parsedBook)
 	}
+
+	return parsedBook, nil
 }
 
 // Place a new order
-func (c *BinanceClient) PlaceOrder(side, orderType, price, quantity string) (*models.Order, error) {
-	if err := utils.AuthenticateAPIKeys(c.apiKey, c.secretKey); err != nil {
-		log.Fatalf("Authentication failed: %v", err)
+func (c *BinanceClient) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	if err := utils.AuthenticateAPIKeys(c.apiKey, c.signer); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	resultCh := make(chan *models.Order, 1)
-	errCh := make(chan error, 1)
-
 	timestamp := utils.GenerateTimestampString()
 
 	params := map[string]string{
-		"symbol":    c.symbol,
+		"symbol":    symbol,
 		"side":      side,
 		"type":      orderType,
 		"timestamp": timestamp,
@@ -303,213 +532,457 @@ func (c *BinanceClient) PlaceOrder(side, orderType, price, quantity string) (*mo
 		params["quantity"] = quantity
 		params["timeInForce"] = "GTC"
 
-		log.Printf("Placing %s BUY order: %s %s @ %s", orderType, c.symbol, quantity, price)
+		slog.Info("Placing order", "symbol", symbol, "side", side, "type", orderType, "quantity", quantity, "price", price)
 
 	} else if orderType == "MARKET" {
 		params["quantity"] = quantity
 
-		log.Printf("Placing %s BUY order: %s %s", orderType, c.symbol, quantity)
+		slog.Info("Placing order", "symbol", symbol, "side", side, "type", orderType, "quantity", quantity)
 	}
 
-	params["signature"] = utils.GenerateSignature(c.secretKey, params)
-
-	_, err := c.wsClient.SendRequest("order.place", params, func(response []byte) {
-		var wsResponse models.WebSocketResponse
-		if err := json.Unmarshal(response, &wsResponse); err != nil {
-			errCh <- fmt.Errorf("error parsing order response: %w", err)
-			return
-		}
-
-		if wsResponse.Error != nil {
-			errCh <- fmt.Errorf("API error: %s", wsResponse.Error.Msg)
-			return
-		}
+	if c.sim != nil {
+		return c.placeSimulated(params)
+	}
 
-		resultJSON, err := json.Marshal(wsResponse.Result)
-		if err != nil {
-			errCh <- fmt.Errorf("error marshaling result: %w", err)
-			return
-		}
+	// A market order fills at once and frees its client order ID, so a
+	// second copy would always fill again
+	hedged := orderType == "LIMIT" && c.pool.HedgingOrders()
+	if hedged {
+		params["newClientOrderId"] = newClientOrderID()
+	}
 
-		var order models.Order
-		if err := json.Unmarshal(resultJSON, &order); err != nil {
-			errCh <- fmt.Errorf("error parsing order data: %w", err)
-			return
-		}
+	if err := c.addSignature(params); err != nil {
+		return nil, err
+	}
 
-		c.orderManager.TrackOrder(&order)
+	// Every symbol draws from the same order budget
+	c.limiter.WaitOrder()
 
-		resultCh <- &order
-	})
+	if hedged {
+		// The exchange counts the second copy against the budget too
+		c.limiter.WaitOrder()
+		return c.placeHedged(params)
+	}
 
+	wsResponse, err := c.call(ClassTrading, "order.place", params)
 	if err != nil {
 		return nil, err
 	}
 
-	select {
-	case result := <-resultCh:
-		return result, nil
-	case err := <-errCh:
-		return nil, err
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for order response")
+	if wsResponse.Error != nil {
+		return nil, fmt.Errorf("API error: %s", wsResponse.Error.Msg)
 	}
+
+	var order models.Order
+	if err := json.Unmarshal(wsResponse.Result, &order); err != nil {
+		return nil, fmt.Errorf("error parsing order data: %w", err)
+	}
+
+	c.orderManager.TrackOrder(&order)
+
+	return &order, nil
 }
 
 // Cancel an active order
-func (c *BinanceClient) CancelOrder(orderID int64) (*models.Order, error) {
-	if err := utils.AuthenticateAPIKeys(c.apiKey, c.secretKey); err != nil {
-		log.Fatalf("Authentication failed: %v", err)
+func (c *BinanceClient) CancelOrder(symbol string, orderID int64) (*models.Order, error) {
+	if err := utils.AuthenticateAPIKeys(c.apiKey, c.signer); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	resultCh := make(chan *models.Order, 1)
-	errCh := make(chan error, 1)
+	if c.sim != nil {
+		return c.cancelSimulated(symbol, orderID)
+	}
 
 	timestamp := utils.GenerateTimestampString()
 
 	params := map[string]string{
-		"symbol":    c.symbol,
+		"symbol":    symbol,
 		"orderId":   fmt.Sprintf("%d", orderID),
 		"timestamp": timestamp,
 		"apiKey":    c.apiKey,
 	}
 
-	params["signature"] = utils.GenerateSignature(c.secretKey, params)
-
-	_, err := c.wsClient.SendRequest("order.cancel", params, func(response []byte) {
-		var wsResponse models.WebSocketResponse
-		if err := json.Unmarshal(response, &wsResponse); err != nil {
-			errCh <- fmt.Errorf("error parsing cancel response: %w", err)
-			return
-		}
-
-		if wsResponse.Error != nil {
-			errCh <- fmt.Errorf("API error: %s", wsResponse.Error.Msg)
-			return
-		}
+	if err := c.addSignature(params); err != nil {
+		return nil, err
+	}
 
-		resultJSON, err := json.Marshal(wsResponse.Result)
-		if err != nil {
-			errCh <- fmt.Errorf("error marshaling result: %w", err)
-			return
-		}
+	wsResponse, err := c.call(ClassTrading, "order.cancel", params)
+	if err != nil {
+		return nil, err
+	}
 
-		var order models.Order
-		if err := json.Unmarshal(resultJSON, &order); err != nil {
-			errCh <- fmt.Errorf("error parsing order data: %w", err)
-			return
+	if wsResponse.Error != nil {
+		// An order that can't be canceled has usually filled, so record its
+		// final state rather than leave it open in the order manager
+		if _, err := c.GetOrderStatus(symbol, orderID); err != nil {
+			slog.Warn("Failed to refresh order after cancel error", "symbol", symbol, "orderId", orderID, "error", err)
 		}
 
-		c.orderManager.UpdateOrder(&order)
-
-		resultCh <- &order
-	})
-
-	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("API error: %s", wsResponse.Error.Msg)
 	}
 
-	select {
-	case result := <-resultCh:
-		return result, nil
-	case err := <-errCh:
-		return nil, err
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for cancel response")
+	var order models.Order
+	if err := json.Unmarshal(wsResponse.Result, &order); err != nil {
+		return nil, fmt.Errorf("error parsing order data: %w", err)
 	}
+
+	c.orderManager.UpdateOrder(&order)
+
+	return &order, nil
 }
 
 // Check execution status of an order
-func (c *BinanceClient) GetOrderStatus(orderID int64) (*models.Order, error) {
-	if err := utils.AuthenticateAPIKeys(c.apiKey, c.secretKey); err != nil {
-		log.Fatalf("Authentication failed: %v", err)
+func (c *BinanceClient) GetOrderStatus(symbol string, orderID int64) (*models.Order, error) {
+	if err := utils.AuthenticateAPIKeys(c.apiKey, c.signer); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
 	if orderID == -1 {
 		return nil, fmt.Errorf("invalid orderID")
 	}
 
-	if order, err := c.orderManager.GetOrder(orderID); err == nil {
-		return order, nil
+	// A tracked order can still fill until it reaches a final status
+	order, err := c.orderManager.GetOrder(symbol, orderID)
+	if (err == nil && !isOpen(order.Status)) || c.sim != nil {
+		return order, err
 	}
 
-	resultCh := make(chan *models.Order, 1)
-	errCh := make(chan error, 1)
-
 	timestamp := utils.GenerateTimestampString()
 
 	params := map[string]string{
-		"symbol":    c.symbol,
+		"symbol":    symbol,
 		"orderId":   fmt.Sprintf("%d", orderID),
 		"timestamp": timestamp,
 		"apiKey":    c.apiKey,
 	}
 
-	params["signature"] = utils.GenerateSignature(c.secretKey, params)
+	if err := c.addSignature(params); err != nil {
+		return nil, err
+	}
 
-	fmt.Printf("Params: %s", params)
+	wsResponse, err := c.call(ClassAccount, "order.status", params)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := c.wsClient.SendRequest("order.status", params, func(response []byte) {
-		var wsResponse models.WebSocketResponse
-		if err := json.Unmarshal(response, &wsResponse); err != nil {
-			errCh <- fmt.Errorf("error parsing status response: %w", err)
-			return
+	if wsResponse.Error != nil {
+		return nil, fmt.Errorf("API error: %s", wsResponse.Error.Msg)
+	}
+
+	order = &models.Order{}
+	if err := json.Unmarshal(wsResponse.Result, order); err != nil {
+		return nil, fmt.Errorf("error parsing order data: %w", err)
+	}
+
+	if err := c.orderManager.UpdateOrder(order); err != nil {
+		c.orderManager.TrackOrder(order)
+	}
+
+	return order, nil
+}
+
+// Add the API key, timestamp and signature to request parameters
+func (c *BinanceClient) sign(params map[string]string) (map[string]string, error) {
+	params["timestamp"] = utils.GenerateTimestampString()
+	params["apiKey"] = c.apiKey
+
+	if err := c.addSignature(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// Sign the parameters with the configured credentials
+func (c *BinanceClient) addSignature(params map[string]string) error {
+	signature, err := utils.GenerateSignature(c.signer, params)
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	params["signature"] = signature
+	return nil
+}
+
+// Decode a successful response result into out
+func decodeResult(wsResponse *models.WebSocketResponse, out any) error {
+	if wsResponse.Error != nil {
+		return fmt.Errorf("API error: %s", wsResponse.Error.Msg)
+	}
+
+	if err := json.Unmarshal(wsResponse.Result, out); err != nil {
+		return fmt.Errorf("error parsing result: %w", err)
+	}
+
+	return nil
+}
+
+// Open orders for a symbol, or for every symbol when symbol is empty
+func (c *BinanceClient) GetOpenOrders(symbol string) ([]models.Order, error) {
+	if c.sim != nil {
+		return c.openSimulated(symbol), nil
+	}
+
+	params := map[string]string{}
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+
+	signed, err := c.sign(params)
+	if err != nil {
+		return nil, err
+	}
+
+	wsResponse, err := c.call(ClassAccount, "openOrders.status", signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []models.Order
+	if err := decodeResult(wsResponse, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// Most recent orders for a symbol in any state, up to limit (0 uses the exchange default)
+func (c *BinanceClient) GetAllOrders(symbol string, limit int) ([]models.Order, error) {
+	if c.sim != nil {
+		return c.allSimulated(symbol, limit), nil
+	}
+
+	params := map[string]string{"symbol": symbol}
+	if limit > 0 {
+		params["limit"] = fmt.Sprintf("%d", limit)
+	}
+
+	signed, err := c.sign(params)
+	if err != nil {
+		return nil, err
+	}
+
+	wsResponse, err := c.call(ClassAccount, "allOrders", signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []models.Order
+	if err := decodeResult(wsResponse, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// Most recent account trades for a symbol, up to limit (0 uses the exchange default)
+func (c *BinanceClient) GetMyTrades(symbol string, limit int) ([]models.Trade, error) {
+	if c.sim != nil {
+		return c.tradesSimulated(symbol, limit), nil
+	}
+
+	params := map[string]string{"symbol": symbol}
+	if limit > 0 {
+		params["limit"] = fmt.Sprintf("%d", limit)
+	}
+
+	signed, err := c.sign(params)
+	if err != nil {
+		return nil, err
+	}
+
+	wsResponse, err := c.call(ClassAccount, "myTrades", signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []models.Trade
+	if err := decodeResult(wsResponse, &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+// Cancel every open order on a symbol
+func (c *BinanceClient) CancelAllOrders(symbol string) ([]models.Order, error) {
+	if c.sim != nil {
+		var orders []models.Order
+		for _, open := range c.openSimulated(symbol) {
+			// An order filled since it was listed is no longer open, which is fine
+			if order, err := c.cancelSimulated(open.Symbol, open.OrderID); err == nil {
+				orders = append(orders, *order)
+			}
 		}
 
-		if wsResponse.Error != nil {
-			errCh <- fmt.Errorf("API error: %s", wsResponse.Error.Msg)
-			return
+		return orders, nil
+	}
+
+	signed, err := c.sign(map[string]string{"symbol": symbol})
+	if err != nil {
+		return nil, err
+	}
+
+	wsResponse, err := c.call(ClassTrading, "openOrders.cancelAll", signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Order
+	if err := decodeResult(wsResponse, &results); err != nil {
+		return nil, err
+	}
+
+	// Canceled order lists are reported alongside orders without an order ID
+	var orders []models.Order
+	for i := range results {
+		if results[i].OrderID == 0 {
+			continue
 		}
 
-		resultJSON, err := json.Marshal(wsResponse.Result)
+		c.orderManager.UpdateOrder(&results[i])
+		orders = append(orders, results[i])
+	}
+
+	return orders, nil
+}
+
+// Most recent public trades on a symbol, oldest first, up to limit (0 uses the exchange default)
+func (c *BinanceClient) GetRecentTrades(symbol string, limit int) ([]models.MarketTrade, error) {
+	params := map[string]any{"symbol": symbol}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+
+	wsResponse, err := c.call(ClassMarketData, "trades.recent", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []models.MarketTrade
+	if err := decodeResult(wsResponse, &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+// Candlesticks for a symbol and interval (e.g. "1m", "1h"), oldest first.
+// The last candle may still be forming.
+func (c *BinanceClient) GetKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error) {
+	return c.klines("klines", symbol, interval, query)
+}
+
+// Candlesticks as klines, adjusted by the exchange for presentation in charts
+func (c *BinanceClient) GetUIKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error) {
+	return c.klines("uiKlines", symbol, interval, query)
+}
+
+func (c *BinanceClient) klines(method, symbol, interval string, query models.KlineQuery) ([]models.Candle, error) {
+	params := map[string]any{
+		"symbol":   symbol,
+		"interval": interval,
+	}
+	if !query.StartTime.IsZero() {
+		params["startTime"] = query.StartTime.UnixMilli()
+	}
+	if !query.EndTime.IsZero() {
+		params["endTime"] = query.EndTime.UnixMilli()
+	}
+	if query.Limit > 0 {
+		params["limit"] = query.Limit
+	}
+	if query.TimeZone != "" {
+		params["timeZone"] = query.TimeZone
+	}
+
+	wsResponse, err := c.call(ClassMarketData, method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]json.RawMessage
+	if err := decodeResult(wsResponse, &rows); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	candles := make([]models.Candle, len(rows))
+	for i, row := range rows {
+		candle, err := parseKline(row)
 		if err != nil {
-			errCh <- fmt.Errorf("error marshaling result: %w", err)
-			return
+			return nil, fmt.Errorf("error parsing %s row %d: %w", method, i, err)
 		}
 
-		var order models.Order
-		if err := json.Unmarshal(resultJSON, &order); err != nil {
-			errCh <- fmt.Errorf("error parsing order data: %w", err)
-			return
-		}
+		candle.Symbol = symbol
+		candle.Closed = candle.CloseTime.Before(now)
+		candles[i] = candle
+	}
 
-		c.orderManager.TrackOrder(&order)
+	return candles, nil
+}
 
-		resultCh <- &order
-	})
+// Trading rules for the given symbols, or for every symbol when none are given
+func (c *BinanceClient) GetExchangeInfo(symbols ...string) (*models.ExchangeInfo, error) {
+	params := map[string]any{}
+	if len(symbols) > 0 {
+		params["symbols"] = symbols
+	}
 
+	wsResponse, err := c.call(ClassMarketData, "exchangeInfo", params)
 	if err != nil {
 		return nil, err
 	}
 
-	select {
-	case result := <-resultCh:
-		return result, nil
-	case err := <-errCh:
+	var info models.ExchangeInfo
+	if err := decodeResult(wsResponse, &info); err != nil {
 		return nil, err
-	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("timeout waiting for status response")
 	}
+
+	return &info, nil
 }
 
-func (c *BinanceClient) DisplayOrderbook(book *models.ParsedOrderBook, limit int) {
-	log.Printf("Orderbook LastUpdateID: %d", book.LastUpdateID)
-	log.Println("Bids (Buy Orders):")
-	log.Println("Price\t\tQuantity")
+// Measure the offset between the exchange clock and ours, taking the local
+// time halfway through the round trip as the moment the server answered
+func (c *BinanceClient) SyncTime() (time.Duration, error) {
+	sent := time.Now()
 
-	maxBids := min(len(book.Bids), limit)
-	for i := range maxBids {
-		log.Printf("%.8f\t%.8f", book.Bids[i].Price, book.Bids[i].Quantity)
+	wsResponse, err := c.call(ClassMarketData, "time", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := decodeResult(wsResponse, &result); err != nil {
+		return 0, err
 	}
 
-	log.Println()
-	log.Println("Asks (Sell Orders):")
-	log.Println("Price\t\tQuantity")
+	local := sent.Add(time.Since(sent) / 2)
+	offset := time.UnixMilli(result.ServerTime).Sub(local)
+	timeOffset.Set(offset.Seconds())
 
-	maxAsks := min(len(book.Asks), limit)
-	for i := range maxAsks {
-		log.Printf("%.8f\t%.8f", book.Asks[i].Price, book.Asks[i].Quantity)
+	return offset, nil
+}
+
+func (c *BinanceClient) DisplayOrderbook(book *models.ParsedOrderBook, limit int) {
+	slog.Info("Orderbook",
+		"symbol", book.Symbol,
+		"lastUpdateId", book.LastUpdateID,
+		"bids", formatLevels(book.Bids, limit),
+		"asks", formatLevels(book.Asks, limit),
+	)
+}
+
+// Up to limit levels as "price x quantity"
+func formatLevels(levels []models.PriceLevel, limit int) []string {
+	formatted := make([]string, 0, min(len(levels), limit))
+	for _, level := range levels[:min(len(levels), limit)] {
+		formatted = append(formatted, level.Price.StringFixed(decimal.Places)+" x "+level.Quantity.StringFixed(decimal.Places))
 	}
+
+	return formatted
 }
 
 func (c *BinanceClient) DisplayAccountBalance(data *models.AccountResponse) {
@@ -531,7 +1004,7 @@ func (c *BinanceClient) DisplayAccountBalance(data *models.AccountResponse) {
 	}
 }
 
-func (c *BinanceClient) HasSufficientBalance(baseAsset string, quoteAsset string, side string, quantity float64, price float64) (bool, error) {
+func (c *BinanceClient) HasSufficientBalance(baseAsset string, quoteAsset string, side string, quantity decimal.Decimal, price decimal.Decimal) (bool, error) {
 	balances, err := c.GetTradingPairBalance(baseAsset, quoteAsset)
 	if err != nil {
 		return false, err
@@ -539,37 +1012,38 @@ func (c *BinanceClient) HasSufficientBalance(baseAsset string, quoteAsset string
 
 	if side == "BUY" {
 		// For a buy order, check if we have enough quote asset (e.g., USDT)
-		requiredAmount := quantity * price
-		return balances[quoteAsset] >= requiredAmount, nil
+		requiredAmount := quantity.Mul(price)
+		return balances[quoteAsset].Cmp(requiredAmount) >= 0, nil
 	} else if side == "SELL" {
 		// For a sell order, check if we have enough base asset (e.g., BTC)
-		return balances[baseAsset] >= quantity, nil
+		return balances[baseAsset].Cmp(quantity) >= 0, nil
 	}
 
 	return false, fmt.Errorf("invalid side: %s", side)
 }
 
-// Calculate the maximum order size based on available balance
-func (c *BinanceClient) GetMaxOrderSize(baseAsset string, quoteAsset string, side string, price float64) (float64, error) {
+// Calculate the maximum order size based on available balance,
+// rounded down to the symbol's lot step size
+func (c *BinanceClient) GetMaxOrderSize(baseAsset string, quoteAsset string, side string, price decimal.Decimal, stepSize decimal.Decimal) (decimal.Decimal, error) {
 	balances, err := c.GetTradingPairBalance(baseAsset, quoteAsset)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
 	if side == "BUY" {
+		if !price.IsPositive() {
+			return decimal.Zero, fmt.Errorf("invalid price: %s", price)
+		}
+
 		// For a buy order, the max quantity is limited by quote asset (e.g., USDT)
-		maxQuantity := balances[quoteAsset] / price
-		// Round down to 6 decimal places or whatever precision is appropriate for the asset
-		maxQuantity = math.Floor(maxQuantity*1000000) / 1000000
-		return maxQuantity, nil
+		maxQuantity := balances[quoteAsset].Div(price, decimal.Floor)
+		return maxQuantity.RoundToStep(stepSize, decimal.Floor), nil
 	} else if side == "SELL" {
 		// For a sell order, the max quantity is the base asset amount (e.g., BTC)
-		// Round down to 6 decimal places or whatever precision is appropriate for the asset
-		maxQuantity := math.Floor(balances[baseAsset]*1000000) / 1000000
-		return maxQuantity, nil
+		return balances[baseAsset].RoundToStep(stepSize, decimal.Floor), nil
 	}
 
-	return 0, fmt.Errorf("invalid side: %s", side)
+	return decimal.Zero, fmt.Errorf("invalid side: %s", side)
 }
 
 func parseOrderbook(data *models.OrderbookDepth) (*models.ParsedOrderBook, error) {
@@ -584,17 +1058,12 @@ func parseOrderbook(data *models.OrderbookDepth) (*models.ParsedOrderBook, error
 			continue
 		}
 
-		price, err := strconv.ParseFloat(bid[0], 64)
-		if err != nil {
-			return nil, err
-		}
-
-		qty, err := strconv.ParseFloat(bid[1], 64)
+		level, err := parsePriceLevel(bid)
 		if err != nil {
 			return nil, err
 		}
 
-		result.Bids[i] = models.PriceLevel{Price: price, Quantity: qty}
+		result.Bids[i] = level
 	}
 
 	for i, ask := range data.Asks {
@@ -602,18 +1071,66 @@ func parseOrderbook(data *models.OrderbookDepth) (*models.ParsedOrderBook, error
 			continue
 		}
 
-		price, err := strconv.ParseFloat(ask[0], 64)
+		level, err := parsePriceLevel(ask)
 		if err != nil {
 			return nil, err
 		}
 
-		qty, err := strconv.ParseFloat(ask[1], 64)
-		if err != nil {
-			return nil, err
+		result.Asks[i] = level
+	}
+
+	return result, nil
+}
+
+// Decode a kline row: open time, open, high, low, close, volume, close time,
+// quote volume, trades, taker buy volume, taker buy quote volume, unused
+func parseKline(row []json.RawMessage) (models.Candle, error) {
+	if len(row) < 11 {
+		return models.Candle{}, fmt.Errorf("kline has %d fields, expected at least 11", len(row))
+	}
+
+	var candle models.Candle
+	var openTime, closeTime int64
+
+	fields := []struct {
+		index int
+		value any
+	}{
+		{0, &openTime},
+		{1, &candle.Open},
+		{2, &candle.High},
+		{3, &candle.Low},
+		{4, &candle.Close},
+		{5, &candle.Volume},
+		{6, &closeTime},
+		{7, &candle.QuoteVolume},
+		{8, &candle.Trades},
+		{9, &candle.TakerBuyVolume},
+		{10, &candle.TakerBuyQuoteVolume},
+	}
+
+	for _, field := range fields {
+		if err := json.Unmarshal(row[field.index], field.value); err != nil {
+			return models.Candle{}, fmt.Errorf("kline field %d: %w", field.index, err)
 		}
+	}
 
-		result.Asks[i] = models.PriceLevel{Price: price, Quantity: qty}
+	candle.OpenTime = time.UnixMilli(openTime)
+	candle.CloseTime = time.UnixMilli(closeTime)
+	return candle, nil
+}
+
+// Parse a [price, quantity] pair
+func parsePriceLevel(pair []string) (models.PriceLevel, error) {
+	price, err := decimal.NewFromString(pair[0])
+	if err != nil {
+		return models.PriceLevel{}, err
 	}
 
-	return result, nil
+	qty, err := decimal.NewFromString(pair[1])
+	if err != nil {
+		return models.PriceLevel{}, err
+	}
+
+	return models.PriceLevel{Price: price, Quantity: qty}, nil
 }
@@ -1,10 +1,24 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/logging"
 	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/utils"
+	"github.com/iamramtin/binance-trader/internal/websocket"
 )
 
 // Mock version of the websocket client for testing
@@ -53,14 +67,14 @@ func (m *MockWebSocketClient) SetMockResponse(method string, response []byte) {
 
 // TestNew tests the creation of a new BinanceClient
 func TestNew(t *testing.T) {
-	client := New("wss://testnet.binance.vision/ws", "apiKey", "secretKey", "BTCUSDT")
+	client := New("wss://testnet.binance.vision/ws", "apiKey", "secretKey")
 
 	if client == nil {
 		t.Fatal("New() returned nil")
 	}
 
-	if client.wsClient == nil {
-		t.Error("websocket client was not initialized")
+	if client.pool == nil {
+		t.Error("connection pool was not initialized")
 	}
 
 	if client.orderManager == nil {
@@ -71,12 +85,12 @@ func TestNew(t *testing.T) {
 		t.Errorf("expected apiKey to be 'apiKey', got %s", client.apiKey)
 	}
 
-	if client.secretKey != "secretKey" {
-		t.Errorf("expected secretKey to be 'secretKey', got %s", client.secretKey)
+	if client.signer != utils.HMACSigner("secretKey") {
+		t.Errorf("expected an HMAC signer with 'secretKey', got %v", client.signer)
 	}
 
-	if client.symbol != "BTCUSDT" {
-		t.Errorf("expected symbol to be 'BTCUSDT', got %s", client.symbol)
+	if client.limiter == nil {
+		t.Error("rate limiter was not initialized")
 	}
 }
 
@@ -111,19 +125,605 @@ func TestParseOrderbook(t *testing.T) {
 		t.Errorf("expected 2 asks, got %d", len(result.Asks))
 	}
 
-	if result.Bids[0].Price != 40000.00 {
-		t.Errorf("expected first bid price to be 40000.00, got %f", result.Bids[0].Price)
+	if result.Bids[0].Price.String() != "40000" {
+		t.Errorf("expected first bid price to be 40000.00, got %s", result.Bids[0].Price)
 	}
 
-	if result.Bids[0].Quantity != 1.5 {
-		t.Errorf("expected first bid quantity to be 1.5, got %f", result.Bids[0].Quantity)
+	if result.Bids[0].Quantity.String() != "1.5" {
+		t.Errorf("expected first bid quantity to be 1.5, got %s", result.Bids[0].Quantity)
 	}
 
-	if result.Asks[0].Price != 40100.00 {
-		t.Errorf("expected first ask price to be 40100.00, got %f", result.Asks[0].Price)
+	if result.Asks[0].Price.String() != "40100" {
+		t.Errorf("expected first ask price to be 40100.00, got %s", result.Asks[0].Price)
 	}
 
-	if result.Asks[0].Quantity != 1.0 {
-		t.Errorf("expected first ask quantity to be 1.0, got %f", result.Asks[0].Quantity)
+	if result.Asks[0].Quantity.String() != "1" {
+		t.Errorf("expected first ask quantity to be 1.0, got %s", result.Asks[0].Quantity)
+	}
+}
+
+// Answer a request with a status and a result or error body
+type scriptedResponder func(method string, params map[string]string) (status int, body string)
+
+// Create a connected client whose requests are answered in memory
+func newScriptedClient(t *testing.T, respond scriptedResponder, opts ...Option) *BinanceClient {
+	t.Helper()
+
+	transport := websocket.NewPipeTransport(func(server websocket.Conn) {
+		for {
+			message, err := server.Read()
+			if err != nil {
+				return
+			}
+
+			var request struct {
+				ID     string         `json:"id"`
+				Method string         `json:"method"`
+				Params map[string]any `json:"params"`
+			}
+			if err := json.Unmarshal(message, &request); err != nil {
+				t.Errorf("server received invalid request: %v", err)
+				return
+			}
+
+			params := make(map[string]string, len(request.Params))
+			for k, v := range request.Params {
+				params[k] = fmt.Sprintf("%v", v)
+			}
+
+			status, body := respond(request.Method, params)
+
+			field := "result"
+			if status != 200 {
+				field = "error"
+			}
+
+			server.Write([]byte(fmt.Sprintf(`{"id":"%s","status":%d,"%s":%s}`, request.ID, status, field, body)))
+		}
+	})
+
+	opts = append(opts, WithWebSocketOptions(websocket.WithTransport(transport)))
+	client := New("pipe://exchange", "apiKey", "secretKey", opts...)
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func TestGetOrderbook(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method != "depth" || params["symbol"] != "BTCUSDT" || params["limit"] != "5" {
+			return 400, `{"code":-1100,"msg":"unexpected request"}`
+		}
+
+		return 200, `{"lastUpdateId":7,"bids":[["40000.00","1.5"]],"asks":[["40100.00","2.0"]]}`
+	})
+
+	book, err := client.GetOrderbook("BTCUSDT", 5)
+	if err != nil {
+		t.Fatalf("GetOrderbook() returned error: %v", err)
+	}
+
+	if book.Symbol != "BTCUSDT" || book.LastUpdateID != 7 {
+		t.Errorf("unexpected orderbook header: %s %d", book.Symbol, book.LastUpdateID)
+	}
+
+	if book.Bids[0].Price.String() != "40000" || book.Asks[0].Quantity.String() != "2" {
+		t.Errorf("unexpected orderbook levels: %+v %+v", book.Bids, book.Asks)
+	}
+}
+
+func TestPlaceOrderSignsRequest(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		signature := params["signature"]
+		delete(params, "signature")
+
+		if want, _ := utils.GenerateSignature(utils.HMACSigner("secretKey"), params); signature != want {
+			return 400, `{"code":-1022,"msg":"Signature for this request is not valid."}`
+		}
+
+		if params["apiKey"] != "apiKey" || params["timeInForce"] != "GTC" {
+			return 400, `{"code":-1100,"msg":"missing parameters"}`
+		}
+
+		return 200, fmt.Sprintf(`{"symbol":"%s","orderId":99,"price":"%s","origQty":"%s","status":"NEW","side":"%s"}`,
+			params["symbol"], params["price"], params["quantity"], params["side"])
+	})
+
+	order, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "40000.00", "0.001")
+	if err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+
+	if order.OrderID != 99 || order.Price != "40000.00" || order.Side != "BUY" {
+		t.Errorf("unexpected order: %+v", order)
+	}
+
+	if _, err := client.GetOrderManager().GetOrder("BTCUSDT", 99); err != nil {
+		t.Errorf("placed order was not tracked: %v", err)
+	}
+}
+
+func TestPlaceOrderAPIError(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		return 400, `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`
+	})
+
+	_, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "40000.00", "0.001")
+	if err == nil || !strings.Contains(err.Error(), "insufficient balance") {
+		t.Errorf("expected insufficient balance error, got %v", err)
+	}
+
+	if orders := client.GetOrderManager().GetAllOrders(); len(orders) != 0 {
+		t.Errorf("rejected order should not be tracked, got %d orders", len(orders))
+	}
+}
+
+// Wait for a condition a hedged placement settles in the background
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(2 * time.Second); !condition(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+	}
+}
+
+func TestHedgedOrderSharesClientOrderID(t *testing.T) {
+	var mu sync.Mutex
+	placed := map[string]int{}
+	var cancels int
+
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch method {
+		case "order.place":
+			id := params["newClientOrderId"]
+			placed[id]++
+			if placed[id] > 1 {
+				return 400, `{"code":-2010,"msg":"Duplicate order sent."}`
+			}
+			return 200, fmt.Sprintf(`{"symbol":"BTCUSDT","orderId":%d,"clientOrderId":"%s","status":"NEW"}`, len(placed), id)
+		case "order.cancel":
+			cancels++
+		}
+
+		return 400, `{"code":-1100,"msg":"unexpected request"}`
+	}, WithPoolConfig(PoolConfig{TradingConnections: 2, HedgeOrders: true}))
+
+	order, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "40000.00", "0.001")
+	if err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return placed[order.ClientOrderID] == 2
+	})
+
+	// A market order frees its ID at once, so it is sent only once
+	if _, err := client.PlaceOrder("BTCUSDT", "BUY", "MARKET", "", "0.001"); err != nil {
+		t.Fatalf("PlaceOrder(MARKET) returned error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if order.ClientOrderID == "" || len(placed) != 2 || placed[""] != 1 {
+		t.Errorf("expected both limit copies under one client order ID and one market copy, got %v", placed)
+	}
+	if cancels != 0 {
+		t.Errorf("expected a rejected duplicate not to be canceled, got %d cancels", cancels)
+	}
+	if orders := client.GetOrderManager().GetAllOrders(); len(orders) != 2 {
+		t.Errorf("expected one tracked order per placement, got %d", len(orders))
+	}
+}
+
+func TestHedgedOrderCancelsAcceptedDuplicate(t *testing.T) {
+	var placed atomic.Int32
+
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		switch {
+		case method == "order.place" && placed.Add(1) == 1:
+			// The first copy fills at once and frees the ID for the second
+			return 200, `{"symbol":"BTCUSDT","orderId":1,"side":"BUY","status":"FILLED","executedQty":"0.001"}`
+		case method == "order.place":
+			time.Sleep(50 * time.Millisecond)
+			return 200, `{"symbol":"BTCUSDT","orderId":2,"side":"BUY","status":"NEW","executedQty":"0"}`
+		case method == "order.cancel" && params["orderId"] == "2":
+			return 200, `{"symbol":"BTCUSDT","orderId":2,"side":"BUY","status":"CANCELED","executedQty":"0"}`
+		}
+
+		return 400, `{"code":-1100,"msg":"unexpected request"}`
+	}, WithPoolConfig(PoolConfig{TradingConnections: 2, HedgeOrders: true}))
+
+	order, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "40000.00", "0.001")
+	if err != nil || order.OrderID != 1 {
+		t.Fatalf("PlaceOrder() = %+v, %v, want the first copy", order, err)
+	}
+
+	eventually(t, func() bool {
+		duplicate, err := client.GetOrderManager().GetOrder("BTCUSDT", 2)
+		return err == nil && duplicate.Status == "CANCELED"
+	})
+}
+
+func TestHedgedOrderFoundWhenResultDoesNotParse(t *testing.T) {
+	var placed atomic.Int32
+
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		switch {
+		case method == "order.place" && placed.Add(1) == 1:
+			return 200, `{"symbol":"BTCUSDT","orderId":"not a number"}`
+		case method == "order.place":
+			return 400, `{"code":-2010,"msg":"Duplicate order sent."}`
+		case method == "order.status" && params["origClientOrderId"] != "":
+			return 200, fmt.Sprintf(`{"symbol":"BTCUSDT","orderId":7,"clientOrderId":"%s","status":"NEW"}`, params["origClientOrderId"])
+		}
+
+		return 400, `{"code":-1100,"msg":"unexpected request"}`
+	}, WithPoolConfig(PoolConfig{TradingConnections: 2, HedgeOrders: true}))
+
+	order, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "40000.00", "0.001")
+	if err != nil || order.OrderID != 7 {
+		t.Fatalf("PlaceOrder() = %+v, %v, want the order found by its client order ID", order, err)
+	}
+
+	if _, err := client.GetOrderManager().GetOrder("BTCUSDT", 7); err != nil {
+		t.Errorf("expected the found order to be tracked: %v", err)
+	}
+}
+
+func TestCancelOrderUpdatesOrderManager(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method != "order.cancel" || params["orderId"] != "99" {
+			return 400, `{"code":-1100,"msg":"unexpected request"}`
+		}
+
+		return 200, `{"symbol":"BTCUSDT","orderId":99,"status":"CANCELED"}`
+	})
+
+	client.GetOrderManager().TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 99, Status: "NEW"})
+
+	if _, err := client.CancelOrder("BTCUSDT", 99); err != nil {
+		t.Fatalf("CancelOrder() returned error: %v", err)
+	}
+
+	order, _ := client.GetOrderManager().GetOrder("BTCUSDT", 99)
+	if order.Status != "CANCELED" {
+		t.Errorf("expected tracked order to be CANCELED, got %s", order.Status)
+	}
+}
+
+func TestGetOrderStatusRefreshesOpenOrders(t *testing.T) {
+	var requests int
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		requests++
+		if method != "order.status" || params["orderId"] != "99" {
+			return 400, `{"code":-1100,"msg":"unexpected request"}`
+		}
+
+		return 200, `{"symbol":"BTCUSDT","orderId":99,"status":"FILLED","executedQty":"1"}`
+	})
+
+	client.GetOrderManager().TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 99, Status: "NEW"})
+
+	for range 2 {
+		order, err := client.GetOrderStatus("BTCUSDT", 99)
+		if err != nil || order.Status != "FILLED" {
+			t.Fatalf("GetOrderStatus() = %+v, %v, want FILLED", order, err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected a filled order to be answered from tracking, got %d requests", requests)
+	}
+
+	if order, _ := client.GetOrderManager().GetOrder("BTCUSDT", 99); order.Status != "FILLED" {
+		t.Errorf("expected tracked order to be FILLED, got %s", order.Status)
+	}
+}
+
+func TestCancelOrderRecordsFillOnError(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method == "order.cancel" {
+			return 400, `{"code":-2011,"msg":"Unknown order sent."}`
+		}
+
+		return 200, `{"symbol":"BTCUSDT","orderId":99,"side":"BUY","status":"FILLED","executedQty":"1","cummulativeQuoteQty":"100"}`
+	})
+
+	client.GetOrderManager().TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 99, Side: "BUY", Status: "NEW"})
+
+	if _, err := client.CancelOrder("BTCUSDT", 99); err == nil {
+		t.Fatal("expected CancelOrder() to return the exchange error")
+	}
+
+	if active := client.GetOrderManager().GetActiveOrdersBySymbol("BTCUSDT"); len(active) != 0 {
+		t.Errorf("expected the filled order to be closed, got %d active orders", len(active))
+	}
+
+	if base := client.GetOrderManager().Position("BTCUSDT").Base; base.String() != "1" {
+		t.Errorf("expected the fill to count toward the position, got base %s", base)
+	}
+}
+
+func TestGetAccountBalance(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		return 200, `{"canTrade":true,"balances":[{"asset":"BTC","free":"1.5","locked":"0.5"},{"asset":"USDT","free":"100","locked":"0"}]}`
+	})
+
+	balances, err := client.GetTradingPairBalance("BTC", "USDT")
+	if err != nil {
+		t.Fatalf("GetTradingPairBalance() returned error: %v", err)
+	}
+
+	if balances["BTC"].String() != "2" || balances["USDT"].String() != "100" {
+		t.Errorf("unexpected balances: %v", balances)
+	}
+}
+
+func TestGetMaxOrderSizeIsExact(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		return 200, `{"balances":[{"asset":"BTC","free":"0.00199999","locked":"0"},{"asset":"USDT","free":"0.3","locked":"0"}]}`
+	})
+
+	step := decimal.RequireFromString("0.00001")
+
+	// On floats 0.3 / 0.1 is 2.9999999999999996, one step short of the balance
+	buy, err := client.GetMaxOrderSize("BTC", "USDT", "BUY", decimal.RequireFromString("0.1"), step)
+	if err != nil {
+		t.Fatalf("GetMaxOrderSize() returned error: %v", err)
+	}
+
+	if buy.String() != "3" {
+		t.Errorf("expected max buy size 3, got %s", buy)
+	}
+
+	sell, err := client.GetMaxOrderSize("BTC", "USDT", "SELL", decimal.Zero, step)
+	if err != nil {
+		t.Fatalf("GetMaxOrderSize() returned error: %v", err)
+	}
+
+	if sell.String() != "0.00199" {
+		t.Errorf("expected max sell size rounded down to 0.00199, got %s", sell)
+	}
+}
+
+func TestRequestsCarryTheirSymbol(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		return 200, fmt.Sprintf(`{"symbol":"%s","orderId":1,"status":"NEW"}`, params["symbol"])
+	})
+
+	// One client serves every symbol; order IDs are tracked per symbol
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT"} {
+		order, err := client.PlaceOrder(symbol, "BUY", "LIMIT", "1.00", "1")
+		if err != nil {
+			t.Fatalf("PlaceOrder(%s) returned error: %v", symbol, err)
+		}
+
+		if order.Symbol != symbol {
+			t.Errorf("expected %s order, got %s", symbol, order.Symbol)
+		}
+	}
+
+	if got := len(client.GetOrderManager().GetAllOrders()); got != 2 {
+		t.Errorf("expected 2 tracked orders, got %d", got)
+	}
+}
+
+func TestAccountQueriesAreSigned(t *testing.T) {
+	methods := map[string]map[string]string{}
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		methods[method] = params
+
+		switch method {
+		case "myTrades":
+			return 200, `[{"symbol":"BTCUSDT","id":7,"orderId":3,"price":"40000.00","qty":"0.001","isBuyer":true}]`
+		default:
+			return 200, `[{"symbol":"BTCUSDT","orderId":3,"status":"NEW"}]`
+		}
+	})
+
+	open, err := client.GetOpenOrders("")
+	if err != nil || len(open) != 1 {
+		t.Fatalf("GetOpenOrders() = %v, %v", open, err)
+	}
+
+	if _, err := client.GetAllOrders("BTCUSDT", 20); err != nil {
+		t.Fatalf("GetAllOrders() returned error: %v", err)
+	}
+
+	trades, err := client.GetMyTrades("BTCUSDT", 0)
+	if err != nil || len(trades) != 1 || trades[0].ID != 7 || !trades[0].IsBuyer {
+		t.Fatalf("GetMyTrades() = %+v, %v", trades, err)
+	}
+
+	for method, params := range methods {
+		if params["signature"] == "" || params["apiKey"] != "apiKey" || params["timestamp"] == "" {
+			t.Errorf("%s request is not signed: %v", method, params)
+		}
+	}
+
+	if _, exists := methods["openOrders.status"]["symbol"]; exists {
+		t.Error("expected open orders for every symbol to omit the symbol")
+	}
+
+	if methods["allOrders"]["limit"] != "20" {
+		t.Errorf("expected allOrders limit 20, got %q", methods["allOrders"]["limit"])
+	}
+
+	if _, exists := methods["myTrades"]["limit"]; exists {
+		t.Error("expected a zero limit to be omitted")
+	}
+}
+
+func TestCancelAllOrdersSkipsOrderLists(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method != "openOrders.cancelAll" || params["symbol"] != "BTCUSDT" {
+			t.Errorf("unexpected request: %s %v", method, params)
+		}
+
+		return 200, `[{"symbol":"BTCUSDT","orderId":1,"status":"CANCELED"},{"orderListId":5,"listOrderStatus":"ALL_DONE","orders":[]}]`
+	})
+
+	orders, err := client.CancelAllOrders("BTCUSDT")
+	if err != nil {
+		t.Fatalf("CancelAllOrders() returned error: %v", err)
+	}
+
+	if len(orders) != 1 || orders[0].OrderID != 1 || orders[0].Status != "CANCELED" {
+		t.Errorf("unexpected canceled orders: %+v", orders)
+	}
+}
+
+func TestGetExchangeInfo(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if params["symbols"] != "[BTCUSDT]" {
+			t.Errorf("expected symbols filter, got %v", params)
+		}
+
+		return 200, `{"timezone":"UTC","symbols":[{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT",
+			"filters":[{"filterType":"PRICE_FILTER","tickSize":"0.01000000"},{"filterType":"LOT_SIZE","stepSize":"0.00001000"}]}]}`
+	})
+
+	info, err := client.GetExchangeInfo("BTCUSDT")
+	if err != nil {
+		t.Fatalf("GetExchangeInfo() returned error: %v", err)
+	}
+
+	if len(info.Symbols) != 1 {
+		t.Fatalf("expected 1 symbol, got %d", len(info.Symbols))
+	}
+
+	if filter, ok := info.Symbols[0].Filter("PRICE_FILTER"); !ok || filter.TickSize != "0.01000000" {
+		t.Errorf("unexpected price filter: %+v", filter)
+	}
+
+	if _, ok := info.Symbols[0].Filter("NOTIONAL"); ok {
+		t.Error("expected no NOTIONAL filter")
+	}
+}
+
+func TestGetKlines(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method != "uiKlines" || params["symbol"] != "BTCUSDT" || params["interval"] != "1m" || params["limit"] != "2" {
+			t.Errorf("unexpected request: %s %v", method, params)
+		}
+		if _, ok := params["startTime"]; ok {
+			t.Errorf("expected no startTime without a bound, got %v", params)
+		}
+
+		return 200, `[
+			[1499040000000,"0.01634790","0.80000000","0.01575800","0.01577100","148976.11427815",1499644799999,"2434.19055334",308,"1756.87402397","28.46694368","0"],
+			[4102444800000,"0.00001234","0.00001300","0.00001200","0.00001250","5312345678901234.00000000",4102444859999,"65554345678.12345678",3,"2656172839450617.00000000","32777172839.06172839","0"]
+		]`
+	})
+
+	candles, err := client.GetUIKlines("BTCUSDT", "1m", models.KlineQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetUIKlines() returned error: %v", err)
+	}
+
+	if len(candles) != 2 {
+		t.Fatalf("expected 2 candles, got %d", len(candles))
+	}
+
+	first := candles[0]
+	if first.Symbol != "BTCUSDT" || first.OpenTime.UnixMilli() != 1499040000000 || first.High.String() != "0.8" ||
+		first.Trades != 308 || first.TakerBuyQuoteVolume.String() != "28.46694368" || !first.Closed {
+		t.Errorf("unexpected candle: %+v", first)
+	}
+
+	// Meme coin volumes are far beyond the int64 range of 8 decimal place units
+	second := candles[1]
+	if second.Volume.String() != "5312345678901234" || second.QuoteVolume.String() != "65554345678.12345678" {
+		t.Errorf("unexpected volumes: %s %s", second.Volume, second.QuoteVolume)
+	}
+
+	if second.Closed {
+		t.Error("expected a candle closing in the future to be forming")
+	}
+}
+
+func TestGetRecentTrades(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method != "trades.recent" || params["symbol"] != "BTCUSDT" || params["limit"] != "2" {
+			t.Errorf("unexpected request: %s %v", method, params)
+		}
+
+		return 200, `[{"id":1,"price":"100.5","qty":"0.2","quoteQty":"20.1","time":1700000000000,"isBuyerMaker":true,"isBestMatch":true},
+			{"id":2,"price":"100.6","qty":"0.1","quoteQty":"10.06","time":1700000000001,"isBuyerMaker":false,"isBestMatch":true}]`
+	})
+
+	trades, err := client.GetRecentTrades("BTCUSDT", 2)
+	if err != nil {
+		t.Fatalf("GetRecentTrades() returned error: %v", err)
+	}
+
+	if len(trades) != 2 || trades[0].Qty != "0.2" || !trades[0].IsBuyerMaker || trades[1].IsBuyerMaker {
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+}
+
+// Log output shared with the client's reader and dispatcher goroutines
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestSignedRequestsDoNotLogCredentials(t *testing.T) {
+	var buf lockedBuffer
+	logging.SetOutput(&buf)
+
+	// Registered before the client so it runs after the client closes
+	previous := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		log.SetOutput(os.Stderr)
+		logging.SetOutput(os.Stderr)
+	})
+
+	slog.SetDefault(logging.New(logging.Options{Level: slog.LevelDebug}))
+
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		return 200, `{}`
+	})
+
+	if err := client.TestSignature(); err != nil {
+		t.Fatalf("TestSignature() returned error: %v", err)
+	}
+
+	logs := buf.String()
+	if !strings.Contains(logs, "account.status") {
+		t.Fatalf("expected the request to be logged, got %q", logs)
+	}
+
+	for _, leaked := range []string{"apiKey=", "signature", "secretKey"} {
+		if strings.Contains(logs, leaked) {
+			t.Errorf("expected no %q in the logs, got %q", leaked, logs)
+		}
 	}
 }
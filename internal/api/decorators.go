@@ -0,0 +1,270 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Log every order entry and query with its duration and outcome
+type LoggingExchange struct {
+	Exchange
+}
+
+func NewLoggingExchange(next Exchange) *LoggingExchange {
+	return &LoggingExchange{Exchange: next}
+}
+
+func (e *LoggingExchange) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
+	start := time.Now()
+	book, err := e.Exchange.GetOrderbook(symbol, limit)
+	logCall("GetOrderbook", start, err, "symbol", symbol, "limit", limit)
+
+	return book, err
+}
+
+func (e *LoggingExchange) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	start := time.Now()
+	order, err := e.Exchange.PlaceOrder(symbol, side, orderType, price, quantity)
+	logCall("PlaceOrder", start, err, "symbol", symbol, "side", side, "type", orderType, "quantity", quantity, "price", price)
+
+	return order, err
+}
+
+func (e *LoggingExchange) CancelOrder(symbol string, orderID int64) (*models.Order, error) {
+	start := time.Now()
+	order, err := e.Exchange.CancelOrder(symbol, orderID)
+	logCall("CancelOrder", start, err, "symbol", symbol, "orderId", orderID)
+
+	return order, err
+}
+
+func (e *LoggingExchange) GetOrderStatus(symbol string, orderID int64) (*models.Order, error) {
+	start := time.Now()
+	order, err := e.Exchange.GetOrderStatus(symbol, orderID)
+	logCall("GetOrderStatus", start, err, "symbol", symbol, "orderId", orderID)
+
+	return order, err
+}
+
+func logCall(method string, start time.Time, err error, args ...any) {
+	args = append(args, "method", method, "duration", time.Since(start))
+
+	if err != nil {
+		slog.Warn("Exchange call failed", append(args, "error", err)...)
+		return
+	}
+
+	slog.Info("Exchange call completed", args...)
+}
+
+// Call counts and latency for one exchange method
+type CallStats struct {
+	Calls        uint64
+	Errors       uint64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// Record call counts, errors and latency per method
+type MetricsExchange struct {
+	Exchange
+	stats map[string]*CallStats
+	mu    sync.Mutex
+}
+
+func NewMetricsExchange(next Exchange) *MetricsExchange {
+	return &MetricsExchange{
+		Exchange: next,
+		stats:    make(map[string]*CallStats),
+	}
+}
+
+func (e *MetricsExchange) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
+	start := time.Now()
+	book, err := e.Exchange.GetOrderbook(symbol, limit)
+	e.record("GetOrderbook", start, err)
+
+	return book, err
+}
+
+func (e *MetricsExchange) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	start := time.Now()
+	order, err := e.Exchange.PlaceOrder(symbol, side, orderType, price, quantity)
+	e.record("PlaceOrder", start, err)
+
+	return order, err
+}
+
+func (e *MetricsExchange) CancelOrder(symbol string, orderID int64) (*models.Order, error) {
+	start := time.Now()
+	order, err := e.Exchange.CancelOrder(symbol, orderID)
+	e.record("CancelOrder", start, err)
+
+	return order, err
+}
+
+func (e *MetricsExchange) GetOrderStatus(symbol string, orderID int64) (*models.Order, error) {
+	start := time.Now()
+	order, err := e.Exchange.GetOrderStatus(symbol, orderID)
+	e.record("GetOrderStatus", start, err)
+
+	return order, err
+}
+
+// Snapshot of the statistics for every method called so far
+func (e *MetricsExchange) Stats() map[string]CallStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	snapshot := make(map[string]CallStats, len(e.stats))
+	for method, stats := range e.stats {
+		snapshot[method] = *stats
+	}
+
+	return snapshot
+}
+
+func (e *MetricsExchange) record(method string, start time.Time, err error) {
+	latency := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats, exists := e.stats[method]
+	if !exists {
+		stats = &CallStats{}
+		e.stats[method] = stats
+	}
+
+	stats.Calls++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.TotalLatency += latency
+	stats.MaxLatency = max(stats.MaxLatency, latency)
+}
+
+// Returned when an order is blocked by a risk limit
+var ErrRiskRejected = errors.New("order rejected by risk limits")
+
+// Orderbook levels a market order's notional is estimated from
+const riskBookDepth = 100
+
+// Limits enforced before an order reaches the exchange. Zero disables a limit.
+type RiskLimits struct {
+	MaxOrderQuantity decimal.Decimal // Largest quantity for a single order
+	MaxOrderNotional decimal.Decimal // Largest price * quantity for a single order
+	MaxOpenOrders    int             // Most orders allowed to rest at once per symbol
+}
+
+// Reject orders that breach risk limits
+type RiskExchange struct {
+	Exchange
+	limits RiskLimits
+}
+
+func NewRiskExchange(next Exchange, limits RiskLimits) *RiskExchange {
+	return &RiskExchange{Exchange: next, limits: limits}
+}
+
+func (e *RiskExchange) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	if err := e.check(symbol, side, orderType, price, quantity); err != nil {
+		return nil, err
+	}
+
+	return e.Exchange.PlaceOrder(symbol, side, orderType, price, quantity)
+}
+
+func (e *RiskExchange) check(symbol, side, orderType, price, quantity string) error {
+	qty, err := decimal.NewFromString(quantity)
+	if err != nil {
+		return fmt.Errorf("%w: invalid quantity %q", ErrRiskRejected, quantity)
+	}
+
+	if e.limits.MaxOrderQuantity.IsPositive() && qty.GreaterThan(e.limits.MaxOrderQuantity) {
+		return fmt.Errorf("%w: quantity %s exceeds %s", ErrRiskRejected, quantity, e.limits.MaxOrderQuantity)
+	}
+
+	if e.limits.MaxOrderNotional.IsPositive() {
+		notional, err := e.notional(symbol, side, orderType, price, qty)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrRiskRejected, err)
+		}
+
+		if notional.GreaterThan(e.limits.MaxOrderNotional) {
+			return fmt.Errorf("%w: notional %s exceeds %s", ErrRiskRejected, notional, e.limits.MaxOrderNotional)
+		}
+	}
+
+	if e.limits.MaxOpenOrders > 0 {
+		if open := e.openOrders(symbol); open >= e.limits.MaxOpenOrders {
+			return fmt.Errorf("%w: %d open %s orders at limit of %d", ErrRiskRejected, open, symbol, e.limits.MaxOpenOrders)
+		}
+	}
+
+	return nil
+}
+
+// Orders resting on symbol. At the limit, the tracked orders are refreshed
+// from the exchange first so fills nobody reported don't count against it.
+func (e *RiskExchange) openOrders(symbol string) int {
+	active := e.GetOrderManager().GetActiveOrdersBySymbol(symbol)
+	if len(active) < e.limits.MaxOpenOrders {
+		return len(active)
+	}
+
+	for _, order := range active {
+		if _, err := e.Exchange.GetOrderStatus(symbol, order.OrderID); err != nil {
+			slog.Warn("Failed to refresh open order", "symbol", symbol, "orderId", order.OrderID, "error", err)
+		}
+	}
+
+	return len(e.GetOrderManager().GetActiveOrdersBySymbol(symbol))
+}
+
+// Value of an order at its limit price. A market order is valued at the
+// prices it would sweep on the side it trades against, and rejected when the
+// book is too thin to tell.
+func (e *RiskExchange) notional(symbol, side, orderType, price string, qty decimal.Decimal) (decimal.Decimal, error) {
+	if orderType != "MARKET" {
+		px, err := decimal.NewFromString(price)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid price %q", price)
+		}
+
+		return px.Mul(qty), nil
+	}
+
+	book, err := e.Exchange.GetOrderbook(symbol, riskBookDepth)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("no reference price for market order: %v", err)
+	}
+
+	levels := book.Asks
+	if side == "SELL" {
+		levels = book.Bids
+	}
+
+	notional, remaining := decimal.Zero, qty
+	for _, level := range levels {
+		if !remaining.IsPositive() {
+			break
+		}
+
+		fill := decimal.Min(remaining, level.Quantity)
+		notional = notional.Add(fill.Mul(level.Price))
+		remaining = remaining.Sub(fill)
+	}
+
+	if remaining.IsPositive() {
+		return decimal.Zero, fmt.Errorf("orderbook too thin to value a market %s of %s", side, qty)
+	}
+
+	return notional, nil
+}
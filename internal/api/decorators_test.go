@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+)
+
+func TestRiskExchangeRejectsLargeOrders(t *testing.T) {
+	placed := 0
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		placed++
+		return 200, `{"symbol":"BTCUSDT","orderId":1,"status":"NEW"}`
+	})
+
+	exchange := NewRiskExchange(client, RiskLimits{
+		MaxOrderQuantity: decimal.NewFromInt(1),
+		MaxOrderNotional: decimal.NewFromInt(1000),
+		MaxOpenOrders:    1,
+	})
+
+	tests := []struct {
+		name     string
+		price    string
+		quantity string
+	}{
+		{name: "quantity", price: "1", quantity: "2"},
+		{name: "notional", price: "2000", quantity: "0.9"},
+		{name: "invalid quantity", price: "1", quantity: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := exchange.PlaceOrder("BTCUSDT", "BUY", "LIMIT", tt.price, tt.quantity)
+			if !errors.Is(err, ErrRiskRejected) {
+				t.Errorf("expected ErrRiskRejected, got %v", err)
+			}
+		})
+	}
+
+	if placed != 0 {
+		t.Errorf("rejected orders reached the exchange %d times", placed)
+	}
+
+	if _, err := exchange.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "100", "0.5"); err != nil {
+		t.Fatalf("PlaceOrder() within limits returned error: %v", err)
+	}
+
+	// The first order is now resting
+	if _, err := exchange.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "100", "0.5"); !errors.Is(err, ErrRiskRejected) {
+		t.Errorf("expected open order limit to reject, got %v", err)
+	}
+}
+
+func TestRiskExchangeRefreshesOpenOrdersAtLimit(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method == "order.status" {
+			return 200, `{"symbol":"BTCUSDT","orderId":1,"side":"BUY","status":"FILLED","executedQty":"0.5"}`
+		}
+
+		return 200, `{"symbol":"BTCUSDT","orderId":1,"side":"BUY","status":"NEW"}`
+	})
+
+	exchange := NewRiskExchange(client, RiskLimits{MaxOpenOrders: 1})
+
+	if _, err := exchange.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "100", "0.5"); err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+
+	// The first order filled without anyone being told
+	if _, err := exchange.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "100", "0.5"); err != nil {
+		t.Errorf("expected a filled order not to count as open, got %v", err)
+	}
+}
+
+func TestRiskExchangeValuesMarketOrdersAcrossTheBook(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method == "depth" {
+			return 200, `{"lastUpdateId":1,"bids":[["99","1"],["90","1"]],"asks":[["101","1"],["110","1"]]}`
+		}
+
+		return 200, `{"symbol":"BTCUSDT","orderId":1,"status":"FILLED"}`
+	})
+
+	exchange := NewRiskExchange(client, RiskLimits{MaxOrderNotional: decimal.NewFromInt(200)})
+
+	tests := []struct {
+		name     string
+		side     string
+		quantity string
+		wantErr  bool
+	}{
+		{name: "buy at the touch", side: "BUY", quantity: "1"},
+		{name: "buy sweeping the asks", side: "BUY", quantity: "1.95", wantErr: true}, // 101 + 0.95 * 110
+		{name: "sell valued at the bids", side: "SELL", quantity: "2"},                // 99 + 90
+		{name: "sell beyond the book", side: "SELL", quantity: "3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := exchange.PlaceOrder("BTCUSDT", tt.side, "MARKET", "", tt.quantity)
+			if tt.wantErr != errors.Is(err, ErrRiskRejected) {
+				t.Errorf("PlaceOrder() error = %v, want rejected %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetricsExchangeCountsCalls(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		return 400, `{"code":-1121,"msg":"Invalid symbol."}`
+	})
+
+	exchange := NewMetricsExchange(client)
+	exchange.GetOrderbook("BTCUSDT", 5)
+	exchange.GetOrderbook("BTCUSDT", 5)
+
+	stats := exchange.Stats()["GetOrderbook"]
+	if stats.Calls != 2 || stats.Errors != 2 {
+		t.Errorf("expected 2 calls and 2 errors, got %+v", stats)
+	}
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Orderbook levels fetched to fill a simulated order
+const simulatedBookDepth = 100
+
+// Prefix of the client order ID of every simulated order
+const simulatedClientOrderPrefix = "dryrun-"
+
+// Orders or trades returned by a history query without a limit, as on the exchange
+const defaultHistoryLimit = 500
+
+// Simulated order entry for dry runs. Orders are checked by the exchange with
+// order.test, then filled locally against the live orderbook: immediately as
+// a taker for the part that crosses the book, and later as a maker whenever a
+// fetched book trades through the resting price. Our orders never consume
+// liquidity, so a level can fill more than one simulated order.
+type simulator struct {
+	nextID atomic.Int64
+	rates  map[string]models.OrderTestResult // Latest commission rates by symbol
+	trades []models.Trade                    // Simulated fills, oldest first
+	mu     sync.Mutex                        // Held while filling so an order is never filled twice
+}
+
+// Route order entry to order.test and simulate fills locally
+func WithDryRun() Option {
+	return func(o *clientOptions) {
+		o.dryRun = true
+	}
+}
+
+// Whether orders are simulated instead of sent to the exchange
+func (c *BinanceClient) DryRun() bool {
+	return c.sim != nil
+}
+
+// Validate an order with order.test, then fill it against the live orderbook
+func (c *BinanceClient) placeSimulated(params map[string]string) (*models.Order, error) {
+	symbol, side, orderType := params["symbol"], params["side"], params["type"]
+
+	params["computeCommissionRates"] = "true"
+
+	signed, err := c.sign(params)
+	if err != nil {
+		return nil, err
+	}
+
+	wsResponse, err := c.call(ClassTrading, "order.test", signed)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.OrderTestResult
+	if err := decodeResult(wsResponse, &result); err != nil {
+		return nil, err
+	}
+
+	quantity, err := decimal.NewFromString(params["quantity"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity %q: %w", params["quantity"], err)
+	}
+
+	limit := decimal.Zero
+	if orderType == "LIMIT" {
+		if limit, err = decimal.NewFromString(params["price"]); err != nil {
+			return nil, fmt.Errorf("invalid price %q: %w", params["price"], err)
+		}
+	}
+
+	book, err := c.GetOrderbook(symbol, simulatedBookDepth)
+	if err != nil {
+		return nil, fmt.Errorf("no orderbook to fill the simulated order: %w", err)
+	}
+
+	now := time.Now().UnixMilli()
+	order := &models.Order{
+		Symbol:        symbol,
+		OrderID:       c.sim.nextID.Add(1),
+		OrderListID:   -1,
+		ClientOrderID: simulatedClientOrderPrefix + uuid.New().String(),
+		TransactTime:  now,
+		Price:         "0",
+		OrigQty:       params["quantity"],
+		TimeInForce:   params["timeInForce"],
+		Type:          orderType,
+		Side:          side,
+		WorkingTime:   now,
+		Simulated:     true,
+	}
+	if orderType == "LIMIT" {
+		order.Price = params["price"]
+	}
+
+	c.sim.mu.Lock()
+	defer c.sim.mu.Unlock()
+
+	c.sim.rates[symbol] = result
+
+	// Take every level priced at or through the limit; market orders take any level
+	executed, quote := decimal.Zero, decimal.Zero
+	for _, level := range opposite(book, side) {
+		if executed.Equal(quantity) || (orderType == "LIMIT" && !crosses(side, level.Price, limit)) {
+			break
+		}
+
+		fill := decimal.Min(level.Quantity, quantity.Sub(executed))
+		executed = executed.Add(fill)
+		quote = quote.Add(fill.Mul(level.Price))
+		c.sim.recordTrade(order, level.Price, fill, false)
+	}
+
+	order.ExecutedQty = executed.String()
+	order.CummulativeQuoteQty = quote.String()
+
+	switch {
+	case executed.Equal(quantity):
+		order.Status = string(models.OrderStatusFilled)
+	case orderType != "LIMIT":
+		// Market orders expire once the visible book runs out
+		order.Status = string(models.OrderStatusExpired)
+	case executed.IsPositive():
+		order.Status = string(models.OrderStatusPartiallyFilled)
+	default:
+		order.Status = string(models.OrderStatusNew)
+	}
+
+	slog.Info("Simulated order placed", "symbol", symbol, "orderId", order.OrderID, "side", side, "type", orderType,
+		"quantity", order.OrigQty, "price", order.Price, "status", order.Status, "executedQty", order.ExecutedQty,
+		"commission", commission(quote, result.StandardCommissionForOrder.Taker, result.TaxCommissionForOrder.Taker))
+
+	c.orderManager.TrackOrder(order)
+
+	tracked := *order
+	return &tracked, nil
+}
+
+// Fill resting simulated orders that a fresh orderbook trades through, at their limit price
+func (c *BinanceClient) matchSimulated(book *models.ParsedOrderBook) {
+	c.sim.mu.Lock()
+	defer c.sim.mu.Unlock()
+
+	rates := c.sim.rates[book.Symbol]
+
+	for _, order := range c.orderManager.GetActiveOrdersBySymbol(book.Symbol) {
+		if !order.Simulated || order.Type != "LIMIT" {
+			continue
+		}
+
+		limit, err1 := decimal.NewFromString(order.Price)
+		quantity, err2 := decimal.NewFromString(order.OrigQty)
+		executed, err3 := decimal.NewFromString(order.ExecutedQty)
+		quote, err4 := decimal.NewFromString(order.CummulativeQuoteQty)
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			slog.Warn("Cannot simulate fills for order", "symbol", order.Symbol, "orderId", order.OrderID, "error", err)
+			continue
+		}
+
+		available := decimal.Zero
+		for _, level := range opposite(book, order.Side) {
+			if !crosses(order.Side, level.Price, limit) {
+				break
+			}
+			available = available.Add(level.Quantity)
+		}
+
+		fill := decimal.Min(available, quantity.Sub(executed))
+		if !fill.IsPositive() {
+			continue
+		}
+
+		executed = executed.Add(fill)
+		quote = quote.Add(fill.Mul(limit))
+		c.sim.recordTrade(&order, limit, fill, true)
+
+		order.ExecutedQty = executed.String()
+		order.CummulativeQuoteQty = quote.String()
+		order.Status = string(models.OrderStatusPartiallyFilled)
+		if executed.Equal(quantity) {
+			order.Status = string(models.OrderStatusFilled)
+		}
+
+		slog.Info("Simulated fill", "symbol", order.Symbol, "orderId", order.OrderID, "side", order.Side,
+			"price", order.Price, "quantity", fill, "status", order.Status,
+			"commission", commission(fill.Mul(limit), rates.StandardCommissionForOrder.Maker, rates.TaxCommissionForOrder.Maker))
+
+		c.orderManager.UpdateOrder(&order)
+	}
+}
+
+// Cancel a simulated order locally
+func (c *BinanceClient) cancelSimulated(symbol string, orderID int64) (*models.Order, error) {
+	c.sim.mu.Lock()
+	defer c.sim.mu.Unlock()
+
+	tracked, err := c.orderManager.GetOrder(symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := *tracked
+	if !isOpen(order.Status) {
+		return nil, fmt.Errorf("order %d is already %s", orderID, order.Status)
+	}
+
+	order.Status = string(models.OrderStatusCanceled)
+	c.orderManager.UpdateOrder(&order)
+
+	slog.Info("Simulated cancel", "symbol", symbol, "orderId", orderID)
+
+	return &order, nil
+}
+
+// Simulated orders still open on a symbol, or on every symbol when symbol is empty
+func (c *BinanceClient) openSimulated(symbol string) []models.Order {
+	var orders []models.Order
+	for _, order := range c.orderManager.GetActiveOrders() {
+		if order.Simulated && (symbol == "" || order.Symbol == symbol) {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders
+}
+
+// Keep a fill of a simulated order for myTrades, called with mu held.
+// Commission is in the quote asset, which the simulator does not name.
+func (s *simulator) recordTrade(order *models.Order, price, quantity decimal.Decimal, maker bool) {
+	rates := s.rates[order.Symbol]
+	fee := commission(quantity.Mul(price), rates.StandardCommissionForOrder.Taker, rates.TaxCommissionForOrder.Taker)
+	if maker {
+		fee = commission(quantity.Mul(price), rates.StandardCommissionForOrder.Maker, rates.TaxCommissionForOrder.Maker)
+	}
+
+	s.trades = append(s.trades, models.Trade{
+		Symbol:      order.Symbol,
+		ID:          int64(len(s.trades) + 1),
+		OrderID:     order.OrderID,
+		OrderListID: -1,
+		Price:       price.String(),
+		Qty:         quantity.String(),
+		QuoteQty:    quantity.Mul(price).String(),
+		Commission:  fee.String(),
+		Time:        time.Now().UnixMilli(),
+		IsBuyer:     order.Side == "BUY",
+		IsMaker:     maker,
+		IsBestMatch: true,
+	})
+}
+
+// The latest simulated orders on a symbol, oldest first
+func (c *BinanceClient) allSimulated(symbol string, limit int) []models.Order {
+	var orders []models.Order
+	for _, order := range c.orderManager.GetOrdersBySymbol(symbol) {
+		if order.Simulated {
+			orders = append(orders, order)
+		}
+	}
+
+	slices.SortFunc(orders, func(a, b models.Order) int { return cmp.Compare(a.OrderID, b.OrderID) })
+	return latest(orders, limit)
+}
+
+// The latest simulated fills on a symbol, oldest first
+func (c *BinanceClient) tradesSimulated(symbol string, limit int) []models.Trade {
+	c.sim.mu.Lock()
+	defer c.sim.mu.Unlock()
+
+	var trades []models.Trade
+	for _, trade := range c.sim.trades {
+		if trade.Symbol == symbol {
+			trades = append(trades, trade)
+		}
+	}
+
+	return latest(trades, limit)
+}
+
+// Last limit items, or the exchange's default number when limit is not positive
+func latest[T any](items []T, limit int) []T {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	return items[max(len(items)-limit, 0):]
+}
+
+// Commission in the quote asset for a traded amount at the summed rates
+func commission(quote decimal.Decimal, rates ...string) decimal.Decimal {
+	total := decimal.Zero
+	for _, rate := range rates {
+		if r, err := decimal.NewFromString(rate); err == nil {
+			total = total.Add(quote.Mul(r))
+		}
+	}
+
+	return total
+}
+
+// Side of the book an order trades against
+func opposite(book *models.ParsedOrderBook, side string) []models.PriceLevel {
+	if side == "BUY" {
+		return book.Asks
+	}
+
+	return book.Bids
+}
+
+// Whether a level on the opposite side is priced at or through the limit
+func crosses(side string, price, limit decimal.Decimal) bool {
+	if side == "BUY" {
+		return !price.GreaterThan(limit)
+	}
+
+	return !price.LessThan(limit)
+}
+
+func isOpen(status string) bool {
+	return status == string(models.OrderStatusNew) || status == string(models.OrderStatusPartiallyFilled)
+}
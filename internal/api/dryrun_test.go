@@ -0,0 +1,180 @@
+package api
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+// Exchange that validates test orders and serves a book whose best ask can move
+type dryRunExchange struct {
+	t      *testing.T
+	mu     sync.Mutex
+	asks   string
+	reject string // Error body for order.test when set
+}
+
+func (e *dryRunExchange) respond(method string, params map[string]string) (int, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch method {
+	case "depth":
+		return 200, `{"lastUpdateId":1,"bids":[["99.00","5"]],"asks":` + e.asks + `}`
+
+	case "order.test":
+		signature := params["signature"]
+		delete(params, "signature")
+
+		if want, _ := utils.GenerateSignature(utils.HMACSigner("secretKey"), params); signature != want || params["computeCommissionRates"] != "true" {
+			return 400, `{"code":-1022,"msg":"Signature for this request is not valid."}`
+		}
+
+		if e.reject != "" {
+			return 400, e.reject
+		}
+
+		return 200, `{"standardCommissionForOrder":{"maker":"0.001","taker":"0.002"},"taxCommissionForOrder":{"maker":"0","taker":"0"},` +
+			`"discount":{"enabledForAccount":false,"enabledForSymbol":false,"discountAsset":"BNB","discount":"0"}}`
+
+	default:
+		e.t.Errorf("dry run sent %s to the exchange", method)
+		return 400, `{"code":-1100,"msg":"unexpected request"}`
+	}
+}
+
+func TestDryRunMarketOrderWalksTheBook(t *testing.T) {
+	exchange := &dryRunExchange{t: t, asks: `[["100.00","1"],["101.00","2"]]`}
+	client := newScriptedClient(t, exchange.respond, WithDryRun())
+
+	order, err := client.PlaceOrder("BTCUSDT", "BUY", "MARKET", "", "2")
+	if err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+
+	if !order.Simulated || !strings.HasPrefix(order.ClientOrderID, simulatedClientOrderPrefix) {
+		t.Errorf("order is not marked as simulated: %+v", order)
+	}
+
+	if order.Status != "FILLED" || order.ExecutedQty != "2" || order.CummulativeQuoteQty != "201" {
+		t.Errorf("expected 2 filled for 201, got %s %s for %s", order.Status, order.ExecutedQty, order.CummulativeQuoteQty)
+	}
+
+	if p := client.GetOrderManager().Position("BTCUSDT"); p.Base.String() != "2" || p.Quote.String() != "-201" {
+		t.Errorf("simulated fill missing from the position: %+v", p)
+	}
+}
+
+func TestDryRunReturnsExchangeRejections(t *testing.T) {
+	exchange := &dryRunExchange{t: t, asks: `[["100.00","1"]]`, reject: `{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`}
+	client := newScriptedClient(t, exchange.respond, WithDryRun())
+
+	_, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "99.00", "0.0000001")
+	if err == nil || !strings.Contains(err.Error(), "LOT_SIZE") {
+		t.Errorf("expected the filter failure, got %v", err)
+	}
+
+	if orders := client.GetOrderManager().GetAllOrders(); len(orders) != 0 {
+		t.Errorf("rejected order should not be tracked, got %d orders", len(orders))
+	}
+}
+
+func TestDryRunRestingOrders(t *testing.T) {
+	exchange := &dryRunExchange{t: t, asks: `[["100.00","1"]]`}
+	client := newScriptedClient(t, exchange.respond, WithDryRun())
+
+	bid, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "99.50", "1")
+	if err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+	if bid.Status != "NEW" {
+		t.Fatalf("expected a passive order to rest, got %s", bid.Status)
+	}
+
+	other, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "90.00", "1")
+	if err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+
+	// The ask moves through the resting bid, which fills at its own price
+	exchange.mu.Lock()
+	exchange.asks = `[["99.00","0.4"],["99.50","1"]]`
+	exchange.mu.Unlock()
+
+	if _, err := client.GetOrderbook("BTCUSDT", 5); err != nil {
+		t.Fatalf("GetOrderbook() returned error: %v", err)
+	}
+
+	filled, _ := client.GetOrderStatus("BTCUSDT", bid.OrderID)
+	if filled.Status != "FILLED" || filled.CummulativeQuoteQty != "99.5" {
+		t.Errorf("expected the bid to fill at 99.50, got %s for %s", filled.Status, filled.CummulativeQuoteQty)
+	}
+
+	if _, err := client.CancelOrder("BTCUSDT", bid.OrderID); err == nil {
+		t.Error("canceling a filled order should fail")
+	}
+
+	open, _ := client.GetOpenOrders("BTCUSDT")
+	if len(open) != 1 || open[0].OrderID != other.OrderID {
+		t.Fatalf("expected only the far bid to be open, got %+v", open)
+	}
+
+	canceled, err := client.CancelAllOrders("BTCUSDT")
+	if err != nil || len(canceled) != 1 || canceled[0].Status != "CANCELED" {
+		t.Errorf("expected the far bid to be canceled, got %+v (%v)", canceled, err)
+	}
+
+	if open, _ := client.GetOpenOrders(""); len(open) != 0 {
+		t.Errorf("expected no open orders, got %d", len(open))
+	}
+}
+
+func TestDryRunHistoryComesFromTheSimulator(t *testing.T) {
+	exchange := &dryRunExchange{t: t, asks: `[["100.00","1"],["101.00","2"]]`}
+	client := newScriptedClient(t, exchange.respond, WithDryRun())
+
+	if _, err := client.PlaceOrder("BTCUSDT", "BUY", "MARKET", "", "2"); err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+	bid, err := client.PlaceOrder("BTCUSDT", "BUY", "LIMIT", "99.50", "1")
+	if err != nil {
+		t.Fatalf("PlaceOrder() returned error: %v", err)
+	}
+
+	exchange.mu.Lock()
+	exchange.asks = `[["99.50","1"]]`
+	exchange.mu.Unlock()
+
+	if _, err := client.GetOrderbook("BTCUSDT", 5); err != nil {
+		t.Fatalf("GetOrderbook() returned error: %v", err)
+	}
+
+	// Any request for the real account's history fails the exchange above
+	orders, err := client.GetAllOrders("BTCUSDT", 0)
+	if err != nil || len(orders) != 2 || orders[1].OrderID != bid.OrderID || orders[1].Status != "FILLED" {
+		t.Fatalf("expected both simulated orders, got %+v (%v)", orders, err)
+	}
+
+	trades, err := client.GetMyTrades("BTCUSDT", 0)
+	if err != nil || len(trades) != 3 {
+		t.Fatalf("expected 3 simulated fills, got %+v (%v)", trades, err)
+	}
+
+	// Two taker fills walking the book, then the bid filling as a maker
+	taker, maker := trades[1], trades[2]
+	if taker.Price != "101" || taker.Qty != "1" || taker.IsMaker || taker.Commission != "0.202" {
+		t.Errorf("unexpected taker fill: %+v", taker)
+	}
+	if maker.OrderID != bid.OrderID || maker.Price != "99.5" || !maker.IsMaker || !maker.IsBuyer || maker.Commission != "0.0995" {
+		t.Errorf("unexpected maker fill: %+v", maker)
+	}
+
+	if latest, _ := client.GetMyTrades("BTCUSDT", 1); len(latest) != 1 || latest[0].ID != maker.ID {
+		t.Errorf("expected only the latest fill, got %+v", latest)
+	}
+	if other, _ := client.GetMyTrades("ETHUSDT", 0); len(other) != 0 {
+		t.Errorf("expected no fills on another symbol, got %+v", other)
+	}
+}
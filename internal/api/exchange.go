@@ -0,0 +1,39 @@
+package api
+
+import (
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+)
+
+// Exchange is everything a strategy needs from a venue.
+// BinanceClient is the live implementation; decorators wrap any Exchange
+// to add logging, metrics, risk checks or dry-run behaviour.
+type Exchange interface {
+	// Market data
+	GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error)
+
+	// Order entry
+	PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error)
+	CancelOrder(symbol string, orderID int64) (*models.Order, error)
+
+	// Account queries
+	GetOrderStatus(symbol string, orderID int64) (*models.Order, error)
+	GetAccountBalance() (*models.AccountResponse, error)
+	GetTradingPairBalance(baseAsset string, quoteAsset string) (map[string]decimal.Decimal, error)
+
+	// Order tracking
+	GetOrderManager() *ordermanager.Manager
+}
+
+var _ Exchange = (*BinanceClient)(nil)
+
+// Operations is what an operator's dashboard or control API needs from a
+// venue: orderbooks to show, tracked orders, and a cancel-all per symbol.
+type Operations interface {
+	GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error)
+	GetOrderManager() *ordermanager.Manager
+	CancelAllOrders(symbol string) ([]models.Order, error)
+}
+
+var _ Operations = (*BinanceClient)(nil)
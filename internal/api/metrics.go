@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// WebSocket API requests by method and result: ok, rejected by the exchange, or failed to get an answer
+	requests = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "ws",
+		Name:      "requests_total",
+		Help:      "WebSocket API requests by method and result.",
+	}, []string{"method", "result"})
+
+	requestDuration = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "ws",
+		Name:      "request_duration_seconds",
+		Help:      "Round trip time of answered WebSocket API requests.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"method"})
+
+	timeOffset = metrics.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "time_offset_seconds",
+		Help:      "Exchange server time minus local time at the last time sync.",
+	})
+)
+
+// Record the outcome of a request sent at start
+func observeRequest(method string, start time.Time, answered, rejected bool) {
+	result := "ok"
+	switch {
+	case !answered:
+		result = "failed"
+	case rejected:
+		result = "rejected"
+	}
+
+	requests.WithLabelValues(method, result).Inc()
+	if answered {
+		requestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+var (
+	connectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "ws", "connected"),
+		"Whether a pooled WebSocket API connection is up.",
+		[]string{"class", "index"}, nil)
+
+	requestLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "ws", "request_latency_seconds"),
+		"Moving average of request round trips on a pooled connection.",
+		[]string{"class", "index"}, nil)
+
+	heartbeatLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "ws", "heartbeat_latency_seconds"),
+		"Last ping round trip on a pooled connection.",
+		[]string{"class", "index"}, nil)
+
+	consecutiveFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "ws", "consecutive_failures"),
+		"Requests failed in a row on a pooled connection.",
+		[]string{"class", "index"}, nil)
+)
+
+func (p *ConnectionPool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- connectedDesc
+	ch <- requestLatencyDesc
+	ch <- heartbeatLatencyDesc
+	ch <- consecutiveFailuresDesc
+}
+
+// Report the health of every pooled connection at scrape time
+func (p *ConnectionPool) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range p.Stats() {
+		labels := []string{s.Class.String(), fmt.Sprint(s.Index)}
+
+		connected := 0.0
+		if s.Connected {
+			connected = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(connectedDesc, prometheus.GaugeValue, connected, labels...)
+		ch <- prometheus.MustNewConstMetric(requestLatencyDesc, prometheus.GaugeValue, s.RequestLatency.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(heartbeatLatencyDesc, prometheus.GaugeValue, s.HeartbeatLatency.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(consecutiveFailuresDesc, prometheus.GaugeValue, float64(s.ConsecutiveFailures), labels...)
+	}
+}
+
+var (
+	rateLimitUsageDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "rate_limit", "usage"),
+		"Usage of an exchange rate limit in its current interval, as last reported by the exchange.",
+		[]string{"type", "interval"}, nil)
+
+	rateLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "rate_limit", "limit"),
+		"Maximum usage of an exchange rate limit per interval.",
+		[]string{"type", "interval"}, nil)
+)
+
+func (r *RateLimiter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rateLimitUsageDesc
+	ch <- rateLimitDesc
+}
+
+// Report the latest usage of every exchange rate limit at scrape time
+func (r *RateLimiter) Collect(ch chan<- prometheus.Metric) {
+	for _, limit := range r.Usage() {
+		interval := fmt.Sprintf("%d%s", limit.IntervalNum, limit.Interval)
+
+		ch <- prometheus.MustNewConstMetric(rateLimitUsageDesc, prometheus.GaugeValue, float64(limit.Count), limit.RateLimitType, interval)
+		ch <- prometheus.MustNewConstMetric(rateLimitDesc, prometheus.GaugeValue, float64(limit.Limit), limit.RateLimitType, interval)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if params["symbol"] == "BADPAIR" {
+			return 400, `{"code":-1121,"msg":"Invalid symbol."}`
+		}
+
+		return 200, `{"lastUpdateId":1,"bids":[["1.00","1"]],"asks":[["1.01","1"]]}`
+	})
+
+	ok := testutil.ToFloat64(requests.WithLabelValues("depth", "ok"))
+	rejected := testutil.ToFloat64(requests.WithLabelValues("depth", "rejected"))
+
+	if _, err := client.GetOrderbook("BTCUSDT", 1); err != nil {
+		t.Fatalf("GetOrderbook() returned error: %v", err)
+	}
+	if _, err := client.GetOrderbook("BADPAIR", 1); err == nil {
+		t.Fatal("expected an invalid symbol to fail")
+	}
+
+	if got := testutil.ToFloat64(requests.WithLabelValues("depth", "ok")) - ok; got != 1 {
+		t.Errorf("expected 1 successful depth request, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(requests.WithLabelValues("depth", "rejected")) - rejected; got != 1 {
+		t.Errorf("expected 1 rejected depth request, got %v", got)
+	}
+
+	stats := client.GetConnectionPool().Stats()
+	if !stats[0].Connected {
+		t.Errorf("expected pooled connections to report connected, got %+v", stats[0])
+	}
+}
+
+func TestSyncTime(t *testing.T) {
+	client := newScriptedClient(t, func(method string, params map[string]string) (int, string) {
+		if method != "time" {
+			return 400, `{"code":-1100,"msg":"unexpected request"}`
+		}
+
+		return 200, fmt.Sprintf(`{"serverTime":%d}`, time.Now().Add(2*time.Second).UnixMilli())
+	})
+
+	offset, err := client.SyncTime()
+	if err != nil {
+		t.Fatalf("SyncTime() returned error: %v", err)
+	}
+
+	if offset < 1900*time.Millisecond || offset > 2100*time.Millisecond {
+		t.Errorf("expected an offset of about 2s, got %v", offset)
+	}
+
+	if got := testutil.ToFloat64(timeOffset); got != offset.Seconds() {
+		t.Errorf("expected the time offset gauge to be %v, got %v", offset.Seconds(), got)
+	}
+}
+
+func TestRateLimiterCollector(t *testing.T) {
+	limiter := NewRateLimiter(defaultOrdersPerSecond, defaultOrderBurst)
+	limiter.Update([]models.RateLimit{
+		{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 6000, Count: 42},
+	})
+
+	expected := `
+# HELP binance_rate_limit_limit Maximum usage of an exchange rate limit per interval.
+# TYPE binance_rate_limit_limit gauge
+binance_rate_limit_limit{interval="1MINUTE",type="REQUEST_WEIGHT"} 6000
+# HELP binance_rate_limit_usage Usage of an exchange rate limit in its current interval, as last reported by the exchange.
+# TYPE binance_rate_limit_usage gauge
+binance_rate_limit_usage{interval="1MINUTE",type="REQUEST_WEIGHT"} 42
+`
+
+	if err := testutil.CollectAndCompare(limiter, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
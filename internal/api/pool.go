@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/websocket"
+)
+
+// Class of request used to route traffic to a dedicated set of connections
+type RequestClass int
+
+const (
+	ClassTrading    RequestClass = iota // Latency-sensitive order entry
+	ClassMarketData                     // Orderbook and other public data
+	ClassAccount                        // Account and order status queries
+)
+
+func (rc RequestClass) String() string {
+	switch rc {
+	case ClassTrading:
+		return "trading"
+	case ClassMarketData:
+		return "market-data"
+	case ClassAccount:
+		return "account"
+	default:
+		return fmt.Sprintf("class(%d)", int(rc))
+	}
+}
+
+// Weight applied to each consecutive failure when scoring a connection
+const failurePenalty = time.Second
+
+// Smoothing factor for the request latency moving average
+const latencyAlpha = 0.2
+
+// Number of connections per request class
+type PoolConfig struct {
+	TradingConnections    int  // Connections reserved for order entry
+	MarketDataConnections int  // Connections reserved for market data
+	AccountConnections    int  // Connections reserved for account queries
+	HedgeReads            bool // Send market data and account queries on two connections of their class and take the first answer
+	HedgeOrders           bool // Send limit orders on two trading connections under one client order ID
+}
+
+// One connection per request class, no hedging
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		TradingConnections:    1,
+		MarketDataConnections: 1,
+		AccountConnections:    1,
+	}
+}
+
+// Snapshot of a pooled connection's health
+type ConnectionStats struct {
+	Class               RequestClass
+	Index               int
+	Connected           bool
+	Score               time.Duration // Lower is healthier
+	RequestLatency      time.Duration // Moving average of request round trips
+	HeartbeatLatency    time.Duration // Last ping round trip
+	ConsecutiveFailures int
+	InFlight            int
+}
+
+// Connection in the pool along with its health statistics
+type pooledConn struct {
+	client              *websocket.Client
+	class               RequestClass
+	index               int
+	requestLatency      time.Duration
+	consecutiveFailures int
+	inFlight            int
+}
+
+// Health score, lower is better
+func (pc *pooledConn) score() time.Duration {
+	latency := pc.requestLatency
+	if heartbeat := pc.client.Latency(); heartbeat > latency {
+		latency = heartbeat
+	}
+
+	return latency + time.Duration(pc.consecutiveFailures)*failurePenalty + time.Duration(pc.inFlight)*latency
+}
+
+// Pool of WebSocket connections routed by request class
+type ConnectionPool struct {
+	conns  map[RequestClass][]*pooledConn
+	config PoolConfig
+	mu     sync.Mutex
+}
+
+func NewConnectionPool(wsURL, apiKey, secretKey string, config PoolConfig, opts ...websocket.Option) *ConnectionPool {
+	pool := &ConnectionPool{
+		conns:  make(map[RequestClass][]*pooledConn),
+		config: config,
+	}
+
+	sizes := map[RequestClass]int{
+		ClassTrading:    config.TradingConnections,
+		ClassMarketData: config.MarketDataConnections,
+		ClassAccount:    config.AccountConnections,
+	}
+
+	for class, size := range sizes {
+		// Every class needs at least one connection
+		size = max(size, 1)
+
+		for i := range size {
+			pool.conns[class] = append(pool.conns[class], &pooledConn{
+				client: websocket.New(wsURL, apiKey, secretKey, opts...),
+				class:  class,
+				index:  i,
+			})
+		}
+	}
+
+	return pool
+}
+
+// Connect every connection in the pool
+func (p *ConnectionPool) Connect(ctx context.Context) error {
+	for _, class := range []RequestClass{ClassTrading, ClassMarketData, ClassAccount} {
+		for _, pc := range p.conns[class] {
+			if err := pc.client.Connect(ctx); err != nil {
+				return fmt.Errorf("failed to connect %s connection %d: %w", class, pc.index, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ConnectionPool) Close() {
+	for _, conns := range p.conns {
+		for _, pc := range conns {
+			pc.client.Close()
+		}
+	}
+}
+
+// Whether requests of a class should be hedged across two connections.
+// Trading requests are not idempotent, so orders go through placeHedged.
+func (p *ConnectionPool) Hedging(class RequestClass) bool {
+	return p.config.HedgeReads && class != ClassTrading && len(p.conns[class]) > 1
+}
+
+// Whether limit orders are placed on two trading connections
+func (p *ConnectionPool) HedgingOrders() bool {
+	return p.config.HedgeOrders && len(p.conns[ClassTrading]) > 1
+}
+
+// Pick the n healthiest connections for a request class
+func (p *ConnectionPool) acquire(class RequestClass, n int) []*pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	candidates := p.conns[class]
+	selected := make([]*pooledConn, 0, n)
+	used := make(map[*pooledConn]bool, n)
+
+	for range min(n, len(candidates)) {
+		var best *pooledConn
+		for _, pc := range candidates {
+			if used[pc] {
+				continue
+			}
+			if best == nil || pc.score() < best.score() {
+				best = pc
+			}
+		}
+
+		used[best] = true
+		best.inFlight++
+		selected = append(selected, best)
+	}
+
+	return selected
+}
+
+// Record the outcome of a request on a connection
+func (p *ConnectionPool) release(pc *pooledConn, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc.inFlight--
+
+	if err != nil {
+		pc.consecutiveFailures++
+		return
+	}
+
+	pc.consecutiveFailures = 0
+	if pc.requestLatency == 0 {
+		pc.requestLatency = latency
+	} else {
+		pc.requestLatency = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(pc.requestLatency))
+	}
+}
+
+// Health statistics for every connection in the pool
+func (p *ConnectionPool) Stats() []ConnectionStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var stats []ConnectionStats
+	for _, class := range []RequestClass{ClassTrading, ClassMarketData, ClassAccount} {
+		for _, pc := range p.conns[class] {
+			stats = append(stats, ConnectionStats{
+				Class:               class,
+				Index:               pc.index,
+				Connected:           pc.client.Connected(),
+				Score:               pc.score(),
+				RequestLatency:      pc.requestLatency,
+				HeartbeatLatency:    pc.client.Latency(),
+				ConsecutiveFailures: pc.consecutiveFailures,
+				InFlight:            pc.inFlight,
+			})
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewConnectionPool(t *testing.T) {
+	pool := NewConnectionPool("wss://testnet.binance.vision/ws-api/v3", "apiKey", "secretKey", PoolConfig{
+		TradingConnections: 2,
+	})
+
+	if got := len(pool.conns[ClassTrading]); got != 2 {
+		t.Errorf("expected 2 trading connections, got %d", got)
+	}
+
+	// Classes configured with zero connections still get one
+	if got := len(pool.conns[ClassMarketData]); got != 1 {
+		t.Errorf("expected 1 market data connection, got %d", got)
+	}
+
+	if got := len(pool.conns[ClassAccount]); got != 1 {
+		t.Errorf("expected 1 account connection, got %d", got)
+	}
+
+	if pool.Hedging(ClassAccount) {
+		t.Error("expected hedging to be disabled")
+	}
+
+	// Hedging orders needs a second trading connection
+	single := NewConnectionPool("wss://testnet.binance.vision/ws-api/v3", "apiKey", "secretKey", PoolConfig{HedgeOrders: true})
+	if single.HedgingOrders() {
+		t.Error("expected a single trading connection not to hedge orders")
+	}
+}
+
+func TestConnectionPoolPrefersHealthyConnection(t *testing.T) {
+	pool := NewConnectionPool("wss://testnet.binance.vision/ws-api/v3", "apiKey", "secretKey", PoolConfig{
+		TradingConnections: 2,
+		AccountConnections: 2,
+		HedgeReads:         true,
+	})
+
+	if !pool.Hedging(ClassAccount) {
+		t.Error("expected account queries to be hedged")
+	}
+
+	// Orders are only hedged under a shared client order ID
+	if pool.Hedging(ClassTrading) || pool.HedgingOrders() {
+		t.Error("expected order entry not to be hedged like a read")
+	}
+
+	first := pool.acquire(ClassTrading, 1)[0]
+	pool.release(first, 0, errors.New("timeout"))
+
+	second := pool.acquire(ClassTrading, 1)[0]
+	if second == first {
+		t.Error("expected the failing connection to be avoided")
+	}
+	pool.release(second, 20*time.Millisecond, nil)
+
+	// Hedged reads take both connections regardless of health
+	both := pool.acquire(ClassTrading, 2)
+	if len(both) != 2 || both[0] == both[1] {
+		t.Errorf("expected two distinct connections, got %d", len(both))
+	}
+	for _, pc := range both {
+		pool.release(pc, 10*time.Millisecond, nil)
+	}
+
+	for _, stats := range pool.Stats() {
+		if stats.InFlight != 0 {
+			t.Errorf("expected no in-flight requests on %s connection %d, got %d", stats.Class, stats.Index, stats.InFlight)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Binance spot allows 50 new orders per 10 seconds. A full burst plus 10
+// seconds of refill is 10 + 4 * 10 = 50, so the default never exceeds it.
+const (
+	defaultOrdersPerSecond = 4
+	defaultOrderBurst      = 10
+)
+
+// Order rate budget shared by every strategy using the same client
+type RateLimiter struct {
+	ordersPerSecond float64
+	burst           float64
+	tokens          float64
+	last            time.Time
+	usage           map[string]models.RateLimit // Latest usage reported by the exchange
+	mu              sync.Mutex
+}
+
+func NewRateLimiter(ordersPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		ordersPerSecond: ordersPerSecond,
+		burst:           float64(burst),
+		tokens:          float64(burst),
+		last:            time.Now(),
+		usage:           make(map[string]models.RateLimit),
+	}
+}
+
+// Override the default order rate budget
+func WithOrderRateLimit(ordersPerSecond float64, burst int) Option {
+	return func(o *clientOptions) {
+		o.limiter = NewRateLimiter(ordersPerSecond, burst)
+	}
+}
+
+// Block until the budget allows another order
+func (r *RateLimiter) WaitOrder() {
+	for {
+		r.mu.Lock()
+
+		now := time.Now()
+		r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.ordersPerSecond)
+		r.last = now
+
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return
+		}
+
+		wait := time.Duration((1 - r.tokens) / r.ordersPerSecond * float64(time.Second))
+		r.mu.Unlock()
+
+		time.Sleep(wait)
+	}
+}
+
+// Record the usage reported in a response
+func (r *RateLimiter) Update(limits []models.RateLimit) {
+	if len(limits) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, limit := range limits {
+		key := fmt.Sprintf("%s/%d%s", limit.RateLimitType, limit.IntervalNum, limit.Interval)
+		r.usage[key] = limit
+	}
+}
+
+// Latest usage for every rate limit the exchange has reported
+func (r *RateLimiter) Usage() []models.RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage := make([]models.RateLimit, 0, len(r.usage))
+	for _, limit := range r.usage {
+		usage = append(usage, limit)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].RateLimitType != usage[j].RateLimitType {
+			return usage[i].RateLimitType < usage[j].RateLimitType
+		}
+		return usage[i].Interval+fmt.Sprint(usage[i].IntervalNum) < usage[j].Interval+fmt.Sprint(usage[j].IntervalNum)
+	})
+
+	return usage
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+func TestRateLimiterThrottlesOrders(t *testing.T) {
+	limiter := NewRateLimiter(20, 2)
+
+	start := time.Now()
+	for range 4 {
+		limiter.WaitOrder()
+	}
+
+	// Two orders use the burst, the other two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected orders beyond the burst to be throttled, took %v", elapsed)
+	}
+}
+
+func TestDefaultOrderRateWithinExchangeLimit(t *testing.T) {
+	// Most orders the default budget allows in any 10 second window
+	if most := defaultOrderBurst + defaultOrdersPerSecond*10; most > 50 {
+		t.Errorf("default budget allows %d orders per 10s, above the exchange's 50", most)
+	}
+}
+
+func TestRateLimiterUsage(t *testing.T) {
+	limiter := NewRateLimiter(defaultOrdersPerSecond, defaultOrderBurst)
+
+	limiter.Update([]models.RateLimit{
+		{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 6000, Count: 10},
+		{RateLimitType: "ORDERS", Interval: "SECOND", IntervalNum: 10, Limit: 50, Count: 1},
+	})
+	limiter.Update([]models.RateLimit{
+		{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 6000, Count: 12},
+	})
+
+	usage := limiter.Usage()
+	if len(usage) != 2 {
+		t.Fatalf("expected 2 rate limits, got %d", len(usage))
+	}
+
+	if usage[0].RateLimitType != "ORDERS" || usage[1].Count != 12 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
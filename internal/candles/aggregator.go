@@ -0,0 +1,163 @@
+package candles
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// What closes a bar
+type barKind int
+
+const (
+	timeBars   barKind = iota // A fixed interval elapsed
+	volumeBars                // A fixed base volume traded
+	tickBars                  // A fixed number of trades
+)
+
+// Builds OHLCV bars from trades in arrival order. Not safe for concurrent use.
+type Aggregator struct {
+	symbol   string
+	kind     barKind
+	interval time.Duration
+	volume   decimal.Decimal
+	ticks    int64
+	bar      models.Candle
+	open     bool // A bar has started
+}
+
+// Bars covering fixed intervals aligned to UTC, e.g. 7 minutes or 4 hours.
+// Intervals without trades produce no bar.
+func NewTimeAggregator(symbol string, interval time.Duration) (*Aggregator, error) {
+	if interval < time.Millisecond {
+		return nil, fmt.Errorf("bar interval must be at least 1ms, got %s", interval)
+	}
+
+	return &Aggregator{symbol: symbol, kind: timeBars, interval: interval}, nil
+}
+
+// Bars of exactly volume base asset each. A trade crossing the threshold is
+// split between the bar it completes and the next.
+func NewVolumeAggregator(symbol string, volume decimal.Decimal) (*Aggregator, error) {
+	if !volume.IsPositive() {
+		return nil, fmt.Errorf("bar volume must be positive, got %s", volume)
+	}
+
+	return &Aggregator{symbol: symbol, kind: volumeBars, volume: volume}, nil
+}
+
+// Bars of a fixed number of trades each
+func NewTickAggregator(symbol string, trades int) (*Aggregator, error) {
+	if trades < 1 {
+		return nil, fmt.Errorf("bar trade count must be positive, got %d", trades)
+	}
+
+	return &Aggregator{symbol: symbol, kind: tickBars, ticks: int64(trades)}, nil
+}
+
+// Add a trade, returning the bars it closed
+func (a *Aggregator) Add(trade Trade) []models.Candle {
+	var closed []models.Candle
+
+	switch a.kind {
+	case timeBars:
+		start := trade.Time.Truncate(a.interval)
+		if a.open && start.After(a.bar.OpenTime) {
+			closed = append(closed, a.close())
+		}
+
+		if !a.open {
+			a.start(trade)
+			a.bar.OpenTime = start
+			a.bar.CloseTime = start.Add(a.interval - time.Millisecond)
+		}
+
+		a.fill(trade.Price, trade.Quantity, trade.IsBuyerMaker)
+
+	case volumeBars:
+		remaining := trade.Quantity
+		for remaining.IsPositive() {
+			if !a.open {
+				a.start(trade)
+			}
+
+			take := decimal.Min(remaining, a.volume.Sub(a.bar.Volume))
+			a.fill(trade.Price, take, trade.IsBuyerMaker)
+			a.bar.CloseTime = trade.Time
+			remaining = remaining.Sub(take)
+
+			if !a.bar.Volume.LessThan(a.volume) {
+				closed = append(closed, a.close())
+			}
+		}
+
+	case tickBars:
+		if !a.open {
+			a.start(trade)
+		}
+
+		a.fill(trade.Price, trade.Quantity, trade.IsBuyerMaker)
+		a.bar.CloseTime = trade.Time
+
+		if a.bar.Trades >= a.ticks {
+			closed = append(closed, a.close())
+		}
+	}
+
+	return closed
+}
+
+// Bar still forming, if any
+func (a *Aggregator) Forming() (models.Candle, bool) {
+	return a.bar, a.open
+}
+
+// Close the forming bar early, e.g. on shutdown
+func (a *Aggregator) Flush() (models.Candle, bool) {
+	if !a.open {
+		return models.Candle{}, false
+	}
+
+	return a.close(), true
+}
+
+// Start a bar at the trade
+func (a *Aggregator) start(trade Trade) {
+	a.bar = models.Candle{
+		Symbol:   a.symbol,
+		OpenTime: trade.Time,
+		Open:     trade.Price,
+		High:     trade.Price,
+		Low:      trade.Price,
+	}
+	a.open = true
+}
+
+// Add a trade, or the part of one, to the forming bar
+func (a *Aggregator) fill(price, quantity decimal.Decimal, isBuyerMaker bool) {
+	quote := price.Mul(quantity)
+
+	a.bar.High = decimal.Max(a.bar.High, price)
+	a.bar.Low = decimal.Min(a.bar.Low, price)
+	a.bar.Close = price
+	a.bar.Volume = a.bar.Volume.Add(quantity)
+	a.bar.QuoteVolume = a.bar.QuoteVolume.Add(quote)
+	a.bar.Trades++
+
+	if !isBuyerMaker {
+		a.bar.TakerBuyVolume = a.bar.TakerBuyVolume.Add(quantity)
+		a.bar.TakerBuyQuoteVolume = a.bar.TakerBuyQuoteVolume.Add(quote)
+	}
+}
+
+func (a *Aggregator) close() models.Candle {
+	bar := a.bar
+	bar.Closed = true
+
+	a.bar = models.Candle{}
+	a.open = false
+
+	return bar
+}
@@ -0,0 +1,170 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Single trade fed to an Aggregator
+type Trade struct {
+	Time         time.Time
+	Price        decimal.Decimal
+	Quantity     decimal.Decimal
+	IsBuyerMaker bool // The taker sold
+}
+
+// Trade from the raw trade stream
+func ParseTrade(event models.TradeEvent) (Trade, error) {
+	return parseTrade(event.TradeTime, event.Price, event.Quantity, event.IsBuyerMaker)
+}
+
+// Trade from the aggregated trade stream
+func ParseAggTrade(event models.AggTradeEvent) (Trade, error) {
+	return parseTrade(event.TradeTime, event.Price, event.Quantity, event.IsBuyerMaker)
+}
+
+func parseTrade(unixMilli int64, price, quantity string, isBuyerMaker bool) (Trade, error) {
+	p, err := decimal.NewFromString(price)
+	if err != nil {
+		return Trade{}, fmt.Errorf("invalid trade price %q: %w", price, err)
+	}
+
+	q, err := decimal.NewFromString(quantity)
+	if err != nil {
+		return Trade{}, fmt.Errorf("invalid trade quantity %q: %w", quantity, err)
+	}
+
+	return Trade{Time: time.UnixMilli(unixMilli), Price: p, Quantity: q, IsBuyerMaker: isBuyerMaker}, nil
+}
+
+// Candle from a kline stream event
+func FromKline(k models.Kline) (models.Candle, error) {
+	candle := models.Candle{
+		Symbol:    k.Symbol,
+		OpenTime:  time.UnixMilli(k.StartTime),
+		CloseTime: time.UnixMilli(k.CloseTime),
+		Trades:    k.NumberOfTrades,
+		Closed:    k.IsClosed,
+	}
+
+	fields := []struct {
+		name  string
+		text  string
+		value *decimal.Decimal
+	}{
+		{"open", k.Open, &candle.Open},
+		{"high", k.High, &candle.High},
+		{"low", k.Low, &candle.Low},
+		{"close", k.Close, &candle.Close},
+		{"volume", k.Volume, &candle.Volume},
+		{"quote volume", k.QuoteVolume, &candle.QuoteVolume},
+		{"taker buy volume", k.TakerBuyBaseVolume, &candle.TakerBuyVolume},
+		{"taker buy quote volume", k.TakerBuyQuoteVolume, &candle.TakerBuyQuoteVolume},
+	}
+
+	for _, field := range fields {
+		value, err := decimal.NewFromString(field.text)
+		if err != nil {
+			return models.Candle{}, fmt.Errorf("invalid kline %s %q: %w", field.name, field.text, err)
+		}
+
+		*field.value = value
+	}
+
+	return candle, nil
+}
+
+// Rolling window of closed candles plus the one still forming, seeded from
+// klines and kept current from the kline stream. Safe for concurrent use.
+type History struct {
+	capacity int
+	closed   []models.Candle
+	forming  *models.Candle
+	mu       sync.RWMutex
+}
+
+// Keep up to capacity closed candles, starting from seed in time order
+func NewHistory(capacity int, seed []models.Candle) *History {
+	h := &History{capacity: capacity}
+	for _, candle := range seed {
+		h.Update(candle)
+	}
+
+	return h
+}
+
+// Record a candle, replacing the forming candle it updates.
+// Returns whether the candle closed.
+func (h *History) Update(candle models.Candle) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Updates older than the newest closed candle arrive after a reconnect
+	if n := len(h.closed); n > 0 && !candle.OpenTime.After(h.closed[n-1].OpenTime) {
+		return false
+	}
+
+	if !candle.Closed {
+		h.forming = &candle
+		return false
+	}
+
+	h.forming = nil
+	h.closed = append(h.closed, candle)
+	if h.capacity > 0 && len(h.closed) > h.capacity {
+		h.closed = append(h.closed[:0:0], h.closed[len(h.closed)-h.capacity:]...)
+	}
+
+	return true
+}
+
+// Closed candles, oldest first
+func (h *History) Closed() []models.Candle {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return append([]models.Candle(nil), h.closed...)
+}
+
+// Candle still forming, if any
+func (h *History) Forming() (models.Candle, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.forming == nil {
+		return models.Candle{}, false
+	}
+
+	return *h.forming, true
+}
+
+// Feed kline stream events into history until ctx is done or events is
+// closed, calling onClose (if set) with every candle that closes
+func Consume(ctx context.Context, events <-chan models.KlineEvent, history *History, onClose func(models.Candle)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			candle, err := FromKline(event.Kline)
+			if err != nil {
+				slog.Warn("Skipping invalid kline", "symbol", event.Symbol, "error", err)
+				continue
+			}
+
+			if history.Update(candle) && onClose != nil {
+				onClose(candle)
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package candles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+var base = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func trade(offset time.Duration, price, quantity string, isBuyerMaker bool) Trade {
+	return Trade{
+		Time:         base.Add(offset),
+		Price:        decimal.RequireFromString(price),
+		Quantity:     decimal.RequireFromString(quantity),
+		IsBuyerMaker: isBuyerMaker,
+	}
+}
+
+// Summary of a bar compared in tests
+type ohlcv struct {
+	open, high, low, close, volume string
+	trades                         int64
+}
+
+func summarize(c models.Candle) ohlcv {
+	return ohlcv{c.Open.String(), c.High.String(), c.Low.String(), c.Close.String(), c.Volume.String(), c.Trades}
+}
+
+func TestAggregators(t *testing.T) {
+	trades := []Trade{
+		trade(10*time.Second, "100", "1", false),
+		trade(50*time.Second, "104", "2", true),
+		trade(70*time.Second, "98", "1.5", false),
+		trade(3*time.Minute, "101", "0.5", false),
+		trade(3*time.Minute+time.Second, "99", "2", true),
+	}
+
+	timeBars, _ := NewTimeAggregator("BTCUSDT", time.Minute)
+	volumeBars, _ := NewVolumeAggregator("BTCUSDT", decimal.RequireFromString("2"))
+	tickBars, _ := NewTickAggregator("BTCUSDT", 2)
+
+	tests := []struct {
+		name       string
+		aggregator *Aggregator
+		closed     []ohlcv
+		forming    ohlcv
+	}{
+		{
+			name:       "time",
+			aggregator: timeBars,
+			closed: []ohlcv{
+				{"100", "104", "100", "104", "3", 2},
+				{"98", "98", "98", "98", "1.5", 1},
+			},
+			forming: ohlcv{"101", "101", "99", "99", "2.5", 2},
+		},
+		{
+			name:       "volume",
+			aggregator: volumeBars,
+			closed: []ohlcv{
+				{"100", "104", "100", "104", "2", 2},
+				{"104", "104", "98", "98", "2", 2},
+				{"98", "101", "98", "99", "2", 3},
+			},
+			forming: ohlcv{"99", "99", "99", "99", "1", 1},
+		},
+		{
+			name:       "tick",
+			aggregator: tickBars,
+			closed: []ohlcv{
+				{"100", "104", "100", "104", "3", 2},
+				{"98", "101", "98", "101", "2", 2},
+			},
+			forming: ohlcv{"99", "99", "99", "99", "2", 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closed []models.Candle
+			for _, trade := range trades {
+				closed = append(closed, tt.aggregator.Add(trade)...)
+			}
+
+			if len(closed) != len(tt.closed) {
+				t.Fatalf("expected %d closed bars, got %d", len(tt.closed), len(closed))
+			}
+
+			for i, bar := range closed {
+				if got := summarize(bar); got != tt.closed[i] || !bar.Closed || bar.Symbol != "BTCUSDT" {
+					t.Errorf("bar %d = %+v (closed %v), want %+v", i, got, bar.Closed, tt.closed[i])
+				}
+			}
+
+			forming, ok := tt.aggregator.Forming()
+			if got := summarize(forming); !ok || got != tt.forming || forming.Closed {
+				t.Errorf("forming bar = %+v, want %+v", got, tt.forming)
+			}
+
+			if flushed, ok := tt.aggregator.Flush(); !ok || !flushed.Closed {
+				t.Error("expected Flush to close the forming bar")
+			}
+			if _, ok := tt.aggregator.Forming(); ok {
+				t.Error("expected no forming bar after Flush")
+			}
+		})
+	}
+}
+
+func TestTimeBarsAlignToInterval(t *testing.T) {
+	bars, _ := NewTimeAggregator("BTCUSDT", 5*time.Minute)
+
+	bars.Add(trade(7*time.Minute, "100", "1", false))
+	closed := bars.Add(trade(11*time.Minute, "101", "1", true))
+
+	if len(closed) != 1 {
+		t.Fatalf("expected 1 closed bar, got %d", len(closed))
+	}
+
+	bar := closed[0]
+	if !bar.OpenTime.Equal(base.Add(5*time.Minute)) || !bar.CloseTime.Equal(base.Add(10*time.Minute-time.Millisecond)) {
+		t.Errorf("expected the bar to cover 12:05 to 12:10, got %s to %s", bar.OpenTime, bar.CloseTime)
+	}
+
+	if bar.TakerBuyVolume.String() != "1" || bar.QuoteVolume.String() != "100" {
+		t.Errorf("unexpected taker buy or quote volume: %s %s", bar.TakerBuyVolume, bar.QuoteVolume)
+	}
+}
+
+func TestAggregatorsRejectEmptyBars(t *testing.T) {
+	if _, err := NewTimeAggregator("BTCUSDT", 0); err == nil {
+		t.Error("expected a zero interval to be rejected")
+	}
+	if _, err := NewVolumeAggregator("BTCUSDT", decimal.Zero); err == nil {
+		t.Error("expected a zero volume to be rejected")
+	}
+	if _, err := NewTickAggregator("BTCUSDT", 0); err == nil {
+		t.Error("expected zero trades to be rejected")
+	}
+}
+
+func kline(start int64, close string, closed bool) models.KlineEvent {
+	return models.KlineEvent{Symbol: "BTCUSDT", Kline: models.Kline{
+		StartTime: start, CloseTime: start + 59_999, Symbol: "BTCUSDT", Interval: "1m",
+		Open: "100", High: "110", Low: "90", Close: close, Volume: "1", QuoteVolume: "100",
+		TakerBuyBaseVolume: "0.5", TakerBuyQuoteVolume: "50", NumberOfTrades: 3, IsClosed: closed,
+	}}
+}
+
+func TestConsumeKeepsClosedHistory(t *testing.T) {
+	events := make(chan models.KlineEvent, 8)
+	events <- kline(0, "101", false)
+	events <- kline(0, "102", true)
+	events <- kline(60_000, "103", false)
+	events <- kline(60_000, "104", true)
+	events <- kline(0, "102", true) // Replayed after a reconnect
+	events <- kline(120_000, "105", true)
+	events <- kline(180_000, "106", false)
+	close(events)
+
+	history := NewHistory(2, nil)
+
+	var onClose []string
+	Consume(context.Background(), events, history, func(c models.Candle) {
+		onClose = append(onClose, c.Close.String())
+	})
+
+	if len(onClose) != 3 || onClose[0] != "102" || onClose[2] != "105" {
+		t.Errorf("expected three closes, got %v", onClose)
+	}
+
+	closed := history.Closed()
+	if len(closed) != 2 || closed[0].Close.String() != "104" || closed[1].Close.String() != "105" {
+		t.Errorf("expected the two newest closed candles, got %+v", closed)
+	}
+
+	if forming, ok := history.Forming(); !ok || forming.Close.String() != "106" {
+		t.Errorf("expected the forming candle, got %+v %v", forming, ok)
+	}
+}
+
+func TestFromKlineRejectsInvalidValues(t *testing.T) {
+	event := kline(0, "not-a-number", true)
+	if _, err := FromKline(event.Kline); err == nil {
+		t.Error("expected an invalid close to be rejected")
+	}
+}
@@ -0,0 +1,453 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Returned for missing or malformed command arguments
+var ErrUsage = errors.New("usage")
+
+// Exchange operations available to one-off commands
+type Client interface {
+	GetAccountBalance() (*models.AccountResponse, error)
+	GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error)
+	PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error)
+	CancelOrder(symbol string, orderID int64) (*models.Order, error)
+	GetOrderStatus(symbol string, orderID int64) (*models.Order, error)
+	GetOpenOrders(symbol string) ([]models.Order, error)
+	GetAllOrders(symbol string, limit int) ([]models.Order, error)
+	GetMyTrades(symbol string, limit int) ([]models.Trade, error)
+	CancelAllOrders(symbol string) ([]models.Order, error)
+	GetExchangeInfo(symbols ...string) (*models.ExchangeInfo, error)
+	GetKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error)
+	GetUIKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error)
+}
+
+// Settings shared by every command
+type Options struct {
+	Out    io.Writer
+	Format Format
+	Depth  int // Default orderbook depth
+}
+
+// One-off command run against the exchange
+type Command struct {
+	Name   string
+	Usage  string
+	Signed bool                     // Needs API credentials
+	trades func(args []string) bool // Whether these arguments place or cancel orders, nil for never
+	run    func(s *session, args []string) error
+}
+
+// State for a single command invocation
+type session struct {
+	client  Client
+	printer *Printer
+	options Options
+}
+
+var commands = []*Command{
+	{Name: "balance", Usage: "balance [--all]", Signed: true, run: runBalance},
+	{Name: "book", Usage: "book <symbol> [--depth N]", run: runBook},
+	{Name: "order", Usage: "order place <symbol> <BUY|SELL> <LIMIT|MARKET> <quantity> [price]\n" +
+		"order cancel <symbol> <orderId>\n" +
+		"order status <symbol> <orderId>\n" +
+		"order list <symbol> [--limit N]", Signed: true, trades: orderTrades, run: runOrder},
+	{Name: "orders", Usage: "orders open [symbol]", Signed: true, run: runOrders},
+	{Name: "trades", Usage: "trades <symbol> [--limit N]", Signed: true, run: runTrades},
+	{Name: "cancel-all", Usage: "cancel-all [symbol...]", Signed: true, trades: always, run: runCancelAll},
+	{Name: "exchange-info", Usage: "exchange-info [symbol...]", run: runExchangeInfo},
+	{Name: "klines", Usage: "klines <symbol> <interval> [--limit N] [--ui]", run: runKlines},
+}
+
+// Whether running the command with these arguments can place or cancel orders
+func (c *Command) Trades(args []string) bool {
+	return c.trades != nil && c.trades(args)
+}
+
+func always([]string) bool { return true }
+
+// Only placing and canceling trade; status and list are queries
+func orderTrades(args []string) bool {
+	return len(args) > 0 && (args[0] == "place" || args[0] == "cancel")
+}
+
+// Symbols the command trades with these arguments, for confirming them before
+// it runs. Empty when it trades every symbol with open orders or none.
+func (c *Command) TradedSymbols(args []string) []string {
+	if !c.Trades(args) {
+		return nil
+	}
+
+	switch c.Name {
+	case "order":
+		if len(args) > 1 {
+			return []string{strings.ToUpper(args[1])}
+		}
+	case "cancel-all":
+		var symbols []string
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-") {
+				symbols = append(symbols, strings.ToUpper(arg))
+			}
+		}
+		return symbols
+	}
+
+	return nil
+}
+
+// Find a command by name
+func Lookup(name string) (*Command, bool) {
+	for _, c := range commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// Write the list of commands
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: binance-trader [flags] [command]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  run [mode]                 run manual, market-maker, avellaneda-stoikov or grid until interrupted (default)")
+	fmt.Fprintln(w, "  keystore <path>            encrypt the configured keys into a keystore")
+	fmt.Fprintln(w, "  signer [socket]            sign requests for traders on a Unix socket")
+	fmt.Fprintln(w, "  execute <symbol> <BUY|SELL> <quantity> [--algo twap|vwap|pov] [--duration D] [--interval D]")
+	fmt.Fprintln(w, "                             work a large order in with child orders, see the README for flags")
+	for _, c := range commands {
+		for _, line := range strings.Split(c.Usage, "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run binance-trader --help for flags.")
+}
+
+// Run the command with its arguments
+func (c *Command) Run(client Client, args []string, options Options) error {
+	s := &session{
+		client:  client,
+		printer: NewPrinter(options.Out, options.Format),
+		options: options,
+	}
+
+	return c.run(s, args)
+}
+
+func runBalance(s *session, args []string) error {
+	fs := newFlagSet("balance")
+	all := fs.Bool("all", false, "include zero balances")
+
+	if _, err := ParseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	account, err := s.client.GetAccountBalance()
+	if err != nil {
+		return err
+	}
+
+	var balances []models.Balance
+	for _, b := range account.AccountInfo.Balances {
+		if *all || !b.Free.IsZero() || !b.Locked.IsZero() {
+			balances = append(balances, b)
+		}
+	}
+
+	return s.printer.balances(balances)
+}
+
+func runBook(s *session, args []string) error {
+	fs := newFlagSet("book")
+	depth := fs.Int("depth", s.options.Depth, "levels per side")
+
+	positional, err := ParseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	book, err := s.client.GetOrderbook(strings.ToUpper(positional[0]), *depth)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.book(book)
+}
+
+func runOrder(s *session, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: order needs a subcommand: place, cancel, status or list", ErrUsage)
+	}
+
+	switch args[0] {
+	case "place":
+		return runOrderPlace(s, args[1:])
+	case "cancel", "status":
+		return runOrderLookup(s, args[0], args[1:])
+	case "list":
+		return runOrderList(s, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown order subcommand %q", ErrUsage, args[0])
+	}
+}
+
+func runOrderPlace(s *session, args []string) error {
+	positional, err := ParseArgs(newFlagSet("order place"), args, 4, 5)
+	if err != nil {
+		return err
+	}
+
+	symbol := strings.ToUpper(positional[0])
+	side := strings.ToUpper(positional[1])
+	orderType := strings.ToUpper(positional[2])
+
+	if side != "BUY" && side != "SELL" {
+		return fmt.Errorf("%w: side must be BUY or SELL, got %q", ErrUsage, positional[1])
+	}
+
+	if orderType != "LIMIT" && orderType != "MARKET" {
+		return fmt.Errorf("%w: type must be LIMIT or MARKET, got %q", ErrUsage, positional[2])
+	}
+
+	quantity, err := decimal.NewFromString(positional[3])
+	if err != nil || !quantity.IsPositive() {
+		return fmt.Errorf("%w: quantity must be a positive decimal, got %q", ErrUsage, positional[3])
+	}
+
+	price := ""
+	if orderType == "LIMIT" {
+		if len(positional) < 5 {
+			return fmt.Errorf("%w: LIMIT orders need a price", ErrUsage)
+		}
+
+		parsed, err := decimal.NewFromString(positional[4])
+		if err != nil || !parsed.IsPositive() {
+			return fmt.Errorf("%w: price must be a positive decimal, got %q", ErrUsage, positional[4])
+		}
+
+		price = parsed.String()
+	}
+
+	order, err := s.client.PlaceOrder(symbol, side, orderType, price, quantity.String())
+	if err != nil {
+		return err
+	}
+
+	return s.printer.order(order)
+}
+
+func runOrderLookup(s *session, action string, args []string) error {
+	positional, err := ParseArgs(newFlagSet("order "+action), args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	symbol := strings.ToUpper(positional[0])
+
+	orderID, err := strconv.ParseInt(positional[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid order ID %q", ErrUsage, positional[1])
+	}
+
+	var order *models.Order
+	if action == "cancel" {
+		order, err = s.client.CancelOrder(symbol, orderID)
+	} else {
+		order, err = s.client.GetOrderStatus(symbol, orderID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.printer.order(order)
+}
+
+func runOrderList(s *session, args []string) error {
+	fs := newFlagSet("order list")
+	limit := fs.Int("limit", 0, "most recent orders to show")
+
+	positional, err := ParseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	orders, err := s.client.GetAllOrders(strings.ToUpper(positional[0]), *limit)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.orders(orders)
+}
+
+func runOrders(s *session, args []string) error {
+	if len(args) == 0 || args[0] != "open" {
+		return fmt.Errorf("%w: orders needs a subcommand: open", ErrUsage)
+	}
+
+	positional, err := ParseArgs(newFlagSet("orders open"), args[1:], 0, 1)
+	if err != nil {
+		return err
+	}
+
+	symbol := ""
+	if len(positional) == 1 {
+		symbol = strings.ToUpper(positional[0])
+	}
+
+	orders, err := s.client.GetOpenOrders(symbol)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.orders(orders)
+}
+
+func runTrades(s *session, args []string) error {
+	fs := newFlagSet("trades")
+	limit := fs.Int("limit", 0, "most recent trades to show")
+
+	positional, err := ParseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	trades, err := s.client.GetMyTrades(strings.ToUpper(positional[0]), *limit)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.trades(trades)
+}
+
+// Cancel open orders on the given symbols, or on every symbol that has any
+func runCancelAll(s *session, args []string) error {
+	positional, err := ParseArgs(newFlagSet("cancel-all"), args, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	var symbols []string
+	for _, symbol := range positional {
+		symbols = append(symbols, strings.ToUpper(symbol))
+	}
+
+	if len(symbols) == 0 {
+		open, err := s.client.GetOpenOrders("")
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		for _, order := range open {
+			if !seen[order.Symbol] {
+				seen[order.Symbol] = true
+				symbols = append(symbols, order.Symbol)
+			}
+		}
+	}
+
+	// Keep going so one failing symbol does not leave the rest exposed
+	var canceled []models.Order
+	var errs []error
+	for _, symbol := range symbols {
+		orders, err := s.client.CancelAllOrders(symbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+			continue
+		}
+
+		canceled = append(canceled, orders...)
+	}
+
+	if err := s.printer.orders(canceled); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+func runExchangeInfo(s *session, args []string) error {
+	positional, err := ParseArgs(newFlagSet("exchange-info"), args, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	symbols := make([]string, len(positional))
+	for i, symbol := range positional {
+		symbols[i] = strings.ToUpper(symbol)
+	}
+
+	info, err := s.client.GetExchangeInfo(symbols...)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.exchangeInfo(info)
+}
+
+// Most recent candlesticks, oldest first
+func runKlines(s *session, args []string) error {
+	fs := newFlagSet("klines")
+	limit := fs.Int("limit", 0, "most recent candles to show")
+	ui := fs.Bool("ui", false, "use uiKlines, adjusted for charts")
+
+	positional, err := ParseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	getKlines := s.client.GetKlines
+	if *ui {
+		getKlines = s.client.GetUIKlines
+	}
+
+	candles, err := getKlines(strings.ToUpper(positional[0]), positional[1], models.KlineQuery{Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	return s.printer.candles(candles)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// Parse flags placed anywhere among positional arguments and check the positional count.
+// A negative max allows any number.
+func ParseArgs(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrUsage, fs.Name(), err)
+		}
+
+		if fs.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) < minArgs || (maxArgs >= 0 && len(positional) > maxArgs) {
+		return nil, fmt.Errorf("%w: %s: wrong number of arguments", ErrUsage, fs.Name())
+	}
+
+	return positional, nil
+}
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Client that records calls and answers from fixed data
+type fakeClient struct {
+	calls     []string
+	open      []models.Order
+	cancelErr map[string]error
+}
+
+func (f *fakeClient) record(call string) {
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeClient) GetAccountBalance() (*models.AccountResponse, error) {
+	f.record("balance")
+	return &models.AccountResponse{AccountInfo: models.AccountInfo{Balances: []models.Balance{
+		{Asset: "BTC", Free: decimal.RequireFromString("0.5")},
+		{Asset: "ETH"},
+		{Asset: "USDT", Free: decimal.NewFromInt(100), Locked: decimal.NewFromInt(20)},
+	}}}, nil
+}
+
+func (f *fakeClient) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
+	f.record("book " + symbol)
+	level := func(price string) models.PriceLevel {
+		return models.PriceLevel{Price: decimal.RequireFromString(price), Quantity: decimal.NewFromInt(1)}
+	}
+
+	return &models.ParsedOrderBook{
+		Symbol: symbol,
+		Bids:   []models.PriceLevel{level("99.5"), level("99")},
+		Asks:   []models.PriceLevel{level("100.5"), level("101")},
+	}, nil
+}
+
+func (f *fakeClient) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	f.record(strings.Join([]string{"place", symbol, side, orderType, price, quantity}, " "))
+	return &models.Order{Symbol: symbol, OrderID: 1, Side: side, Type: orderType, Price: price, OrigQty: quantity, Status: "NEW"}, nil
+}
+
+func (f *fakeClient) CancelOrder(symbol string, orderID int64) (*models.Order, error) {
+	f.record("cancel " + symbol)
+	return &models.Order{Symbol: symbol, OrderID: orderID, Status: "CANCELED"}, nil
+}
+
+func (f *fakeClient) GetOrderStatus(symbol string, orderID int64) (*models.Order, error) {
+	f.record("status " + symbol)
+	return &models.Order{Symbol: symbol, OrderID: orderID, Status: "FILLED"}, nil
+}
+
+func (f *fakeClient) GetOpenOrders(symbol string) ([]models.Order, error) {
+	f.record("open " + symbol)
+	return f.open, nil
+}
+
+func (f *fakeClient) GetAllOrders(symbol string, limit int) ([]models.Order, error) {
+	f.record("all " + symbol)
+	return nil, nil
+}
+
+func (f *fakeClient) GetMyTrades(symbol string, limit int) ([]models.Trade, error) {
+	f.record("trades " + symbol)
+	return []models.Trade{{Symbol: symbol, ID: 9, OrderID: 1, Price: "100", Qty: "1", IsBuyer: true}}, nil
+}
+
+func (f *fakeClient) CancelAllOrders(symbol string) ([]models.Order, error) {
+	f.record("cancel-all " + symbol)
+	if err := f.cancelErr[symbol]; err != nil {
+		return nil, err
+	}
+
+	return []models.Order{{Symbol: symbol, OrderID: 1, Status: "CANCELED"}}, nil
+}
+
+func (f *fakeClient) GetExchangeInfo(symbols ...string) (*models.ExchangeInfo, error) {
+	f.record("exchange-info " + strings.Join(symbols, ","))
+	return &models.ExchangeInfo{Symbols: []models.SymbolInfo{{
+		Symbol:  "BTCUSDT",
+		Status:  "TRADING",
+		Filters: []models.SymbolFilter{{FilterType: "PRICE_FILTER", TickSize: "0.01"}},
+	}}}, nil
+}
+
+func (f *fakeClient) GetKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error) {
+	f.record(fmt.Sprintf("klines %s %s %d", symbol, interval, query.Limit))
+	return []models.Candle{{
+		Symbol:   symbol,
+		OpenTime: time.UnixMilli(1700000000000),
+		Open:     decimal.RequireFromString("100"),
+		High:     decimal.RequireFromString("110"),
+		Low:      decimal.RequireFromString("95"),
+		Close:    decimal.RequireFromString("105"),
+		Volume:   decimal.RequireFromString("2.5"),
+		Trades:   12,
+		Closed:   true,
+	}}, nil
+}
+
+func (f *fakeClient) GetUIKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error) {
+	f.record(fmt.Sprintf("uiKlines %s %s %d", symbol, interval, query.Limit))
+	return nil, nil
+}
+
+// Run a command line against a fake client
+func run(t *testing.T, client *fakeClient, format Format, args ...string) (string, error) {
+	t.Helper()
+
+	command, ok := Lookup(args[0])
+	if !ok {
+		t.Fatalf("unknown command %q", args[0])
+	}
+
+	var out bytes.Buffer
+	err := command.Run(client, args[1:], Options{Out: &out, Format: format, Depth: 5})
+
+	return out.String(), err
+}
+
+func TestBalanceHidesEmptyAssets(t *testing.T) {
+	out, err := run(t, &fakeClient{}, FormatTable, "balance")
+	if err != nil {
+		t.Fatalf("balance returned error: %v", err)
+	}
+
+	if !strings.Contains(out, "USDT   100   20") || strings.Contains(out, "ETH") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+
+	out, _ = run(t, &fakeClient{}, FormatTable, "balance", "--all")
+	if !strings.Contains(out, "ETH") {
+		t.Errorf("expected --all to include empty balances:\n%s", out)
+	}
+}
+
+func TestBookJSON(t *testing.T) {
+	out, err := run(t, &fakeClient{}, FormatJSON, "book", "btcusdt", "--depth", "2")
+	if err != nil {
+		t.Fatalf("book returned error: %v", err)
+	}
+
+	var book struct {
+		Symbol string `json:"symbol"`
+		Bids   []struct {
+			Price string `json:"price"`
+		} `json:"bids"`
+	}
+	if err := json.Unmarshal([]byte(out), &book); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+
+	if book.Symbol != "BTCUSDT" || book.Bids[0].Price != "99.5" {
+		t.Errorf("unexpected book: %+v", book)
+	}
+}
+
+func TestOrderPlace(t *testing.T) {
+	client := &fakeClient{}
+
+	if _, err := run(t, client, FormatTable, "order", "place", "btcusdt", "buy", "limit", "0.0010", "40000.00"); err != nil {
+		t.Fatalf("order place returned error: %v", err)
+	}
+
+	if client.calls[0] != "place BTCUSDT BUY LIMIT 40000 0.001" {
+		t.Errorf("unexpected call: %s", client.calls[0])
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"book"},
+		{"order"},
+		{"order", "place", "BTCUSDT", "HOLD", "LIMIT", "1", "1"},
+		{"order", "place", "BTCUSDT", "BUY", "LIMIT", "1"},
+		{"order", "place", "BTCUSDT", "BUY", "MARKET", "-1"},
+		{"order", "status", "BTCUSDT", "abc"},
+		{"orders"},
+		{"trades", "--limit", "x", "BTCUSDT"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			client := &fakeClient{}
+
+			if _, err := run(t, client, FormatTable, args...); !errors.Is(err, ErrUsage) {
+				t.Errorf("expected a usage error, got %v", err)
+			}
+
+			if len(client.calls) != 0 {
+				t.Errorf("expected no exchange calls, got %v", client.calls)
+			}
+		})
+	}
+}
+
+func TestOnlyOrderEntryTrades(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    bool
+		symbols []string
+	}{
+		{[]string{"order", "place", "ethusdt", "BUY", "MARKET", "1"}, true, []string{"ETHUSDT"}},
+		{[]string{"order", "cancel", "BTCUSDT", "1"}, true, []string{"BTCUSDT"}},
+		{[]string{"order", "status", "BTCUSDT", "1"}, false, nil},
+		{[]string{"order", "list", "BTCUSDT"}, false, nil},
+		{[]string{"cancel-all"}, true, nil},
+		{[]string{"cancel-all", "btcusdt", "ETHUSDT"}, true, []string{"BTCUSDT", "ETHUSDT"}},
+		{[]string{"balance"}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			command, _ := Lookup(tt.args[0])
+			if got := command.Trades(tt.args[1:]); got != tt.want {
+				t.Errorf("Trades() = %v, want %v", got, tt.want)
+			}
+			if got := command.TradedSymbols(tt.args[1:]); !slices.Equal(got, tt.symbols) {
+				t.Errorf("TradedSymbols() = %v, want %v", got, tt.symbols)
+			}
+		})
+	}
+}
+
+func TestCancelAllFindsSymbolsWithOpenOrders(t *testing.T) {
+	client := &fakeClient{
+		open: []models.Order{
+			{Symbol: "BTCUSDT", OrderID: 1},
+			{Symbol: "ETHUSDT", OrderID: 2},
+			{Symbol: "BTCUSDT", OrderID: 3},
+		},
+		cancelErr: map[string]error{"BTCUSDT": errors.New("timeout")},
+	}
+
+	out, err := run(t, client, FormatJSON, "cancel-all")
+
+	// A failure on one symbol still cancels the others
+	if err == nil || !strings.Contains(err.Error(), "BTCUSDT: timeout") {
+		t.Errorf("expected the BTCUSDT failure to be reported, got %v", err)
+	}
+
+	want := []string{"open ", "cancel-all BTCUSDT", "cancel-all ETHUSDT"}
+	if strings.Join(client.calls, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected calls: %v", client.calls)
+	}
+
+	var canceled []models.Order
+	if err := json.Unmarshal([]byte(out), &canceled); err != nil || len(canceled) != 1 || canceled[0].Symbol != "ETHUSDT" {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestEmptyListsPrintAsJSONArrays(t *testing.T) {
+	out, err := run(t, &fakeClient{}, FormatJSON, "orders", "open")
+	if err != nil {
+		t.Fatalf("orders open returned error: %v", err)
+	}
+
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty JSON array, got %q", out)
+	}
+}
+
+func TestTradesAndExchangeInfoTables(t *testing.T) {
+	out, err := run(t, &fakeClient{}, FormatTable, "trades", "BTCUSDT")
+	if err != nil || !strings.Contains(out, "TRADE ID") || !strings.Contains(out, "BUY") {
+		t.Errorf("unexpected trades output: %v\n%s", err, out)
+	}
+
+	out, err = run(t, &fakeClient{}, FormatTable, "exchange-info", "btcusdt")
+	if err != nil || !strings.Contains(out, "0.01") || !strings.Contains(out, "-") {
+		t.Errorf("unexpected exchange-info output: %v\n%s", err, out)
+	}
+}
+
+func TestKlines(t *testing.T) {
+	client := &fakeClient{}
+	out, err := run(t, client, FormatTable, "klines", "btcusdt", "1h", "--limit", "3")
+	if err != nil || !strings.Contains(out, "2023-11-14T22:13:20Z") || !strings.Contains(out, "105") {
+		t.Errorf("unexpected klines output: %v\n%s", err, out)
+	}
+
+	if _, err := run(t, client, FormatJSON, "klines", "btcusdt", "1m", "--ui"); err != nil {
+		t.Errorf("klines --ui returned error: %v", err)
+	}
+
+	want := []string{"klines BTCUSDT 1h 3", "uiKlines BTCUSDT 1m 0"}
+	if strings.Join(client.calls, "|") != strings.Join(want, "|") {
+		t.Errorf("expected calls %v, got %v", want, client.calls)
+	}
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Output format for command results
+type Format string
+
+const (
+	FormatTable Format = "table" // Aligned columns for people
+	FormatJSON  Format = "json"  // Indented JSON for scripts
+)
+
+// Writes command results in the selected format
+type Printer struct {
+	out    io.Writer
+	format Format
+}
+
+func NewPrinter(out io.Writer, format Format) *Printer {
+	return &Printer{out: out, format: format}
+}
+
+// Print v as JSON, or call table to write rows of tab separated columns
+func (p *Printer) Print(v any, table func(w io.Writer)) error {
+	if p.format == FormatJSON {
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// Write a tab separated row
+func row(w io.Writer, columns ...any) {
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = fmt.Sprint(column)
+	}
+
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
+
+func (p *Printer) orders(orders []models.Order) error {
+	if orders == nil {
+		orders = []models.Order{}
+	}
+
+	return p.Print(orders, func(w io.Writer) {
+		row(w, "SYMBOL", "ORDER ID", "SIDE", "TYPE", "PRICE", "QUANTITY", "EXECUTED", "STATUS")
+		for _, o := range orders {
+			row(w, o.Symbol, o.OrderID, o.Side, o.Type, o.Price, o.OrigQty, o.ExecutedQty, o.Status)
+		}
+	})
+}
+
+func (p *Printer) order(order *models.Order) error {
+	return p.Print(order, func(w io.Writer) {
+		row(w, "Symbol:", order.Symbol)
+		row(w, "Order ID:", order.OrderID)
+		row(w, "Client order ID:", order.ClientOrderID)
+		row(w, "Side:", order.Side)
+		row(w, "Type:", order.Type)
+		row(w, "Price:", order.Price)
+		row(w, "Quantity:", order.OrigQty)
+		row(w, "Executed:", order.ExecutedQty)
+		row(w, "Status:", order.Status)
+		if order.Simulated {
+			row(w, "Simulated:", "yes, dry run")
+		}
+	})
+}
+
+func (p *Printer) trades(trades []models.Trade) error {
+	if trades == nil {
+		trades = []models.Trade{}
+	}
+
+	return p.Print(trades, func(w io.Writer) {
+		row(w, "SYMBOL", "TRADE ID", "ORDER ID", "SIDE", "PRICE", "QUANTITY", "QUOTE QTY", "COMMISSION", "TIME")
+		for _, t := range trades {
+			side := "SELL"
+			if t.IsBuyer {
+				side = "BUY"
+			}
+
+			commission := t.Commission + " " + t.CommissionAsset
+			row(w, t.Symbol, t.ID, t.OrderID, side, t.Price, t.Qty, t.QuoteQty, commission, formatTime(t.Time))
+		}
+	})
+}
+
+func (p *Printer) balances(balances []models.Balance) error {
+	if balances == nil {
+		balances = []models.Balance{}
+	}
+
+	return p.Print(balances, func(w io.Writer) {
+		row(w, "ASSET", "FREE", "LOCKED")
+		for _, b := range balances {
+			row(w, b.Asset, b.Free, b.Locked)
+		}
+	})
+}
+
+// Asks from highest to lowest above bids from highest to lowest
+func (p *Printer) book(book *models.ParsedOrderBook) error {
+	return p.Print(book, func(w io.Writer) {
+		row(w, "SIDE", "PRICE", "QUANTITY")
+		for i := len(book.Asks) - 1; i >= 0; i-- {
+			row(w, "ASK", book.Asks[i].Price, book.Asks[i].Quantity)
+		}
+		for _, bid := range book.Bids {
+			row(w, "BID", bid.Price, bid.Quantity)
+		}
+	})
+}
+
+func (p *Printer) exchangeInfo(info *models.ExchangeInfo) error {
+	return p.Print(info, func(w io.Writer) {
+		row(w, "SYMBOL", "STATUS", "BASE", "QUOTE", "TICK SIZE", "STEP SIZE", "MIN NOTIONAL")
+		for _, s := range info.Symbols {
+			price, _ := s.Filter("PRICE_FILTER")
+			lot, _ := s.Filter("LOT_SIZE")
+
+			notional, ok := s.Filter("NOTIONAL")
+			if !ok {
+				notional, _ = s.Filter("MIN_NOTIONAL")
+			}
+
+			row(w, s.Symbol, s.Status, s.BaseAsset, s.QuoteAsset, orDash(price.TickSize), orDash(lot.StepSize), orDash(notional.MinNotional))
+		}
+	})
+}
+
+func (p *Printer) candles(candles []models.Candle) error {
+	if candles == nil {
+		candles = []models.Candle{}
+	}
+
+	return p.Print(candles, func(w io.Writer) {
+		row(w, "OPEN TIME", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME", "TRADES", "CLOSED")
+		for _, c := range candles {
+			row(w, formatTime(c.OpenTime.UnixMilli()), c.Open, c.High, c.Low, c.Close, c.Volume, c.Trades, c.Closed)
+		}
+	})
+}
+
+func formatTime(unixMilli int64) string {
+	return time.UnixMilli(unixMilli).UTC().Format(time.RFC3339)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,1002 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/logging"
+	"github.com/iamramtin/binance-trader/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// Operating mode of the application
+type Mode string
+
+const (
+	ModeManual            Mode = "manual"             // Place and cancel individual test market orders
+	ModeMarketMaker       Mode = "market-maker"       // Continuously quote bid/ask orders at a fixed spread
+	ModeAvellanedaStoikov Mode = "avellaneda-stoikov" // Quote around an inventory-skewed reservation price
+	ModeGrid              Mode = "grid"               // Keep buy and sell orders on a price grid
+)
+
+var modes = []Mode{ModeManual, ModeMarketMaker, ModeAvellanedaStoikov, ModeGrid}
+
+// Whether the mode runs a market maker quoting both sides
+func (m Mode) Quotes() bool {
+	return m == ModeMarketMaker || m == ModeAvellanedaStoikov
+}
+
+// Price the market maker centres its quotes on
+type FairValue string
+
+const (
+	FairValueMid        FairValue = "mid"        // Halfway between the best bid and ask
+	FairValueMicroprice FairValue = "microprice" // Top of book weighted by size
+	FairValueDepth      FairValue = "depth"      // Several levels weighted by depth
+	FairValueTradeFlow  FairValue = "trade-flow" // Mid shifted towards recent taker flow
+)
+
+var fairValues = []FairValue{FairValueMid, FairValueMicroprice, FairValueDepth, FairValueTradeFlow}
+
+// Most trades returned by trades.recent
+const maxTradeFlowWindow = 1000
+
+// Most levels a grid may have, keeping within the 200 open orders Binance allows per symbol
+const maxGridLevels = 200
+
+// Largest depth accepted by the depth request
+const maxOrderbookDepth = 5000
+
+// Directory searched for Docker secrets holding the keys
+var secretsDir = "/run/secrets"
+
+// Settings for a trading session.
+// Values are resolved from defaults, then a config file, then environment
+// variables, then command-line flags, with later sources taking precedence.
+type Config struct {
+	Profile          string          // Exchange environment providing endpoint, key and safety defaults
+	Symbols          []string        // Symbols to trade
+	Mode             Mode            // Operating mode, prompted for when empty and interactive
+	Quantity         decimal.Decimal // Quantity of each order
+	SpreadPercentage decimal.Decimal // Spread from mid price for the market maker (e.g., 0.5 for 0.5%)
+	FairValue        FairValue       // Price the market maker quotes around
+	FairValueLevels  int             // Book levels per side read by the depth fair value
+	TradeFlowWindow  int             // Recent trades read by the trade-flow fair value
+	TradeFlowWeight  decimal.Decimal // Trade-flow shift at full one-sided flow, as a fraction of half the spread
+	ASGamma          float64         // Avellaneda-Stoikov risk aversion
+	ASKappa          float64         // Avellaneda-Stoikov order arrival decay, zero to estimate it from trades
+	ASHorizon        time.Duration   // Avellaneda-Stoikov time horizon
+	GridLower        decimal.Decimal // Lowest grid price
+	GridUpper        decimal.Decimal // Highest grid price
+	GridLevels       int             // Prices in the grid, including both bounds
+	GridSpacing      string          // arithmetic or geometric
+	GridStateFile    string          // Grid state kept here across restarts
+	TickSize         string          // Price tick size for symbols exchangeInfo has no rules for
+	OrderbookDepth   int             // Number of levels to request and display
+	WebSocketURL     string          // WebSocket API endpoint
+	StreamURL        string          // Combined market data streams endpoint
+	APIKey           string          // API key, from the config file or environment only
+	SecretKey        string          // Secret key, from the config file or environment only
+	Keystore         string          // Encrypted keystore holding both keys, used instead of APIKey and SecretKey
+	PassphraseFile   string          // File holding the keystore passphrase, prompted for when empty
+	SignerSocket     string          // Unix socket of a signing agent holding the keys, used instead of APIKey and SecretKey
+	RecordFile       string          // Record the wire-level session to this file when set
+	NonInteractive   bool            // Never prompt on stdin
+	Dashboard        bool            // Show the full-screen dashboard instead of log output
+	DryRun           bool            // Validate orders with order.test and simulate fills instead of trading
+	ControlAddr      string          // Serve the HTTP control API on this address when set
+	ControlToken     string          // Bearer token for the control API, from the config file or environment only
+	MetricsAddr      string          // Serve Prometheus metrics on this address when set
+	Output           string          // Command output format: table or json
+	LogLevel         slog.Level      // Least severe level logged
+	LogFormat        string          // Log line format: text or json
+	ShutdownTimeout  time.Duration   // Time allowed on exit to confirm every open order is canceled
+	StateFile        string          // Write the final order state here on exit when set
+	ConfirmMainnet   bool            // Trade on mainnet without asking
+	MaxOrderQuantity decimal.Decimal // Largest quantity of a single order, zero for no limit
+	MaxOrderNotional decimal.Decimal // Largest price * quantity of a single order, zero for no limit
+	MaxOpenOrders    int             // Most orders resting at once per symbol, zero for no limit
+}
+
+// Settings used when nothing overrides them
+func Default() *Config {
+	c := &Config{
+		Symbols:          []string{"BTCTUSD"},
+		Quantity:         decimal.RequireFromString("0.001"),
+		SpreadPercentage: decimal.RequireFromString("0.0001"),
+		FairValue:        FairValueMid,
+		FairValueLevels:  5,
+		TradeFlowWindow:  100,
+		TradeFlowWeight:  decimal.RequireFromString("0.5"),
+		ASGamma:          0.001,
+		ASHorizon:        5 * time.Minute,
+		GridLevels:       10,
+		GridSpacing:      "arithmetic",
+		GridStateFile:    "grid-state.json",
+		TickSize:         "0.01",
+		OrderbookDepth:   5,
+		Output:           "table",
+		LogLevel:         slog.LevelInfo,
+		LogFormat:        logging.FormatText,
+		ShutdownTimeout:  30 * time.Second,
+	}
+
+	testnet, _ := LookupProfile(ProfileTestnet)
+	c.applyProfile(testnet)
+
+	return c
+}
+
+// Single configurable value and the names it goes by in each source
+type setting struct {
+	key    string // Config file key
+	flag   string // Command-line flag, empty if the value must not appear in process arguments
+	env    string // Environment variable
+	usage  string
+	isBool bool
+	apply  func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{key: "profile", flag: "profile", env: "BINANCE_PROFILE", usage: "exchange environment: mainnet, testnet, local-sim or custom", apply: setProfile},
+	{key: "symbols", flag: "symbols", env: "BINANCE_SYMBOLS", usage: "comma separated symbols to trade", apply: setSymbols},
+	{key: "mode", flag: "mode", env: "BINANCE_MODE", usage: "operating mode: manual, market-maker, avellaneda-stoikov or grid", apply: setMode},
+	{key: "quantity", flag: "quantity", env: "BINANCE_QUANTITY", usage: "quantity of each order", apply: setQuantity},
+	{key: "spread_percentage", flag: "spread", env: "BINANCE_SPREAD_PERCENTAGE", usage: "market maker spread from mid price in percent", apply: setSpread},
+	{key: "fair_value", flag: "fair-value", env: "BINANCE_FAIR_VALUE", usage: "market maker fair value: mid, microprice, depth or trade-flow", apply: setFairValue},
+	{key: "fair_value_levels", flag: "fair-value-levels", env: "BINANCE_FAIR_VALUE_LEVELS", usage: "book levels per side for the depth fair value", apply: setFairValueLevels},
+	{key: "trade_flow_window", flag: "trade-flow-window", env: "BINANCE_TRADE_FLOW_WINDOW", usage: "recent trades read by the trade-flow fair value", apply: setTradeFlowWindow},
+	{key: "trade_flow_weight", flag: "trade-flow-weight", env: "BINANCE_TRADE_FLOW_WEIGHT", usage: "trade-flow shift as a fraction of half the spread", apply: setTradeFlowWeight},
+	{key: "as_gamma", flag: "as-gamma", env: "BINANCE_AS_GAMMA", usage: "avellaneda-stoikov risk aversion", apply: setASGamma},
+	{key: "as_kappa", flag: "as-kappa", env: "BINANCE_AS_KAPPA", usage: "avellaneda-stoikov order arrival decay, 0 to estimate it from trades", apply: setASKappa},
+	{key: "as_horizon", flag: "as-horizon", env: "BINANCE_AS_HORIZON", usage: "avellaneda-stoikov time horizon", apply: setASHorizon},
+	{key: "grid_lower", flag: "grid-lower", env: "BINANCE_GRID_LOWER", usage: "lowest grid price", apply: setGridLower},
+	{key: "grid_upper", flag: "grid-upper", env: "BINANCE_GRID_UPPER", usage: "highest grid price", apply: setGridUpper},
+	{key: "grid_levels", flag: "grid-levels", env: "BINANCE_GRID_LEVELS", usage: "prices in the grid, including both bounds", apply: setGridLevels},
+	{key: "grid_spacing", flag: "grid-spacing", env: "BINANCE_GRID_SPACING", usage: "grid spacing: arithmetic or geometric", apply: setGridSpacing},
+	{key: "grid_state_file", flag: "grid-state-file", env: "BINANCE_GRID_STATE_FILE", usage: "file the grid state is kept in across restarts", apply: setGridStateFile},
+	{key: "tick_size", flag: "tick-size", env: "BINANCE_TICK_SIZE", usage: "price tick size when exchangeInfo has none for a symbol", apply: setTickSize},
+	{key: "orderbook_depth", flag: "depth", env: "BINANCE_ORDERBOOK_DEPTH", usage: "orderbook levels to request and display", apply: setDepth},
+	{key: "websocket_url", flag: "ws-url", env: "BINANCE_WS_URL", usage: "WebSocket API endpoint", apply: setWebSocketURL},
+	{key: "stream_url", flag: "stream-url", env: "BINANCE_STREAM_URL", usage: "combined market data streams endpoint", apply: setStreamURL},
+	{key: "record_file", flag: "record", env: "BINANCE_RECORD_FILE", usage: "record the wire-level session to this file", apply: setRecordFile},
+	{key: "non_interactive", flag: "non-interactive", env: "BINANCE_NON_INTERACTIVE", usage: "never prompt on stdin", isBool: true, apply: setNonInteractive},
+	{key: "dashboard", flag: "dashboard", env: "BINANCE_DASHBOARD", usage: "show a full-screen dashboard while running a strategy", isBool: true, apply: setDashboard},
+	{key: "dry_run", flag: "dry-run", env: "BINANCE_DRY_RUN", usage: "validate orders with order.test and simulate fills on live market data", isBool: true, apply: setDryRun},
+	{key: "shutdown_timeout", flag: "shutdown-timeout", env: "BINANCE_SHUTDOWN_TIMEOUT", usage: "time allowed on exit to confirm every open order is canceled", apply: setShutdownTimeout},
+	{key: "state_file", flag: "state-file", env: "BINANCE_STATE_FILE", usage: "write the final order state to this file on exit", apply: setStateFile},
+	{key: "control_addr", flag: "control-addr", env: "BINANCE_CONTROL_ADDR", usage: "serve the control API on this address, e.g. :8081 for localhost", apply: setControlAddr},
+	{key: "metrics_addr", flag: "metrics-addr", env: "BINANCE_METRICS_ADDR", usage: "serve Prometheus metrics on this address, e.g. :9090 for localhost", apply: setMetricsAddr},
+	{key: "confirm_mainnet", flag: "confirm-mainnet", env: "BINANCE_CONFIRM_MAINNET", usage: "trade real funds on mainnet without asking", isBool: true, apply: setConfirmMainnet},
+	{key: "max_order_quantity", flag: "max-order-quantity", env: "BINANCE_MAX_ORDER_QUANTITY", usage: "largest quantity of a single order, 0 for no limit", apply: setMaxOrderQuantity},
+	{key: "max_order_notional", flag: "max-order-notional", env: "BINANCE_MAX_ORDER_NOTIONAL", usage: "largest price * quantity of a single order, 0 for no limit", apply: setMaxOrderNotional},
+	{key: "max_open_orders", flag: "max-open-orders", env: "BINANCE_MAX_OPEN_ORDERS", usage: "most orders resting at once per symbol, 0 for no limit", apply: setMaxOpenOrders},
+	{key: "output", flag: "output", env: "BINANCE_OUTPUT", usage: "command output format: table or json", apply: setOutput},
+	{key: "log_level", flag: "log-level", env: "BINANCE_LOG_LEVEL", usage: "least severe level logged: debug, info, warn or error", apply: setLogLevel},
+	{key: "log_format", flag: "log-format", env: "BINANCE_LOG_FORMAT", usage: "log line format: text or json", apply: setLogFormat},
+	{key: "api_key", env: "BINANCE_API_KEY", apply: setAPIKey},
+	{key: "secret_key", env: "BINANCE_SECRET_KEY", apply: setSecretKey},
+	{key: "api_key_file", flag: "api-key-file", env: "BINANCE_API_KEY_FILE", usage: "read the API key from this file", apply: setAPIKeyFile},
+	{key: "secret_key_file", flag: "secret-key-file", env: "BINANCE_SECRET_KEY_FILE", usage: "read the secret key from this file", apply: setSecretKeyFile},
+	{key: "keystore", flag: "keystore", env: "BINANCE_KEYSTORE", usage: "read both keys from this encrypted keystore", apply: setKeystore},
+	{key: "keystore_passphrase_file", flag: "keystore-passphrase-file", env: "BINANCE_KEYSTORE_PASSPHRASE_FILE", usage: "read the keystore passphrase from this file instead of prompting", apply: setPassphraseFile},
+	{key: "signer_socket", flag: "signer-socket", env: "BINANCE_SIGNER_SOCKET", usage: "sign requests with the signing agent on this Unix socket", apply: setSignerSocket},
+	{key: "control_token", env: "BINANCE_CONTROL_TOKEN", apply: setControlToken},
+}
+
+// Raw flag value, applied only when the flag is given
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (f *flagValue) String() string     { return f.value }
+func (f *flagValue) Set(s string) error { f.value = s; return nil }
+func (f *flagValue) IsBoolFlag() bool   { return f.isBool }
+
+// Resolve the configuration from a config file, the environment and command-line flags.
+// The config file is named by --config or BINANCE_CONFIG and may be YAML or TOML.
+// Arguments after the flags, such as a command, are returned unparsed.
+// Returns flag.ErrHelp when help was requested.
+func Load(args []string, getenv func(string) string, output io.Writer) (*Config, []string, error) {
+	fs := flag.NewFlagSet("binance-trader", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	configPath := fs.String("config", "", "path to a YAML or TOML config file (env BINANCE_CONFIG)")
+
+	values := make(map[string]*flagValue)
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+
+		values[s.flag] = &flagValue{isBool: s.isBool}
+		fs.Var(values[s.flag], s.flag, fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	c := Default()
+
+	path := *configPath
+	if path == "" {
+		path = getenv("BINANCE_CONFIG")
+	}
+
+	var fileValues map[string]any
+	if path != "" {
+		var err error
+		if fileValues, err = readFile(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The profile replaces defaults of other settings, so it is resolved before any of them
+	name := getenv("BINANCE_PROFILE")
+	if value, ok := fileValues["profile"]; ok && name == "" {
+		name, _ = fileValue(value)
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "profile" {
+			name = values["profile"].value
+		}
+	})
+
+	if name != "" {
+		p, ok := LookupProfile(strings.ToLower(name))
+		if !ok {
+			return nil, nil, fmt.Errorf("profile: unknown profile %q, use one of %s", name, profileNames())
+		}
+		c.applyProfile(p)
+	}
+
+	if fileValues != nil {
+		if err := c.applyFile(path, fileValues); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range settings {
+		// Exchange keys come from the variables the profile names, below
+		if s.key == "api_key" || s.key == "secret_key" {
+			continue
+		}
+
+		if value := getenv(s.env); value != "" {
+			if err := s.apply(c, value); err != nil {
+				return nil, nil, fmt.Errorf("environment variable %s: %w", s.env, err)
+			}
+		}
+	}
+
+	apiKeyEnv, secretKeyEnv := c.profile().keyEnv()
+	if value := firstEnv(getenv, apiKeyEnv); value != "" {
+		c.APIKey = value
+	}
+	if value := firstEnv(getenv, secretKeyEnv); value != "" {
+		c.SecretKey = value
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && flagErr == nil {
+				if err := s.apply(c, values[s.flag].value); err != nil {
+					flagErr = fmt.Errorf("flag --%s: %w", s.flag, err)
+				}
+			}
+		}
+	})
+
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+
+	if err := c.loadSecretsDir(apiKeyEnv, secretKeyEnv); err != nil {
+		return nil, nil, err
+	}
+
+	return c, fs.Args(), nil
+}
+
+// Fill missing keys from Docker secrets named like the profile's variables,
+// e.g. /run/secrets/binance_api_key
+func (c *Config) loadSecretsDir(apiKeyEnv, secretKeyEnv []string) error {
+	if c.Keystore != "" || c.SignerSocket != "" {
+		return nil
+	}
+
+	for _, key := range []struct {
+		value *string
+		names []string
+	}{
+		{&c.APIKey, apiKeyEnv},
+		{&c.SecretKey, secretKeyEnv},
+	} {
+		if *key.value != "" {
+			continue
+		}
+
+		for _, name := range key.names {
+			path := filepath.Join(secretsDir, strings.ToLower(name))
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			secret, err := secrets.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			*key.value = secret
+			break
+		}
+	}
+
+	return nil
+}
+
+// First non-empty value among environment variables
+func firstEnv(getenv func(string) string, names []string) string {
+	for _, name := range names {
+		if value := getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// Decode a YAML or TOML file, chosen by extension
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, use .yaml, .yml or .toml", path, ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// Apply settings decoded from a config file
+func (c *Config) applyFile(path string, values map[string]any) error {
+	// Apply in a stable order so the first error is deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s, exists := settingByKey(key)
+		if !exists {
+			return fmt.Errorf("config file %s: unknown key %q", path, key)
+		}
+
+		value, err := fileValue(values[key])
+		if err != nil {
+			return fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+
+		if err := s.apply(c, value); err != nil {
+			return fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+	}
+
+	return nil
+}
+
+// Check settings shared by every command, reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if len(c.Symbols) == 0 {
+		errs = append(errs, errors.New("symbols: at least one trading symbol is required"))
+	}
+
+	if c.Mode != "" && !slices.Contains(modes, c.Mode) {
+		errs = append(errs, fmt.Errorf("mode: unknown mode %q, use manual, market-maker, avellaneda-stoikov or grid", c.Mode))
+	}
+
+	if !c.Quantity.IsPositive() {
+		errs = append(errs, fmt.Errorf("quantity: must be greater than 0, got %s", c.Quantity))
+	}
+
+	if tick, err := decimal.NewFromString(c.TickSize); err != nil || !tick.IsPositive() {
+		errs = append(errs, fmt.Errorf("tick_size: must be a positive decimal, got %q", c.TickSize))
+	}
+
+	if c.OrderbookDepth < 1 || c.OrderbookDepth > maxOrderbookDepth {
+		errs = append(errs, fmt.Errorf("orderbook_depth: must be between 1 and %d, got %d", maxOrderbookDepth, c.OrderbookDepth))
+	}
+
+	switch {
+	case c.WebSocketURL == "" && c.Profile == ProfileCustom:
+		errs = append(errs, fmt.Errorf("websocket_url: required with the %s profile", ProfileCustom))
+	case !isWebSocketURL(c.WebSocketURL):
+		errs = append(errs, fmt.Errorf("websocket_url: must start with ws:// or wss://, got %q", c.WebSocketURL))
+	}
+
+	if c.StreamURL != "" && !isWebSocketURL(c.StreamURL) {
+		errs = append(errs, fmt.Errorf("stream_url: must start with ws:// or wss://, got %q", c.StreamURL))
+	}
+
+	errs = append(errs, c.checkEndpoints()...)
+
+	if c.Output != "table" && c.Output != "json" {
+		errs = append(errs, fmt.Errorf("output: must be table or json, got %q", c.Output))
+	}
+
+	if c.Keystore != "" && c.SignerSocket != "" {
+		errs = append(errs, errors.New("keystore, signer_socket: use one source of keys, not both"))
+	}
+
+	if c.LogFormat != logging.FormatText && c.LogFormat != logging.FormatJSON {
+		errs = append(errs, fmt.Errorf("log_format: must be text or json, got %q", c.LogFormat))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate everything a strategy run needs on top of Validate
+func (c *Config) ValidateRun() error {
+	errs := []error{c.Validate()}
+
+	if c.Mode == "" {
+		errs = append(errs, errors.New("mode: required when running non-interactively, use manual, market-maker, avellaneda-stoikov or grid"))
+	}
+
+	if c.Mode.Quotes() && !c.SpreadPercentage.IsPositive() {
+		errs = append(errs, fmt.Errorf("spread_percentage: must be greater than 0, got %s", c.SpreadPercentage))
+	}
+
+	if c.Mode == ModeAvellanedaStoikov && c.ASHorizon <= 0 {
+		errs = append(errs, fmt.Errorf("as_horizon: must be greater than 0, got %s", c.ASHorizon))
+	}
+
+	if c.Mode == ModeGrid {
+		errs = append(errs, c.checkGrid()...)
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown_timeout: must be greater than 0, got %s", c.ShutdownTimeout))
+	}
+
+	if c.ControlAddr != "" {
+		if _, _, err := net.SplitHostPort(c.ControlAddr); err != nil {
+			errs = append(errs, fmt.Errorf("control_addr: must be host:port or :port, got %q", c.ControlAddr))
+		}
+
+		if c.ControlToken == "" {
+			errs = append(errs, errors.New("control_token: required when control_addr is set, set BINANCE_CONTROL_TOKEN"))
+		}
+	}
+
+	if c.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+			errs = append(errs, fmt.Errorf("metrics_addr: must be host:port or :port, got %q", c.MetricsAddr))
+		}
+	}
+
+	errs = append(errs, c.RequireRiskLimits(), c.RequireCredentials())
+
+	return errors.Join(errs...)
+}
+
+// Check the grid settings, which have no usable defaults for the bounds
+func (c *Config) checkGrid() []error {
+	var errs []error
+
+	if len(c.Symbols) > 1 {
+		errs = append(errs, fmt.Errorf("symbols: a grid trades one symbol, got %d", len(c.Symbols)))
+	}
+
+	if !c.GridLower.IsPositive() || !c.GridUpper.GreaterThan(c.GridLower) {
+		errs = append(errs, fmt.Errorf("grid_lower, grid_upper: must satisfy 0 < grid_lower < grid_upper, got %s and %s", c.GridLower, c.GridUpper))
+	}
+
+	if c.GridStateFile == "" {
+		errs = append(errs, errors.New("grid_state_file: required so the grid survives restarts"))
+	}
+
+	return errs
+}
+
+// Check that a mainnet session cannot place unbounded orders
+func (c *Config) RequireRiskLimits() error {
+	if !c.profile().Mainnet {
+		return nil
+	}
+
+	var errs []error
+	if !c.MaxOrderNotional.IsPositive() {
+		errs = append(errs, fmt.Errorf("max_order_notional: required with the %s profile", ProfileMainnet))
+	}
+	if c.MaxOpenOrders <= 0 {
+		errs = append(errs, fmt.Errorf("max_open_orders: required with the %s profile", ProfileMainnet))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Check that API keys are present for signed requests
+func (c *Config) RequireCredentials() error {
+	if (c.APIKey != "" && c.SecretKey != "") || c.Keystore != "" || c.SignerSocket != "" {
+		return nil
+	}
+
+	apiKeyEnv, secretKeyEnv := c.profile().keyEnv()
+	hint := fmt.Sprintf("set %s and %s", apiKeyEnv[0], secretKeyEnv[0])
+	if c.Profile == ProfileTestnet {
+		hint += " (keys from https://testnet.binance.vision)"
+	}
+
+	return fmt.Errorf("api_key, secret_key: both are required, %s, or use a keystore or signing agent", hint)
+}
+
+func isWebSocketURL(value string) bool {
+	return strings.HasPrefix(value, "ws://") || strings.HasPrefix(value, "wss://")
+}
+
+// Parse a comma separated list of symbols
+func ParseSymbols(input string) []string {
+	var symbols []string
+	for _, symbol := range strings.Split(input, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+func settingByKey(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+
+	return setting{}, false
+}
+
+// Convert a decoded file value to the string form every source shares
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		// Avoid exponent notation, which decimals do not accept
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int, int64, bool:
+		return fmt.Sprint(v), nil
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			part, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+func setSymbols(c *Config, value string) error {
+	symbols := ParseSymbols(value)
+	if len(symbols) == 0 {
+		return fmt.Errorf("no symbols in %q", value)
+	}
+
+	c.Symbols = symbols
+	return nil
+}
+
+func setMode(c *Config, value string) error {
+	mode := Mode(strings.ToLower(strings.TrimSpace(value)))
+	if !slices.Contains(modes, mode) {
+		return fmt.Errorf("unknown mode %q, use manual, market-maker, avellaneda-stoikov or grid", value)
+	}
+
+	c.Mode = mode
+	return nil
+}
+
+func setQuantity(c *Config, value string) error {
+	quantity, err := decimal.NewFromString(value)
+	if err != nil {
+		return err
+	}
+
+	c.Quantity = quantity
+	return nil
+}
+
+func setSpread(c *Config, value string) error {
+	spread, err := decimal.NewFromString(value)
+	if err != nil {
+		return err
+	}
+
+	c.SpreadPercentage = spread
+	return nil
+}
+
+func setFairValue(c *Config, value string) error {
+	fairValue := FairValue(strings.ToLower(strings.TrimSpace(value)))
+	if !slices.Contains(fairValues, fairValue) {
+		return fmt.Errorf("unknown fair value %q, use mid, microprice, depth or trade-flow", value)
+	}
+
+	c.FairValue = fairValue
+	return nil
+}
+
+func setFairValueLevels(c *Config, value string) error {
+	levels, err := strconv.Atoi(value)
+	if err != nil || levels < 1 || levels > maxOrderbookDepth {
+		return fmt.Errorf("must be a whole number from 1 to %d, got %q", maxOrderbookDepth, value)
+	}
+
+	c.FairValueLevels = levels
+	return nil
+}
+
+func setTradeFlowWindow(c *Config, value string) error {
+	window, err := strconv.Atoi(value)
+	if err != nil || window < 1 || window > maxTradeFlowWindow {
+		return fmt.Errorf("must be a whole number from 1 to %d, got %q", maxTradeFlowWindow, value)
+	}
+
+	c.TradeFlowWindow = window
+	return nil
+}
+
+func setTradeFlowWeight(c *Config, value string) error {
+	weight, err := nonNegativeDecimal(value)
+	if err != nil {
+		return err
+	}
+
+	c.TradeFlowWeight = weight
+	return nil
+}
+
+func setASGamma(c *Config, value string) error {
+	gamma, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(gamma) || math.IsInf(gamma, 0) || gamma <= 0 {
+		return fmt.Errorf("must be a number greater than 0, got %q", value)
+	}
+
+	c.ASGamma = gamma
+	return nil
+}
+
+func setASKappa(c *Config, value string) error {
+	kappa, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(kappa) || math.IsInf(kappa, 0) || kappa < 0 {
+		return fmt.Errorf("must be a number of at least 0, got %q", value)
+	}
+
+	c.ASKappa = kappa
+	return nil
+}
+
+func setASHorizon(c *Config, value string) error {
+	horizon, err := time.ParseDuration(value)
+	if err != nil || horizon <= 0 {
+		return fmt.Errorf("invalid duration %q, e.g. 5m", value)
+	}
+
+	c.ASHorizon = horizon
+	return nil
+}
+
+func setGridLower(c *Config, value string) error {
+	lower, err := nonNegativeDecimal(value)
+	if err != nil {
+		return err
+	}
+
+	c.GridLower = lower
+	return nil
+}
+
+func setGridUpper(c *Config, value string) error {
+	upper, err := nonNegativeDecimal(value)
+	if err != nil {
+		return err
+	}
+
+	c.GridUpper = upper
+	return nil
+}
+
+func setGridLevels(c *Config, value string) error {
+	levels, err := strconv.Atoi(value)
+	if err != nil || levels < 2 || levels > maxGridLevels {
+		return fmt.Errorf("must be a whole number from 2 to %d, got %q", maxGridLevels, value)
+	}
+
+	c.GridLevels = levels
+	return nil
+}
+
+func setGridSpacing(c *Config, value string) error {
+	spacing := strings.ToLower(strings.TrimSpace(value))
+	if spacing != "arithmetic" && spacing != "geometric" {
+		return fmt.Errorf("unknown grid spacing %q, use arithmetic or geometric", value)
+	}
+
+	c.GridSpacing = spacing
+	return nil
+}
+
+func setGridStateFile(c *Config, value string) error {
+	c.GridStateFile = value
+	return nil
+}
+
+func setTickSize(c *Config, value string) error {
+	if _, err := decimal.NewFromString(value); err != nil {
+		return err
+	}
+
+	c.TickSize = value
+	return nil
+}
+
+func setDepth(c *Config, value string) error {
+	depth, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid depth %q", value)
+	}
+
+	c.OrderbookDepth = depth
+	return nil
+}
+
+func setProfile(c *Config, value string) error {
+	if _, ok := LookupProfile(strings.ToLower(value)); !ok {
+		return fmt.Errorf("unknown profile %q, use one of %s", value, profileNames())
+	}
+
+	// Defaults were already replaced by Load, before any other setting
+	c.Profile = strings.ToLower(value)
+	return nil
+}
+
+func setWebSocketURL(c *Config, value string) error {
+	c.WebSocketURL = value
+	return nil
+}
+
+func setStreamURL(c *Config, value string) error {
+	c.StreamURL = value
+	return nil
+}
+
+func setRecordFile(c *Config, value string) error {
+	c.RecordFile = value
+	return nil
+}
+
+func setNonInteractive(c *Config, value string) error {
+	nonInteractive, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+
+	c.NonInteractive = nonInteractive
+	return nil
+}
+
+func setDashboard(c *Config, value string) error {
+	dashboard, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+
+	c.Dashboard = dashboard
+	return nil
+}
+
+func setDryRun(c *Config, value string) error {
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+
+	c.DryRun = dryRun
+	return nil
+}
+
+func setConfirmMainnet(c *Config, value string) error {
+	confirm, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+
+	c.ConfirmMainnet = confirm
+	return nil
+}
+
+func setMaxOrderQuantity(c *Config, value string) error {
+	quantity, err := nonNegativeDecimal(value)
+	if err != nil {
+		return err
+	}
+
+	c.MaxOrderQuantity = quantity
+	return nil
+}
+
+func setMaxOrderNotional(c *Config, value string) error {
+	notional, err := nonNegativeDecimal(value)
+	if err != nil {
+		return err
+	}
+
+	c.MaxOrderNotional = notional
+	return nil
+}
+
+func setMaxOpenOrders(c *Config, value string) error {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return fmt.Errorf("must be a whole number of 0 or more, got %q", value)
+	}
+
+	c.MaxOpenOrders = count
+	return nil
+}
+
+func nonNegativeDecimal(value string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if d.IsNegative() {
+		return decimal.Zero, fmt.Errorf("must be 0 or more, got %s", value)
+	}
+
+	return d, nil
+}
+
+func setOutput(c *Config, value string) error {
+	output := strings.ToLower(value)
+	if output != "table" && output != "json" {
+		return fmt.Errorf("unknown output format %q, use table or json", value)
+	}
+
+	c.Output = output
+	return nil
+}
+
+func setLogLevel(c *Config, value string) error {
+	level, err := logging.ParseLevel(value)
+	if err != nil {
+		return err
+	}
+
+	c.LogLevel = level
+	return nil
+}
+
+func setLogFormat(c *Config, value string) error {
+	format := strings.ToLower(value)
+	if format != logging.FormatText && format != logging.FormatJSON {
+		return fmt.Errorf("unknown log format %q, use text or json", value)
+	}
+
+	c.LogFormat = format
+	return nil
+}
+
+func setShutdownTimeout(c *Config, value string) error {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, e.g. 30s", value)
+	}
+
+	c.ShutdownTimeout = timeout
+	return nil
+}
+
+func setStateFile(c *Config, value string) error {
+	c.StateFile = value
+	return nil
+}
+
+func setControlAddr(c *Config, value string) error {
+	c.ControlAddr = value
+	return nil
+}
+
+func setControlToken(c *Config, value string) error {
+	c.ControlToken = value
+	return nil
+}
+
+func setMetricsAddr(c *Config, value string) error {
+	c.MetricsAddr = value
+	return nil
+}
+
+func setAPIKey(c *Config, value string) error {
+	c.APIKey = value
+	return nil
+}
+
+func setSecretKey(c *Config, value string) error {
+	c.SecretKey = value
+	return nil
+}
+
+func setAPIKeyFile(c *Config, value string) error {
+	key, err := secrets.ReadFile(value)
+	if err != nil {
+		return err
+	}
+
+	c.APIKey = key
+	return nil
+}
+
+func setSecretKeyFile(c *Config, value string) error {
+	key, err := secrets.ReadFile(value)
+	if err != nil {
+		return err
+	}
+
+	c.SecretKey = key
+	return nil
+}
+
+func setKeystore(c *Config, value string) error {
+	c.Keystore = value
+	return nil
+}
+
+func setPassphraseFile(c *Config, value string) error {
+	c.PassphraseFile = value
+	return nil
+}
+
+func setSignerSocket(c *Config, value string) error {
+	c.SignerSocket = value
+	return nil
+}
@@ -0,0 +1,542 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+)
+
+// Environment lookup backed by a map
+func env(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, args, err := Load(nil, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if strings.Join(c.Symbols, ",") != "BTCTUSD" || c.Quantity.String() != "0.001" || c.OrderbookDepth != 5 {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+
+	if c.Mode != "" || c.NonInteractive || c.Output != "table" {
+		t.Errorf("expected no mode, interactive and table output by default, got %q %v %q", c.Mode, c.NonInteractive, c.Output)
+	}
+
+	if len(args) != 0 {
+		t.Errorf("expected no arguments, got %v", args)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+symbols: [ethusdt, btcusdt]
+mode: market-maker
+quantity: 0.00001
+spread_percentage: 0.5
+orderbook_depth: 10
+`)
+
+	environment := env(map[string]string{
+		"BINANCE_CONFIG":          path,
+		"BINANCE_QUANTITY":        "0.002",
+		"BINANCE_ORDERBOOK_DEPTH": "20",
+		"BINANCE_API_KEY":         "key",
+		"BINANCE_SECRET_KEY":      "secret",
+	})
+
+	c, _, err := Load([]string{"--depth", "50", "--non-interactive", "--dashboard", "--dry-run"}, environment, io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	// File over defaults
+	if strings.Join(c.Symbols, ",") != "ETHUSDT,BTCUSDT" || c.Mode != ModeMarketMaker || c.SpreadPercentage.String() != "0.5" {
+		t.Errorf("expected file values, got %v %q %s", c.Symbols, c.Mode, c.SpreadPercentage)
+	}
+
+	// Environment over file
+	if c.Quantity.String() != "0.002" || c.APIKey != "key" || c.SecretKey != "secret" {
+		t.Errorf("expected environment values, got %s %q %q", c.Quantity, c.APIKey, c.SecretKey)
+	}
+
+	// Flags over environment
+	if c.OrderbookDepth != 50 || !c.NonInteractive || !c.Dashboard || !c.DryRun {
+		t.Errorf("expected flag values, got depth %d non-interactive %v dashboard %v dry run %v", c.OrderbookDepth, c.NonInteractive, c.Dashboard, c.DryRun)
+	}
+
+	if err := c.ValidateRun(); err != nil {
+		t.Errorf("ValidateRun() returned error: %v", err)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+symbols = ["BNBUSDT"]
+mode = "manual"
+quantity = "0.5"
+tick_size = "0.0001"
+non_interactive = true
+`)
+
+	c, _, err := Load([]string{"--config", path}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.Symbols[0] != "BNBUSDT" || c.Mode != ModeManual || c.Quantity.String() != "0.5" || c.TickSize != "0.0001" || !c.NonInteractive {
+		t.Errorf("unexpected config: %+v", c)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unknown file key",
+			file:    "config.yaml",
+			content: "symbol: BTCUSDT\n",
+			want:    `unknown key "symbol"`,
+		},
+		{
+			name:    "unsupported format",
+			file:    "config.json",
+			content: "{}",
+			want:    "unsupported format",
+		},
+		{
+			name: "invalid environment value",
+			env:  map[string]string{"BINANCE_QUANTITY": "lots"},
+			want: "environment variable BINANCE_QUANTITY",
+		},
+		{
+			name: "invalid flag value",
+			args: []string{"--mode", "scalper"},
+			want: `flag --mode: unknown mode "scalper"`,
+		},
+		{
+			name: "secrets are not flags",
+			args: []string{"--api-key", "key"},
+			want: "flag provided but not defined",
+		},
+		{
+			name: "control token is not a flag",
+			args: []string{"--control-token", "token"},
+			want: "flag provided but not defined",
+		},
+		{
+			name: "unknown log level",
+			env:  map[string]string{"BINANCE_LOG_LEVEL": "verbose"},
+			want: `unknown log level "verbose"`,
+		},
+		{
+			name: "invalid shutdown timeout",
+			env:  map[string]string{"BINANCE_SHUTDOWN_TIMEOUT": "30"},
+			want: `invalid duration "30"`,
+		},
+		{
+			name: "unknown log format",
+			args: []string{"--log-format", "xml"},
+			want: `unknown log format "xml"`,
+		},
+		{
+			name: "unknown profile",
+			env:  map[string]string{"BINANCE_PROFILE": "staging"},
+			want: `unknown profile "staging"`,
+		},
+		{
+			name: "negative risk limit",
+			args: []string{"--max-open-orders", "-1"},
+			want: "flag --max-open-orders: must be a whole number",
+		},
+		{
+			name: "unknown fair value",
+			args: []string{"--fair-value", "last"},
+			want: `unknown fair value "last"`,
+		},
+		{
+			name: "trade flow window beyond trades.recent",
+			env:  map[string]string{"BINANCE_TRADE_FLOW_WINDOW": "5000"},
+			want: "must be a whole number from 1 to 1000",
+		},
+		{
+			name: "non-positive risk aversion",
+			env:  map[string]string{"BINANCE_AS_GAMMA": "0"},
+			want: "environment variable BINANCE_AS_GAMMA: must be a number greater than 0",
+		},
+		{
+			name: "unknown grid spacing",
+			args: []string{"--grid-spacing", "fibonacci"},
+			want: `unknown grid spacing "fibonacci"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment := map[string]string{}
+			for key, value := range tt.env {
+				environment[key] = value
+			}
+
+			if tt.file != "" {
+				environment["BINANCE_CONFIG"] = writeFile(t, tt.file, tt.content)
+			}
+
+			_, _, err := Load(tt.args, env(environment), io.Discard)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	if _, _, err := Load([]string{"--help"}, env(nil), io.Discard); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+}
+
+func TestLoadReturnsCommand(t *testing.T) {
+	c, args, err := Load([]string{"--output", "JSON", "--log-level", "debug", "--log-format", "json", "order", "status", "BTCUSDT", "1"}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.Output != "json" || c.LogLevel != slog.LevelDebug || c.LogFormat != "json" {
+		t.Errorf("expected json output and debug json logs, got %q, %v, %q", c.Output, c.LogLevel, c.LogFormat)
+	}
+
+	if strings.Join(args, " ") != "order status BTCUSDT 1" {
+		t.Errorf("expected the command to be returned, got %v", args)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	c := Default()
+	c.Symbols = nil
+	c.Quantity = c.Quantity.Neg()
+	c.TickSize = "abc"
+	c.OrderbookDepth = 0
+	c.WebSocketURL = "https://example.com"
+
+	err := c.ValidateRun()
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	for _, want := range []string{"symbols:", "mode:", "quantity:", "tick_size:", "orderbook_depth:", "websocket_url:", "api_key, secret_key:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in validation error:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateSpreadOnlyForMarketMaker(t *testing.T) {
+	c := Default()
+	c.APIKey, c.SecretKey = "key", "secret"
+	c.SpreadPercentage = c.SpreadPercentage.Neg()
+
+	c.Mode = ModeManual
+	if err := c.ValidateRun(); err != nil {
+		t.Errorf("expected manual mode to ignore the spread, got %v", err)
+	}
+
+	c.Mode = ModeMarketMaker
+	if err := c.ValidateRun(); err == nil || !strings.Contains(err.Error(), "spread_percentage:") {
+		t.Errorf("expected a spread error for the market maker, got %v", err)
+	}
+}
+
+func TestValidateAllowsCommandsWithoutModeOrKeys(t *testing.T) {
+	c := Default()
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() returned error: %v", err)
+	}
+
+	if err := c.RequireCredentials(); err == nil {
+		t.Error("expected missing credentials to be reported")
+	}
+}
+
+func TestValidateControlAPI(t *testing.T) {
+	c := Default()
+	c.Mode = ModeManual
+	c.APIKey, c.SecretKey = "key", "secret"
+
+	c.ControlAddr = "8081"
+	err := c.ValidateRun()
+	if err == nil || !strings.Contains(err.Error(), "control_addr:") || !strings.Contains(err.Error(), "control_token:") {
+		t.Errorf("expected address and token errors, got %v", err)
+	}
+
+	c.ControlAddr, c.ControlToken = ":8081", "token"
+	if err := c.ValidateRun(); err != nil {
+		t.Errorf("ValidateRun() returned error: %v", err)
+	}
+
+	c.MetricsAddr = "9090"
+	if err := c.ValidateRun(); err == nil || !strings.Contains(err.Error(), "metrics_addr:") {
+		t.Errorf("expected a metrics address error, got %v", err)
+	}
+}
+
+func TestProfileDefaultsAndKeys(t *testing.T) {
+	environment := env(map[string]string{
+		"BINANCE_API_KEY":            "shared-key",
+		"BINANCE_SECRET_KEY":         "shared-secret",
+		"BINANCE_MAINNET_API_KEY":    "mainnet-key",
+		"BINANCE_MAINNET_SECRET_KEY": "mainnet-secret",
+	})
+
+	c, _, err := Load(nil, environment, io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.Profile != ProfileTestnet || !strings.Contains(c.WebSocketURL, "testnet") || c.APIKey != "shared-key" {
+		t.Errorf("expected testnet with the shared keys by default, got %s %s %s", c.Profile, c.WebSocketURL, c.APIKey)
+	}
+
+	c, _, err = Load([]string{"--profile", "MAINNET"}, environment, io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.WebSocketURL != "wss://ws-api.binance.com:443/ws-api/v3" || c.StreamURL != "wss://stream.binance.com:9443/stream" {
+		t.Errorf("unexpected mainnet endpoints: %s %s", c.WebSocketURL, c.StreamURL)
+	}
+
+	if c.APIKey != "mainnet-key" || c.SecretKey != "mainnet-secret" {
+		t.Errorf("expected the mainnet keys, got %s %s", c.APIKey, c.SecretKey)
+	}
+
+	if c.MaxOrderNotional.String() != "100" || c.MaxOpenOrders != 10 {
+		t.Errorf("expected mainnet risk limits, got %s %d", c.MaxOrderNotional, c.MaxOpenOrders)
+	}
+}
+
+func TestMainnetIgnoresSharedKeys(t *testing.T) {
+	path := writeFile(t, "config.yaml", "profile: mainnet\nmax_order_notional: 50\n")
+
+	c, _, err := Load(nil, env(map[string]string{
+		"BINANCE_CONFIG":     path,
+		"BINANCE_API_KEY":    "shared-key",
+		"BINANCE_SECRET_KEY": "shared-secret",
+	}), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.Profile != ProfileMainnet || c.MaxOrderNotional.String() != "50" {
+		t.Errorf("expected the file to select mainnet and override its limit, got %s %s", c.Profile, c.MaxOrderNotional)
+	}
+
+	err = c.RequireCredentials()
+	if c.APIKey != "" || err == nil || !strings.Contains(err.Error(), "BINANCE_MAINNET_API_KEY") {
+		t.Errorf("expected shared keys to be ignored on mainnet, got key %q and error %v", c.APIKey, err)
+	}
+}
+
+func TestValidateEndpointsMatchProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		url     string
+		want    string // Expected error, empty when valid
+	}{
+		{"testnet", ProfileTestnet, "", ""},
+		{"mainnet", ProfileMainnet, "", ""},
+		{"mainnet url on testnet", ProfileTestnet, "wss://ws-api.binance.com:9443/ws-api/v3", "websocket_url: wss://ws-api.binance.com:9443/ws-api/v3 is a mainnet endpoint"},
+		{"mainnet url on custom", ProfileCustom, "wss://ws-api.binance.com/ws-api/v3", "is a mainnet endpoint"},
+		{"testnet url on mainnet", ProfileMainnet, "wss://testnet.binance.vision/ws-api/v3", "is not a mainnet endpoint"},
+		{"custom without url", ProfileCustom, "", "websocket_url: required with the custom profile"},
+		{"custom url", ProfileCustom, "ws://10.0.0.5:8090/ws-api/v3", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"--profile", tt.profile}
+			if tt.url != "" {
+				args = append(args, "--ws-url", tt.url)
+			}
+
+			c, _, err := Load(args, env(nil), io.Discard)
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+
+			err = c.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate() returned error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateRunRequiresRiskLimitsOnMainnet(t *testing.T) {
+	c, _, err := Load([]string{"--profile", "mainnet", "--mode", "manual", "--max-order-notional", "0"}, env(map[string]string{
+		"BINANCE_MAINNET_API_KEY":    "key",
+		"BINANCE_MAINNET_SECRET_KEY": "secret",
+	}), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if err := c.ValidateRun(); err == nil || !strings.Contains(err.Error(), "max_order_notional:") {
+		t.Errorf("expected a missing risk limit error, got %v", err)
+	}
+
+	c.MaxOrderNotional = c.MaxOrderNotional.Add(c.Quantity)
+	if err := c.ValidateRun(); err != nil {
+		t.Errorf("ValidateRun() returned error: %v", err)
+	}
+}
+
+func TestLoadKeysFromSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secretsDir = dir
+	t.Cleanup(func() { secretsDir = "/run/secrets" })
+
+	os.WriteFile(filepath.Join(dir, "binance_testnet_api_key"), []byte("docker-key\n"), 0o600)
+	os.WriteFile(filepath.Join(dir, "binance_secret_key"), []byte("docker-secret\n"), 0o600)
+
+	c, _, err := Load(nil, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.APIKey != "docker-key" || c.SecretKey != "docker-secret" {
+		t.Errorf("expected keys from Docker secrets, got %q %q", c.APIKey, c.SecretKey)
+	}
+
+	secretFile := writeFile(t, "secret", "file-secret\n")
+	c, _, err = Load([]string{"--secret-key-file", secretFile}, env(map[string]string{
+		"BINANCE_API_KEY": "env-key",
+	}), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.APIKey != "env-key" || c.SecretKey != "file-secret" {
+		t.Errorf("expected the environment and secret file to win over Docker secrets, got %q %q", c.APIKey, c.SecretKey)
+	}
+
+	c, _, err = Load([]string{"--keystore", "keys.json"}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.APIKey != "" || c.SecretKey != "" || c.RequireCredentials() != nil {
+		t.Errorf("expected a keystore to replace Docker secrets, got %q %q", c.APIKey, c.SecretKey)
+	}
+}
+
+func TestValidateOneKeySource(t *testing.T) {
+	c, _, err := Load([]string{"--keystore", "keys.json", "--signer-socket", "signer.sock"}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "keystore, signer_socket") {
+		t.Errorf("expected a keystore and signing agent to be rejected together, got %v", err)
+	}
+}
+
+func TestLoadFairValue(t *testing.T) {
+	c, _, err := Load(nil, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.FairValue != FairValueMid {
+		t.Errorf("expected the mid price by default, got %q", c.FairValue)
+	}
+
+	path := writeFile(t, "config.yaml", "fair_value: Depth\nfair_value_levels: 3\ntrade_flow_weight: 0.25\n")
+	c, _, err = Load([]string{"--config", path}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.FairValue != FairValueDepth || c.FairValueLevels != 3 || c.TradeFlowWeight.String() != "0.25" {
+		t.Errorf("unexpected fair value settings: %q %d %s", c.FairValue, c.FairValueLevels, c.TradeFlowWeight)
+	}
+}
+
+func TestValidateGrid(t *testing.T) {
+	path := writeFile(t, "config.yaml", "mode: grid\nsymbols: [BTCUSDT]\ngrid_lower: 90\ngrid_upper: 110\ngrid_spacing: Geometric\n")
+	c, _, err := Load([]string{"--config", path, "--grid-levels", "21"}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.GridLower.String() != "90" || c.GridUpper.String() != "110" || c.GridLevels != 21 || c.GridSpacing != "geometric" {
+		t.Errorf("unexpected grid settings: %s %s %d %q", c.GridLower, c.GridUpper, c.GridLevels, c.GridSpacing)
+	}
+
+	if errs := c.checkGrid(); len(errs) != 0 {
+		t.Errorf("unexpected grid errors: %v", errs)
+	}
+
+	c.Symbols = []string{"BTCUSDT", "ETHUSDT"}
+	c.GridUpper = c.GridLower
+	c.GridStateFile = ""
+
+	if errs := c.checkGrid(); len(errs) != 3 {
+		t.Errorf("expected three grid errors, got %v", errs)
+	}
+}
+
+func TestLoadAvellanedaStoikov(t *testing.T) {
+	path := writeFile(t, "config.yaml", "mode: Avellaneda-Stoikov\nas_gamma: 0.05\nas_horizon: 90s\n")
+	c, _, err := Load([]string{"--config", path, "--as-kappa", "12.5"}, env(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if c.Mode != ModeAvellanedaStoikov || !c.Mode.Quotes() {
+		t.Errorf("expected a quoting avellaneda-stoikov mode, got %q", c.Mode)
+	}
+
+	if c.ASGamma != 0.05 || c.ASKappa != 12.5 || c.ASHorizon != 90*time.Second {
+		t.Errorf("unexpected model parameters: %g %g %s", c.ASGamma, c.ASKappa, c.ASHorizon)
+	}
+
+	c.SpreadPercentage = decimal.Zero
+	if err := c.ValidateRun(); err == nil || !strings.Contains(err.Error(), "spread_percentage") {
+		t.Errorf("expected the spread floor to be required, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+)
+
+const (
+	ProfileMainnet  = "mainnet"   // Real funds on binance.com
+	ProfileTestnet  = "testnet"   // Spot testnet at binance.vision
+	ProfileLocalSim = "local-sim" // Exchange simulator on this machine
+	ProfileCustom   = "custom"    // Endpoints given explicitly
+)
+
+// Named exchange environment and the defaults it brings
+type Profile struct {
+	Name         string
+	WebSocketURL string // WebSocket API endpoint, empty when it must be configured
+	StreamURL    string // Combined market data streams endpoint
+	KeyPrefix    string // Keys are read from <prefix>_API_KEY and <prefix>_SECRET_KEY
+	SharedKeys   bool   // Also read BINANCE_API_KEY and BINANCE_SECRET_KEY
+	Mainnet      bool   // Trading needs confirmation and order risk limits are required
+
+	// Default risk limits, zero leaves a limit off
+	MaxOrderNotional decimal.Decimal
+	MaxOpenOrders    int
+}
+
+var profiles = []Profile{
+	{
+		Name:             ProfileMainnet,
+		WebSocketURL:     "wss://ws-api.binance.com:443/ws-api/v3",
+		StreamURL:        "wss://stream.binance.com:9443/stream",
+		KeyPrefix:        "BINANCE_MAINNET",
+		Mainnet:          true,
+		MaxOrderNotional: decimal.NewFromInt(100),
+		MaxOpenOrders:    10,
+	},
+	{
+		Name:         ProfileTestnet,
+		WebSocketURL: "wss://testnet.binance.vision/ws-api/v3",
+		StreamURL:    "wss://stream.testnet.binance.vision/stream",
+		KeyPrefix:    "BINANCE_TESTNET",
+		SharedKeys:   true,
+	},
+	{
+		Name:         ProfileLocalSim,
+		WebSocketURL: "ws://127.0.0.1:8090/ws-api/v3",
+		StreamURL:    "ws://127.0.0.1:8090/stream",
+		KeyPrefix:    "BINANCE_LOCAL_SIM",
+		SharedKeys:   true,
+	},
+	{
+		Name:       ProfileCustom,
+		SharedKeys: true,
+	},
+}
+
+// Find a profile by name
+func LookupProfile(name string) (Profile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return Profile{}, false
+}
+
+func profileNames() string {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Environment variables the profile reads API keys from, most preferred first
+func (p Profile) keyEnv() (apiKey, secretKey []string) {
+	if p.KeyPrefix != "" {
+		apiKey = append(apiKey, p.KeyPrefix+"_API_KEY")
+		secretKey = append(secretKey, p.KeyPrefix+"_SECRET_KEY")
+	}
+
+	if p.SharedKeys {
+		apiKey = append(apiKey, "BINANCE_API_KEY")
+		secretKey = append(secretKey, "BINANCE_SECRET_KEY")
+	}
+
+	return apiKey, secretKey
+}
+
+// Replace the endpoints and safety defaults with the profile's
+func (c *Config) applyProfile(p Profile) {
+	c.Profile = p.Name
+	c.WebSocketURL = p.WebSocketURL
+	c.StreamURL = p.StreamURL
+	c.MaxOrderQuantity = decimal.Zero
+	c.MaxOrderNotional = p.MaxOrderNotional
+	c.MaxOpenOrders = p.MaxOpenOrders
+}
+
+// Selected profile; Load only accepts known names
+func (c *Config) profile() Profile {
+	p, _ := LookupProfile(c.Profile)
+	return p
+}
+
+// Whether an endpoint is on binance.com, where orders use real funds
+func isMainnetURL(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	return host == "binance.com" || strings.HasSuffix(host, ".binance.com")
+}
+
+// Keep mainnet endpoints behind the mainnet profile, and the mainnet profile on mainnet
+func (c *Config) checkEndpoints() []error {
+	var errs []error
+	mainnet := c.profile().Mainnet
+
+	for _, endpoint := range []struct{ key, url string }{
+		{"websocket_url", c.WebSocketURL},
+		{"stream_url", c.StreamURL},
+	} {
+		if endpoint.url == "" {
+			continue
+		}
+
+		switch onMainnet := isMainnetURL(endpoint.url); {
+		case onMainnet && !mainnet:
+			errs = append(errs, fmt.Errorf("%s: %s is a mainnet endpoint, select it with profile %s instead of %s", endpoint.key, endpoint.url, ProfileMainnet, c.Profile))
+		case !onMainnet && mainnet:
+			errs = append(errs, fmt.Errorf("%s: %s is not a mainnet endpoint, the %s profile only connects to binance.com", endpoint.key, endpoint.url, ProfileMainnet))
+		}
+	}
+
+	return errs
+}
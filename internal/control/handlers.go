@@ -0,0 +1,402 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/trader"
+)
+
+type strategyStatus struct {
+	Symbol           string `json:"symbol"`
+	Active           bool   `json:"active"`
+	Paused           bool   `json:"paused"`
+	SpreadPercentage string `json:"spreadPercentage,omitempty"`
+	OrderQty         string `json:"orderQty,omitempty"`
+}
+
+type connectionStatus struct {
+	Class               string  `json:"class"`
+	Index               int     `json:"index"`
+	RequestLatencyMs    float64 `json:"requestLatencyMs"`
+	HeartbeatLatencyMs  float64 `json:"heartbeatLatencyMs"`
+	ConsecutiveFailures int     `json:"consecutiveFailures"`
+	InFlight            int     `json:"inFlight"`
+}
+
+type statusResponse struct {
+	Uptime      string             `json:"uptime"`
+	DryRun      bool               `json:"dryRun"`
+	OpenOrders  int                `json:"openOrders"`
+	Strategies  []strategyStatus   `json:"strategies"`
+	Connections []connectionStatus `json:"connections"`
+}
+
+type positionResponse struct {
+	Symbol string           `json:"symbol"`
+	Base   decimal.Decimal  `json:"base"`
+	Quote  decimal.Decimal  `json:"quote"`
+	Fills  int              `json:"fills"`
+	Mark   *decimal.Decimal `json:"mark,omitempty"` // Mid price, missing when the orderbook is unavailable
+	PnL    *decimal.Decimal `json:"pnl,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// Body of a settings change; missing fields are left as they are
+type settingsRequest struct {
+	SpreadPercentage *decimal.Decimal `json:"spreadPercentage"`
+	OrderQty         *decimal.Decimal `json:"orderQty"`
+}
+
+type cancelAllResponse struct {
+	Canceled []models.Order    `json:"canceled"`
+	Errors   map[string]string `json:"errors,omitempty"` // Failure by symbol
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	response := statusResponse{
+		Uptime:      time.Since(s.started).Round(time.Second).String(),
+		DryRun:      s.options.DryRun,
+		OpenOrders:  len(s.client.GetOrderManager().GetActiveOrders()),
+		Strategies:  []strategyStatus{},
+		Connections: []connectionStatus{},
+	}
+
+	if s.runner != nil {
+		for _, strategy := range s.runner.Strategies() {
+			response.Strategies = append(response.Strategies, describe(strategy))
+		}
+	}
+
+	if s.options.Connections != nil {
+		for _, c := range s.options.Connections() {
+			response.Connections = append(response.Connections, connectionStatus{
+				Class:               c.Class.String(),
+				Index:               c.Index,
+				RequestLatencyMs:    milliseconds(c.RequestLatency),
+				HeartbeatLatencyMs:  milliseconds(c.HeartbeatLatency),
+				ConsecutiveFailures: c.ConsecutiveFailures,
+				InFlight:            c.InFlight,
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Tracked orders, optionally filtered by ?symbol= and a comma separated ?status=
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	manager := s.client.GetOrderManager()
+
+	var orders []models.Order
+	if symbol := strings.ToUpper(r.URL.Query().Get("symbol")); symbol != "" {
+		orders = manager.GetOrdersBySymbol(symbol)
+	} else {
+		orders = manager.GetAllOrders()
+	}
+
+	if statuses := r.URL.Query().Get("status"); statuses != "" {
+		wanted := make(map[string]bool)
+		for _, status := range strings.Split(statuses, ",") {
+			wanted[strings.ToUpper(strings.TrimSpace(status))] = true
+		}
+
+		filtered := []models.Order{}
+		for _, order := range orders {
+			if wanted[order.Status] {
+				filtered = append(filtered, order)
+			}
+		}
+		orders = filtered
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Symbol != orders[j].Symbol {
+			return orders[i].Symbol < orders[j].Symbol
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+
+	writeJSON(w, http.StatusOK, orders)
+}
+
+// Position of every traded symbol, marked at the current mid price
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	manager := s.client.GetOrderManager()
+	positions := []positionResponse{}
+
+	for _, symbol := range s.symbols() {
+		p := manager.Position(symbol)
+		response := positionResponse{Symbol: symbol, Base: p.Base, Quote: p.Quote, Fills: p.Fills}
+
+		book, err := s.client.GetOrderbook(symbol, 1)
+		switch {
+		case err != nil:
+			response.Error = err.Error()
+		case len(book.Bids) == 0 || len(book.Asks) == 0:
+			response.Error = "empty orderbook"
+		default:
+			mark := book.Bids[0].Price.Add(book.Asks[0].Price).Div(decimal.NewFromInt(2), decimal.Nearest)
+			pnl := p.PnL(mark)
+			response.Mark, response.PnL = &mark, &pnl
+		}
+
+		positions = append(positions, response)
+	}
+
+	writeJSON(w, http.StatusOK, positions)
+}
+
+// Start, stop, pause or resume the strategy trading a symbol
+func (s *Server) handleStrategyAction(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.strategy(w, r.PathValue("symbol"))
+	if !ok {
+		return
+	}
+
+	action := r.PathValue("action")
+
+	switch action {
+	case "start":
+		strategy.Start()
+	case "stop":
+		strategy.Stop()
+	case "pause", "resume":
+		p, ok := strategy.(trader.Pauser)
+		if !ok {
+			writeError(w, http.StatusConflict, fmt.Errorf("the %s strategy cannot be paused", strategy.Symbol()))
+			return
+		}
+
+		if action == "pause" {
+			p.Pause()
+		} else {
+			p.Resume()
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action %q, use start, stop, pause or resume", action))
+		return
+	}
+
+	slog.Info("Control action", "action", action, "symbol", strategy.Symbol())
+	writeJSON(w, http.StatusOK, describe(strategy))
+}
+
+// Change the spread and order quantity of a running market maker
+func (s *Server) handleStrategySettings(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.strategy(w, r.PathValue("symbol"))
+	if !ok {
+		return
+	}
+
+	q, ok := strategy.(trader.Quoter)
+	if !ok {
+		writeError(w, http.StatusConflict, fmt.Errorf("the %s strategy has no adjustable settings", strategy.Symbol()))
+		return
+	}
+
+	var request settingsRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid settings: %w", err))
+		return
+	}
+
+	// Check everything first so a bad value does not leave a half-applied change
+	var errs []error
+	if request.SpreadPercentage != nil && !request.SpreadPercentage.IsPositive() {
+		errs = append(errs, fmt.Errorf("spreadPercentage: must be greater than 0, got %s", request.SpreadPercentage))
+	}
+	if request.OrderQty != nil && !request.OrderQty.IsPositive() {
+		errs = append(errs, fmt.Errorf("orderQty: must be greater than 0, got %s", request.OrderQty))
+	}
+	if err := errors.Join(errs...); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if request.SpreadPercentage != nil {
+		if err := q.SetSpreadPercentage(*request.SpreadPercentage); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if request.OrderQty != nil {
+		if err := q.SetOrderQuantity(*request.OrderQty); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, describe(strategy))
+}
+
+// Cancel every open order on the ?symbol= symbols, or on every traded symbol.
+// Strategies on those symbols are paused first so they do not quote again.
+func (s *Server) handleCancelAll(w http.ResponseWriter, r *http.Request) {
+	symbols := r.URL.Query()["symbol"]
+	for i, symbol := range symbols {
+		symbols[i] = strings.ToUpper(symbol)
+	}
+
+	if len(symbols) == 0 {
+		symbols = s.symbols()
+	}
+
+	response := cancelAllResponse{Canceled: []models.Order{}}
+
+	for _, symbol := range symbols {
+		if strategy, ok := s.lookup(symbol); ok {
+			if p, ok := strategy.(trader.Pauser); ok {
+				p.Pause()
+			}
+		}
+
+		canceled, err := s.client.CancelAllOrders(symbol)
+		if err != nil {
+			if response.Errors == nil {
+				response.Errors = make(map[string]string)
+			}
+			response.Errors[symbol] = err.Error()
+			continue
+		}
+
+		response.Canceled = append(response.Canceled, canceled...)
+	}
+
+	slog.Info("Control canceled all orders", "canceled", len(response.Canceled), "symbols", symbols)
+
+	status := http.StatusOK
+	if len(response.Errors) > 0 {
+		status = http.StatusBadGateway
+	}
+
+	writeJSON(w, status, response)
+}
+
+// Stream order events as server-sent events, optionally for one ?symbol=
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+
+	events, unsubscribe := s.client.GetOrderManager().Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-s.closing:
+			return
+
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if symbol != "" && event.Order.Symbol != symbol {
+				continue
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.Warn("Failed to encode order event", "error", err)
+				continue
+			}
+
+			fmt.Fprintf(w, "event: order\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// Strategy for a path symbol, writing a not found response when there is none
+func (s *Server) strategy(w http.ResponseWriter, symbol string) (trader.Strategy, bool) {
+	strategy, ok := s.lookup(strings.ToUpper(symbol))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no strategy is trading %s", strings.ToUpper(symbol)))
+	}
+
+	return strategy, ok
+}
+
+func (s *Server) lookup(symbol string) (trader.Strategy, bool) {
+	if s.runner == nil {
+		return nil, false
+	}
+
+	return s.runner.Strategy(symbol)
+}
+
+// Symbols with a strategy or tracked orders, sorted
+func (s *Server) symbols() []string {
+	seen := make(map[string]bool)
+	var symbols []string
+
+	add := func(symbol string) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	if s.runner != nil {
+		for _, symbol := range s.runner.Symbols() {
+			add(symbol)
+		}
+	}
+
+	for _, symbol := range s.client.GetOrderManager().GetSymbols() {
+		add(symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+func describe(strategy trader.Strategy) strategyStatus {
+	status := strategyStatus{Symbol: strategy.Symbol(), Active: strategy.IsActive()}
+
+	if p, ok := strategy.(trader.Pauser); ok {
+		status.Paused = p.IsPaused()
+	}
+
+	if q, ok := strategy.(trader.Quoter); ok {
+		status.SpreadPercentage = q.SpreadPercentage().String()
+		status.OrderQty = q.OrderQuantity()
+	}
+
+	return status
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
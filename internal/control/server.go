@@ -0,0 +1,126 @@
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/trader"
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+// Interval between keep-alive comments on the event stream
+const keepAliveInterval = 15 * time.Second
+
+type Options struct {
+	Addr        string                       // Listen address; a missing host means localhost
+	Token       string                       // Bearer token required on every request
+	Connections func() []api.ConnectionStats // Connection health, optional
+	DryRun      bool                         // Orders are simulated, reported by /status
+}
+
+// HTTP/JSON API to inspect and steer a running trader
+type Server struct {
+	client  api.Operations
+	runner  *trader.Runner // Nil when no strategies are running
+	options Options
+	started time.Time
+	http    *http.Server
+	closing chan struct{} // Closed on shutdown to end event streams
+	once    sync.Once
+}
+
+func New(client api.Operations, runner *trader.Runner, options Options) *Server {
+	s := &Server{
+		client:  client,
+		runner:  runner,
+		options: options,
+		started: time.Now(),
+		closing: make(chan struct{}),
+	}
+
+	s.http = &http.Server{
+		Addr:              utils.ListenAddr(options.Addr),
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// Routes, all behind token authentication
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /orders", s.handleOrders)
+	mux.HandleFunc("GET /positions", s.handlePositions)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("POST /strategies/{symbol}/{action}", s.handleStrategyAction)
+	mux.HandleFunc("PATCH /strategies/{symbol}", s.handleStrategySettings)
+	mux.HandleFunc("POST /cancel-all", s.handleCancelAll)
+
+	return s.authenticate(mux)
+}
+
+// Listen and serve in the background. Returns once the address is bound, so
+// a port already in use is reported to the caller.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("control server: %w", err)
+	}
+
+	slog.Info("Control server listening", "addr", listener.Addr().String())
+
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Control server stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop accepting requests and wait for active ones, ending event streams
+func (s *Server) Shutdown(ctx context.Context) error {
+	// Event streams never finish on their own, so Shutdown would wait for them forever
+	s.once.Do(func() { close(s.closing) })
+
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.options.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.options.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="binance-trader"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write control response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,275 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+	"github.com/iamramtin/binance-trader/internal/trader"
+	"github.com/iamramtin/binance-trader/internal/trader/tradertest"
+)
+
+const token = "secret-token"
+
+func newServer(t *testing.T) (*Server, *tradertest.Exchange, *tradertest.Quoter) {
+	t.Helper()
+
+	client := tradertest.NewExchange()
+	maker := tradertest.NewQuoter("BTCUSDT", "0.5", "0.001")
+
+	runner := trader.NewRunner()
+	if err := runner.Add(maker); err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+
+	return New(client, runner, Options{Token: token}), client, maker
+}
+
+// Send an authenticated request and decode the JSON response into out
+func do(t *testing.T, s *Server, method, target, body string, out any) int {
+	t.Helper()
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, request)
+
+	if out != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, target, recorder.Body.String(), err)
+		}
+	}
+
+	return recorder.Code
+}
+
+func TestRequestsNeedTheToken(t *testing.T) {
+	s, _, _ := newServer(t)
+
+	for _, header := range []string{"", "Bearer wrong", token, "Basic " + token} {
+		request := httptest.NewRequest(http.MethodGet, "/status", nil)
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+
+		recorder := httptest.NewRecorder()
+		s.Handler().ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", header, recorder.Code)
+		}
+	}
+
+	// An empty configured token never matches
+	open := New(tradertest.NewExchange(), nil, Options{})
+	request := httptest.NewRequest(http.MethodGet, "/status", nil)
+	request.Header.Set("Authorization", "Bearer ")
+	recorder := httptest.NewRecorder()
+	open.Handler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected an empty token to be rejected, got %d", recorder.Code)
+	}
+}
+
+func TestStatusAndOrders(t *testing.T) {
+	s, client, _ := newServer(t)
+	client.Manager.TrackOrder(&models.Order{Symbol: "ETHUSDT", OrderID: 3, Status: "FILLED"})
+	client.Manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 2, Status: "NEW"})
+	client.Manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 1, Status: "CANCELED"})
+
+	var status statusResponse
+	if code := do(t, s, http.MethodGet, "/status", "", &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	if status.OpenOrders != 1 || len(status.Strategies) != 1 || status.Strategies[0].SpreadPercentage != "0.5" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	var orders []models.Order
+	do(t, s, http.MethodGet, "/orders", "", &orders)
+	if len(orders) != 3 || orders[0].OrderID != 1 || orders[2].Symbol != "ETHUSDT" {
+		t.Errorf("expected every order sorted by symbol and ID, got %+v", orders)
+	}
+
+	do(t, s, http.MethodGet, "/orders?symbol=btcusdt&status=new,filled", "", &orders)
+	if len(orders) != 1 || orders[0].OrderID != 2 {
+		t.Errorf("expected only the new BTCUSDT order, got %+v", orders)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	s, client, _ := newServer(t)
+	client.Manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 1, Side: "BUY", ExecutedQty: "1", CummulativeQuoteQty: "98", Status: "FILLED"})
+	client.Manager.TrackOrder(&models.Order{Symbol: "ETHUSDT", OrderID: 1, Side: "SELL", ExecutedQty: "1", CummulativeQuoteQty: "10", Status: "FILLED"})
+	client.Failing["ETHUSDT"] = true
+
+	var positions []map[string]any
+	do(t, s, http.MethodGet, "/positions", "", &positions)
+
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %+v", positions)
+	}
+
+	if btc := positions[0]; btc["symbol"] != "BTCUSDT" || btc["base"] != "1" || btc["mark"] != "100" || btc["pnl"] != "2" {
+		t.Errorf("unexpected BTCUSDT position: %+v", btc)
+	}
+
+	if eth := positions[1]; eth["error"] != "timeout" || eth["pnl"] != nil {
+		t.Errorf("expected the ETHUSDT position without a mark, got %+v", eth)
+	}
+}
+
+func TestStrategyActions(t *testing.T) {
+	s, _, maker := newServer(t)
+
+	var status strategyStatus
+	if code := do(t, s, http.MethodPost, "/strategies/btcusdt/pause", "", &status); code != http.StatusOK || !status.Paused {
+		t.Errorf("expected the strategy to be paused, got %d %+v", code, status)
+	}
+
+	do(t, s, http.MethodPost, "/strategies/BTCUSDT/resume", "", &status)
+	do(t, s, http.MethodPost, "/strategies/BTCUSDT/stop", "", &status)
+	if maker.Paused || maker.Active || status.Active {
+		t.Errorf("expected the strategy to be resumed and stopped, got %+v", maker)
+	}
+
+	do(t, s, http.MethodPost, "/strategies/BTCUSDT/start", "", &status)
+	if !maker.Active {
+		t.Error("expected the strategy to be started again")
+	}
+
+	if code := do(t, s, http.MethodPost, "/strategies/ETHUSDT/pause", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a symbol without a strategy, got %d", code)
+	}
+
+	if code := do(t, s, http.MethodPost, "/strategies/BTCUSDT/explode", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown action, got %d", code)
+	}
+}
+
+func TestStrategySettings(t *testing.T) {
+	s, _, maker := newServer(t)
+
+	var status strategyStatus
+	if code := do(t, s, http.MethodPatch, "/strategies/BTCUSDT", `{"spreadPercentage": "0.25", "orderQty": 0.002}`, &status); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	if status.SpreadPercentage != "0.25" || status.OrderQty != "0.002" {
+		t.Errorf("unexpected settings: %+v", status)
+	}
+
+	tests := []string{
+		`{"spreadPercentage": "1", "orderQty": "0"}`,
+		`{"spreadPercentage": "abc"}`,
+		`{"spread": "1"}`,
+	}
+
+	for _, body := range tests {
+		var response map[string]string
+		if code := do(t, s, http.MethodPatch, "/strategies/BTCUSDT", body, &response); code != http.StatusBadRequest || response["error"] == "" {
+			t.Errorf("%s: expected 400 with an error, got %d %v", body, code, response)
+		}
+	}
+
+	// Nothing is applied when any value is invalid
+	if maker.Spread.String() != "0.25" || maker.Quantity != "0.002" {
+		t.Errorf("expected the settings to be unchanged, got spread %s quantity %s", maker.Spread, maker.Quantity)
+	}
+}
+
+func TestCancelAllPausesStrategies(t *testing.T) {
+	s, client, maker := newServer(t)
+	client.Manager.TrackOrder(&models.Order{Symbol: "ETHUSDT", OrderID: 1, Status: "NEW"})
+	client.Failing["ETHUSDT"] = true
+
+	var response cancelAllResponse
+	if code := do(t, s, http.MethodPost, "/cancel-all", "", &response); code != http.StatusBadGateway {
+		t.Errorf("expected 502 for a partial failure, got %d", code)
+	}
+
+	if !maker.Paused || len(client.Canceled) != 1 || client.Canceled[0] != "BTCUSDT" {
+		t.Errorf("expected BTCUSDT to be paused and canceled, got paused %v canceled %v", maker.Paused, client.Canceled)
+	}
+
+	if response.Errors["ETHUSDT"] != "timeout" || len(response.Canceled) != 1 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	if code := do(t, s, http.MethodPost, "/cancel-all?symbol=btcusdt", "", &response); code != http.StatusOK {
+		t.Errorf("expected 200 for a single symbol, got %d", code)
+	}
+}
+
+func TestEventsStreamOrderChanges(t *testing.T) {
+	s, client, _ := newServer(t)
+
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?symbol=BTCUSDT", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("failed to open the event stream: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", response.Header.Get("Content-Type"))
+	}
+
+	// The stream is subscribed once the headers arrive
+	client.Manager.TrackOrder(&models.Order{Symbol: "ETHUSDT", OrderID: 1, Status: "NEW"})
+	client.Manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 2, Status: "NEW"})
+
+	reader := bufio.NewReader(response.Body)
+
+	var event, data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended early: %v", err)
+		}
+
+		if value, ok := strings.CutPrefix(line, "event: "); ok {
+			event = strings.TrimSpace(value)
+		}
+		if value, ok := strings.CutPrefix(line, "data: "); ok {
+			data = strings.TrimSpace(value)
+		}
+	}
+
+	var received ordermanager.OrderEvent
+	if err := json.Unmarshal([]byte(data), &received); err != nil {
+		t.Fatalf("invalid event data %q: %v", data, err)
+	}
+
+	if event != "order" || received.Type != ordermanager.EventTracked || received.Order.OrderID != 2 {
+		t.Errorf("expected the BTCUSDT order to be streamed, got %s %+v", event, received)
+	}
+
+	// Shutdown ends the stream instead of waiting for it
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Errorf("expected the stream to end cleanly, got %v", err)
+	}
+}
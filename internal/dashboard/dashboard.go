@@ -0,0 +1,327 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/trader"
+	"golang.org/x/term"
+)
+
+// Change in spread percentage for each + or - key press
+var spreadStep = decimal.RequireFromString("0.01")
+
+const (
+	defaultRefresh = time.Second
+	ctrlC          = 3
+)
+
+type Options struct {
+	Symbols     []string
+	Depth       int                          // Orderbook levels per side
+	Refresh     time.Duration                // Time between frames, one second when zero
+	Logs        *LogBuffer                   // Shown in the log pane when set
+	Connections func() []api.ConnectionStats // Connection health, optional
+	DryRun      bool                         // Orders are simulated, shown in the header
+}
+
+// Full-screen terminal view of the orderbook, our orders, position and
+// connection health, with keys to steer the strategies
+type Dashboard struct {
+	client        api.Operations
+	runner        *trader.Runner // Nil when no strategies are running
+	options       Options
+	current       int // Index of the symbol on screen
+	book          *models.ParsedOrderBook
+	bookErr       error
+	status        string
+	confirmCancel bool // Cancel all was pressed once and awaits confirmation
+	redraw        chan struct{}
+	mu            sync.Mutex
+}
+
+func New(client api.Operations, runner *trader.Runner, options Options) *Dashboard {
+	if options.Refresh <= 0 {
+		options.Refresh = defaultRefresh
+	}
+
+	return &Dashboard{
+		client:  client,
+		runner:  runner,
+		options: options,
+		redraw:  make(chan struct{}, 1),
+	}
+}
+
+// Take over the terminal until q is pressed or ctx is canceled
+func (d *Dashboard) Run(ctx context.Context, in, out *os.File) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	// The reader stays blocked on stdin after Run returns, until the process exits
+	quit := make(chan struct{})
+	go d.readKeys(in, quit)
+
+	ticker := time.NewTicker(d.options.Refresh)
+	defer ticker.Stop()
+
+	for {
+		d.refresh()
+		d.draw(out)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-quit:
+			return nil
+		case <-ticker.C:
+		case <-d.redraw:
+		}
+	}
+}
+
+func (d *Dashboard) readKeys(in *os.File, quit chan<- struct{}) {
+	buf := make([]byte, 16)
+
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			close(quit)
+			return
+		}
+
+		for _, key := range buf[:n] {
+			if d.handleKey(key) {
+				close(quit)
+				return
+			}
+		}
+
+		select {
+		case d.redraw <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Act on a key press, returning true when the user asked to quit
+func (d *Dashboard) handleKey(key byte) bool {
+	d.mu.Lock()
+	confirming := d.confirmCancel
+	d.confirmCancel = false
+	symbol := d.symbol()
+	d.mu.Unlock()
+
+	switch key {
+	case 'q', 'Q', ctrlC:
+		return true
+	case '\t', 'n':
+		d.mu.Lock()
+		d.current = (d.current + 1) % len(d.options.Symbols)
+		d.book, d.bookErr = nil, nil
+		d.status = ""
+		d.mu.Unlock()
+	case 'p':
+		d.togglePause(symbol)
+	case '+', '=':
+		d.adjustSpread(symbol, spreadStep)
+	case '-', '_':
+		d.adjustSpread(symbol, spreadStep.Neg())
+	case 'c':
+		if confirming {
+			d.cancelAll(symbol)
+			break
+		}
+
+		d.mu.Lock()
+		d.confirmCancel = true
+		d.mu.Unlock()
+		d.setStatus("Press c again to cancel every open order on %s", symbol)
+	}
+
+	return false
+}
+
+func (d *Dashboard) togglePause(symbol string) {
+	p, ok := d.pauser(symbol)
+	if !ok {
+		d.setStatus("No strategy that can pause is trading %s", symbol)
+		return
+	}
+
+	if p.IsPaused() {
+		p.Resume()
+		d.setStatus("Resumed %s", symbol)
+		return
+	}
+
+	d.setStatus("Pausing %s...", symbol)
+	p.Pause()
+	d.setStatus("Paused %s and canceled its orders", symbol)
+}
+
+func (d *Dashboard) adjustSpread(symbol string, step decimal.Decimal) {
+	q, ok := d.quoter(symbol)
+	if !ok {
+		d.setStatus("No market maker is trading %s", symbol)
+		return
+	}
+
+	spread := q.SpreadPercentage().Add(step)
+	if err := q.SetSpreadPercentage(spread); err != nil {
+		d.setStatus("Spread unchanged: %v", err)
+		return
+	}
+
+	d.setStatus("%s spread set to %s%%", symbol, spread)
+}
+
+// Pause the symbol's strategy so it does not quote again, then cancel every open order
+func (d *Dashboard) cancelAll(symbol string) {
+	if p, ok := d.pauser(symbol); ok {
+		p.Pause()
+	}
+
+	d.setStatus("Canceling every open order on %s...", symbol)
+
+	canceled, err := d.client.CancelAllOrders(symbol)
+	if err != nil {
+		d.setStatus("Cancel all on %s failed: %v", symbol, err)
+		return
+	}
+
+	d.setStatus("Canceled %d orders on %s", len(canceled), symbol)
+}
+
+func (d *Dashboard) quoter(symbol string) (trader.Quoter, bool) {
+	strategy, ok := d.strategy(symbol)
+	if !ok {
+		return nil, false
+	}
+
+	q, ok := strategy.(trader.Quoter)
+	return q, ok
+}
+
+func (d *Dashboard) pauser(symbol string) (trader.Pauser, bool) {
+	strategy, ok := d.strategy(symbol)
+	if !ok {
+		return nil, false
+	}
+
+	p, ok := strategy.(trader.Pauser)
+	return p, ok
+}
+
+func (d *Dashboard) strategy(symbol string) (trader.Strategy, bool) {
+	if d.runner == nil {
+		return nil, false
+	}
+
+	return d.runner.Strategy(symbol)
+}
+
+// Fetch the orderbook of the symbol on screen
+func (d *Dashboard) refresh() {
+	d.mu.Lock()
+	symbol := d.symbol()
+	d.mu.Unlock()
+
+	book, err := d.client.GetOrderbook(symbol, d.options.Depth)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Drop the result if the user moved to another symbol meanwhile
+	if symbol != d.symbol() {
+		return
+	}
+
+	d.bookErr = err
+	if err == nil {
+		d.book = book
+	}
+}
+
+func (d *Dashboard) snapshot(now time.Time) view {
+	d.mu.Lock()
+	v := view{
+		time:        now,
+		symbol:      d.symbol(),
+		symbolIndex: d.current,
+		symbolCount: len(d.options.Symbols),
+		book:        d.book,
+		bookErr:     d.bookErr,
+		depth:       d.options.Depth,
+		status:      d.status,
+		dryRun:      d.options.DryRun,
+	}
+	d.mu.Unlock()
+
+	manager := d.client.GetOrderManager()
+	v.orders = manager.GetOrdersBySymbol(v.symbol)
+	v.position = manager.Position(v.symbol)
+	v.strategy = d.strategyState(v.symbol)
+
+	if d.options.Connections != nil {
+		v.connections = d.options.Connections()
+	}
+
+	if d.options.Logs != nil {
+		v.logs = d.options.Logs.Lines(100)
+	}
+
+	return v
+}
+
+func (d *Dashboard) strategyState(symbol string) string {
+	p, ok := d.pauser(symbol)
+	if !ok {
+		return "no strategy"
+	}
+
+	state := "quoting"
+	if p.IsPaused() {
+		state = "PAUSED"
+	}
+
+	q, ok := p.(trader.Quoter)
+	if !ok {
+		return fmt.Sprintf("strategy %s", state)
+	}
+
+	return fmt.Sprintf("market maker %s, spread %s%%", state, q.SpreadPercentage())
+}
+
+func (d *Dashboard) draw(out *os.File) {
+	width, height, err := term.GetSize(int(out.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	fmt.Fprint(out, render(d.snapshot(time.Now()), width, height))
+}
+
+func (d *Dashboard) setStatus(format string, args ...any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.status = fmt.Sprintf(format, args...)
+}
+
+// Symbol on screen, called with mu held
+func (d *Dashboard) symbol() string {
+	return d.options.Symbols[d.current]
+}
@@ -0,0 +1,161 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/trader"
+	"github.com/iamramtin/binance-trader/internal/trader/tradertest"
+)
+
+func newDashboard(t *testing.T, client *tradertest.Exchange, symbols ...string) (*Dashboard, *tradertest.Quoter) {
+	t.Helper()
+
+	maker := tradertest.NewQuoter(symbols[0], "0.02", "0.001")
+
+	runner := trader.NewRunner()
+	if err := runner.Add(maker); err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+
+	return New(client, runner, Options{Symbols: symbols, Depth: 5}), maker
+}
+
+func TestKeysSteerTheStrategy(t *testing.T) {
+	client := tradertest.NewExchange()
+	d, maker := newDashboard(t, client, "BTCUSDT", "ETHUSDT")
+
+	d.handleKey('p')
+	if !maker.Paused {
+		t.Error("expected p to pause the market maker")
+	}
+
+	d.handleKey('p')
+	if maker.Paused {
+		t.Error("expected a second p to resume the market maker")
+	}
+
+	d.handleKey('+')
+	if maker.Spread.String() != "0.03" {
+		t.Errorf("expected the spread to widen to 0.03, got %s", maker.Spread)
+	}
+
+	d.handleKey('-')
+	d.handleKey('-')
+	d.handleKey('-')
+	if maker.Spread.String() != "0.01" {
+		t.Errorf("expected the spread to stay positive at 0.01, got %s", maker.Spread)
+	}
+
+	if !strings.Contains(d.status, "Spread unchanged") {
+		t.Errorf("expected the rejected change to be reported, got %q", d.status)
+	}
+
+	d.handleKey('\t')
+	if d.symbol() != "ETHUSDT" {
+		t.Errorf("expected tab to move to ETHUSDT, got %s", d.symbol())
+	}
+
+	d.handleKey('p')
+	if !strings.Contains(d.status, "No strategy that can pause is trading ETHUSDT") {
+		t.Errorf("unexpected status: %q", d.status)
+	}
+
+	if !d.handleKey('q') {
+		t.Error("expected q to quit")
+	}
+}
+
+func TestCancelAllNeedsConfirmation(t *testing.T) {
+	client := tradertest.NewExchange()
+	d, maker := newDashboard(t, client, "BTCUSDT")
+
+	d.handleKey('c')
+	d.handleKey('x')
+	d.handleKey('c')
+	if len(client.Canceled) != 0 {
+		t.Fatalf("expected another key to abandon the confirmation, got %v", client.Canceled)
+	}
+
+	d.handleKey('c')
+	if len(client.Canceled) != 1 || client.Canceled[0] != "BTCUSDT" {
+		t.Fatalf("expected one cancel all on BTCUSDT, got %v", client.Canceled)
+	}
+
+	if !maker.Paused {
+		t.Error("expected the market maker to be paused so it does not quote again")
+	}
+}
+
+func TestRenderHighlightsOurOrders(t *testing.T) {
+	client := tradertest.NewExchange()
+	client.Manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 7, Side: "SELL", Type: "LIMIT", Price: "102", OrigQty: "0.5", ExecutedQty: "0", Status: "NEW"})
+	client.Manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 6, Side: "BUY", Type: "LIMIT", Price: "98", OrigQty: "1", ExecutedQty: "1", CummulativeQuoteQty: "98", Status: "FILLED"})
+
+	logs := NewLogBuffer(10)
+	logs.Write([]byte("first\nsecond\n"))
+
+	d, _ := newDashboard(t, client, "BTCUSDT")
+	d.options.Logs = logs
+	d.refresh()
+
+	frame := render(d.snapshot(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)), 120, 40)
+
+	for _, want := range []string{
+		"BTCUSDT (1/1)",
+		"market maker quoting, spread 0.02%",
+		"03:04:05",
+		styleRed + styleReverse + "ASK                 102                  4                0.5",
+		"MID                 100   spread 2",
+		// Bought 1 at 98, marked at 100
+		"POSITION  base 1  quote -98  fills 1  PnL 2 at mid 100",
+		"second",
+		"[q] quit",
+	} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("expected %q in frame:\n%s", want, frame)
+		}
+	}
+
+	// The filled order is listed but no longer highlighted in the ladder
+	if strings.Contains(frame, styleGreen+styleReverse) {
+		t.Errorf("expected no highlighted bid:\n%s", frame)
+	}
+}
+
+func TestRenderFitsTheScreen(t *testing.T) {
+	client := tradertest.NewExchange()
+	client.Failing["DOWNUSDT"] = true
+	d, _ := newDashboard(t, client, "DOWNUSDT")
+	d.refresh()
+
+	frame := render(d.snapshot(time.Now()), 30, 8)
+
+	if rows := strings.Count(frame, "\r\n") + 1; rows != 8 {
+		t.Errorf("expected 8 rows, got %d", rows)
+	}
+
+	if !strings.Contains(frame, "[p] pause/resume") {
+		t.Errorf("expected the key help to survive a small screen:\n%q", frame)
+	}
+
+	d.mu.Lock()
+	err := d.bookErr
+	d.mu.Unlock()
+	if err == nil {
+		t.Error("expected the orderbook failure to be kept for display")
+	}
+}
+
+func TestLogBufferKeepsRecentLines(t *testing.T) {
+	logs := NewLogBuffer(2)
+
+	logs.Write([]byte("one\ntw"))
+	logs.Write([]byte("o\nthree\n"))
+
+	if got := strings.Join(logs.Lines(5), ","); got != "two,three" {
+		t.Errorf("expected the last two complete lines, got %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package dashboard
+
+import (
+	"strings"
+	"sync"
+)
+
+// Keep the most recent log lines so they can be shown in a pane instead of
+// scrolling over the screen. Safe to use as the output of the log package.
+type LogBuffer struct {
+	lines   []string
+	size    int
+	partial string // Text after the last newline
+	mu      sync.Mutex
+}
+
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{size: size}
+}
+
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	text := b.partial + string(p)
+	lines := strings.Split(text, "\n")
+
+	// The last element is empty when the write ended with a newline
+	b.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		b.lines = append(b.lines, line)
+	}
+
+	if over := len(b.lines) - b.size; over > 0 {
+		b.lines = append([]string(nil), b.lines[over:]...)
+	}
+
+	return len(p), nil
+}
+
+// Up to n of the most recent complete lines, oldest first
+func (b *LogBuffer) Lines(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start := max(len(b.lines)-n, 0)
+	return append([]string(nil), b.lines[start:]...)
+}
@@ -0,0 +1,300 @@
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+)
+
+// ANSI escape sequences
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleGreen   = "\x1b[32m"
+	styleYellow  = "\x1b[33m"
+
+	enterScreen = "\x1b[?1049h\x1b[?25l" // Alternate screen, hidden cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+	home        = "\x1b[H"
+)
+
+// Most orders listed in the orders table
+const maxOrderRows = 8
+
+// Everything shown in one frame
+type view struct {
+	time        time.Time
+	symbol      string
+	symbolIndex int
+	symbolCount int
+	book        *models.ParsedOrderBook
+	bookErr     error
+	depth       int
+	orders      []models.Order // Orders tracked on the symbol this session
+	position    ordermanager.Position
+	strategy    string // Strategy state for the header
+	connections []api.ConnectionStats
+	logs        []string
+	status      string // Result of the last key action
+	dryRun      bool
+}
+
+// Line of text and the style to draw it in
+type line struct {
+	text  string
+	style string
+}
+
+func plain(format string, args ...any) line {
+	return line{text: fmt.Sprintf(format, args...)}
+}
+
+func styled(style string, format string, args ...any) line {
+	return line{text: fmt.Sprintf(format, args...), style: style}
+}
+
+// Draw a frame that fills width by height cells. Logs take whatever rows the
+// other sections leave; the status and key help always stay at the bottom.
+func render(v view, width, height int) string {
+	var top []line
+
+	title := "BINANCE TRADER"
+	if v.dryRun {
+		title += " [DRY RUN]"
+	}
+
+	top = append(top, styled(styleBold+styleReverse, " %s  %s (%d/%d)  %s  %s ", title,
+		v.symbol, v.symbolIndex+1, v.symbolCount, v.strategy, v.time.Format("15:04:05")))
+	top = append(top, connectionLines(v)...)
+	top = append(top, line{})
+	top = append(top, ladderLines(v)...)
+	top = append(top, line{})
+	top = append(top, positionLine(v))
+	top = append(top, line{})
+	top = append(top, orderLines(v.orders)...)
+	top = append(top, line{})
+
+	bottom := []line{
+		styled(styleYellow, "%s", v.status),
+		styled(styleDim, "[p] pause/resume  [+/-] spread  [c] cancel all  [tab] next symbol  [q] quit"),
+	}
+
+	var lines []line
+	if room := height - len(top) - len(bottom); room > 1 {
+		logs := v.logs
+		if len(logs) > room-1 {
+			logs = logs[len(logs)-(room-1):]
+		}
+
+		lines = append(top, styled(styleBold, "LOGS"))
+		for _, l := range logs {
+			lines = append(lines, styled(styleDim, "%s", l))
+		}
+		for len(lines) < height-len(bottom) {
+			lines = append(lines, line{})
+		}
+	} else {
+		lines = top[:max(height-len(bottom), 0)]
+	}
+
+	lines = append(lines, bottom...)
+
+	var b strings.Builder
+	b.WriteString(home)
+	for i, l := range lines {
+		if i > 0 {
+			// Raw mode does not turn a newline into a carriage return
+			b.WriteString("\r\n")
+		}
+
+		text := truncate(l.text, width)
+		if l.style != "" && text != "" {
+			text = l.style + text + styleReset
+		}
+
+		b.WriteString(text)
+		b.WriteString(clearLine)
+	}
+	b.WriteString(clearBelow)
+
+	return b.String()
+}
+
+func connectionLines(v view) []line {
+	parts := make([]string, 0, len(v.connections))
+	style := ""
+
+	for _, c := range v.connections {
+		part := fmt.Sprintf("%s#%d %s (ping %s)", c.Class, c.Index, formatLatency(c.RequestLatency), formatLatency(c.HeartbeatLatency))
+		if c.ConsecutiveFailures > 0 {
+			part += fmt.Sprintf(" %d failures", c.ConsecutiveFailures)
+			style = styleYellow
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, "n/a")
+	}
+
+	lines := []line{styled(style, "CONNECTIONS  %s", strings.Join(parts, "  "))}
+	if v.bookErr != nil {
+		lines = append(lines, styled(styleRed, "Orderbook unavailable: %v", v.bookErr))
+	}
+
+	return lines
+}
+
+// Asks above bids, with the levels we are quoting at highlighted
+func ladderLines(v view) []line {
+	lines := []line{styled(styleBold, "%-4s %18s %18s %18s", "", "PRICE", "QUANTITY", "OURS")}
+
+	if v.book == nil {
+		return append(lines, styled(styleDim, "waiting for the orderbook..."))
+	}
+
+	asks := v.book.Asks[:min(len(v.book.Asks), v.depth)]
+	bids := v.book.Bids[:min(len(v.book.Bids), v.depth)]
+
+	for i := len(asks) - 1; i >= 0; i-- {
+		lines = append(lines, levelLine("ASK", asks[i], ours(v.orders, "SELL", asks[i].Price), styleRed))
+	}
+
+	if len(asks) > 0 && len(bids) > 0 {
+		spread := asks[0].Price.Sub(bids[0].Price)
+		mid := asks[0].Price.Add(bids[0].Price).Div(decimal.NewFromInt(2), decimal.Nearest)
+		lines = append(lines, styled(styleDim, "%-4s %18s   spread %s", "MID", mid, spread))
+	}
+
+	for _, bid := range bids {
+		lines = append(lines, levelLine("BID", bid, ours(v.orders, "BUY", bid.Price), styleGreen))
+	}
+
+	return lines
+}
+
+func levelLine(label string, level models.PriceLevel, own decimal.Decimal, style string) line {
+	ownText := ""
+	if !own.IsZero() {
+		style += styleReverse
+		ownText = own.String()
+	}
+
+	return styled(style, "%-4s %18s %18s %18s", label, level.Price, level.Quantity, ownText)
+}
+
+// Open quantity of our active orders on one side at a price
+func ours(orders []models.Order, side string, price decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+
+	for _, order := range orders {
+		if order.Side != side || !isActive(order) {
+			continue
+		}
+
+		orderPrice, err := decimal.NewFromString(order.Price)
+		if err != nil || !orderPrice.Equal(price) {
+			continue
+		}
+
+		orig, _ := decimal.NewFromString(order.OrigQty)
+		executed, _ := decimal.NewFromString(order.ExecutedQty)
+		total = total.Add(orig.Sub(executed))
+	}
+
+	return total
+}
+
+func positionLine(v view) line {
+	p := v.position
+
+	if v.book == nil || len(v.book.Bids) == 0 || len(v.book.Asks) == 0 {
+		return plain("POSITION  base %s  quote %s  fills %d", p.Base, p.Quote, p.Fills)
+	}
+
+	mid := v.book.Asks[0].Price.Add(v.book.Bids[0].Price).Div(decimal.NewFromInt(2), decimal.Nearest)
+	pnl := p.PnL(mid)
+
+	style := ""
+	switch pnl.Sign() {
+	case 1:
+		style = styleGreen
+	case -1:
+		style = styleRed
+	}
+
+	return styled(style, "POSITION  base %s  quote %s  fills %d  PnL %s at mid %s", p.Base, p.Quote, p.Fills, pnl, mid)
+}
+
+// Active orders first, then filled ones, newest first within each
+func orderLines(orders []models.Order) []line {
+	var shown []models.Order
+	for _, order := range orders {
+		if isActive(order) || models.OrderStatus(order.Status) == models.OrderStatusFilled {
+			shown = append(shown, order)
+		}
+	}
+
+	sort.Slice(shown, func(i, j int) bool {
+		if isActive(shown[i]) != isActive(shown[j]) {
+			return isActive(shown[i])
+		}
+		return shown[i].OrderID > shown[j].OrderID
+	})
+
+	lines := []line{styled(styleBold, "%-12s %-5s %-7s %18s %18s %18s  %s", "ORDER ID", "SIDE", "TYPE", "PRICE", "QUANTITY", "EXECUTED", "STATUS")}
+	if len(shown) == 0 {
+		return append(lines, styled(styleDim, "no open or filled orders"))
+	}
+
+	for i, order := range shown {
+		if i == maxOrderRows {
+			lines = append(lines, styled(styleDim, "... %d more", len(shown)-maxOrderRows))
+			break
+		}
+
+		style := ""
+		if isActive(order) {
+			style = styleBold
+		}
+
+		lines = append(lines, styled(style, "%-12d %-5s %-7s %18s %18s %18s  %s",
+			order.OrderID, order.Side, order.Type, order.Price, order.OrigQty, order.ExecutedQty, order.Status))
+	}
+
+	return lines
+}
+
+func isActive(order models.Order) bool {
+	status := models.OrderStatus(order.Status)
+	return status == models.OrderStatusNew || status == models.OrderStatusPartiallyFilled
+}
+
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// Cut text to at most width characters
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	return string(runes[:max(width, 0)])
+}
@@ -0,0 +1,468 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Number of decimal places carried by every Decimal.
+// Binance quotes prices, quantities and balances with at most 8 places.
+const Places = 8
+
+// Units in one whole number
+const scale = 100_000_000
+
+// Fixed-point number with 8 decimal places, from about -1.7e30 to 1.7e30,
+// enough for any volume or cumulative notional the exchange reports.
+// The zero value is 0 and is ready to use. Arithmetic that leaves the range
+// panics rather than wrapping around.
+type Decimal struct {
+	units int128 // Value multiplied by 10^Places
+}
+
+// How to round a value that falls between two representable values
+type RoundingMode int
+
+const (
+	Floor   RoundingMode = iota // Towards negative infinity
+	Ceil                        // Towards positive infinity
+	Nearest                     // To the closest value, halves away from zero
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case Floor:
+		return "floor"
+	case Ceil:
+		return "ceil"
+	case Nearest:
+		return "nearest"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+var Zero = Decimal{}
+
+// Decimal equal to value * 10^exp, e.g. New(5, -1) is 0.5.
+// Digits beyond 8 decimal places are rounded to nearest.
+func New(value int64, exp int) Decimal {
+	n := new(big.Int).Mul(big.NewInt(value), big.NewInt(scale))
+
+	if exp >= 0 {
+		return Decimal{units: toUnits(n.Mul(n, pow10(exp)))}
+	}
+
+	return Decimal{units: toUnits(divRound(n, pow10(-exp), Nearest))}
+}
+
+// Decimal equal to a whole number
+func NewFromInt(value int64) Decimal {
+	return Decimal{units: toUnits(new(big.Int).Mul(big.NewInt(value), big.NewInt(scale)))}
+}
+
+// Decimal closest to a float, for values that are not money on the wire
+// such as configuration percentages. Prefer NewFromString for prices.
+// Floats that are not finite or out of range give zero.
+func NewFromFloat(value float64) Decimal {
+	d, _ := NewFromString(strconv.FormatFloat(value, 'f', Places, 64))
+	return d
+}
+
+// Parse a plain decimal string such as "-123.45000000"
+func NewFromString(s string) (Decimal, error) {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return Zero, fmt.Errorf("invalid decimal %q: empty", s)
+	}
+
+	negative := false
+	switch text[0] {
+	case '-':
+		negative = true
+		text = text[1:]
+	case '+':
+		text = text[1:]
+	}
+
+	whole, frac, _ := strings.Cut(text, ".")
+	if whole == "" && frac == "" {
+		return Zero, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	// Extra places are only allowed when they are zero
+	if len(frac) > Places {
+		if strings.Trim(frac[Places:], "0") != "" {
+			return Zero, fmt.Errorf("invalid decimal %q: more than %d decimal places", s, Places)
+		}
+		frac = frac[:Places]
+	}
+
+	digits := whole + frac + strings.Repeat("0", Places-len(frac))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Zero, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+
+	n, _ := new(big.Int).SetString(digits, 10)
+	if negative {
+		n.Neg(n)
+	}
+
+	units, ok := fromBig(n)
+	if !ok {
+		return Zero, fmt.Errorf("invalid decimal %q: out of range", s)
+	}
+
+	return Decimal{units: units}, nil
+}
+
+// Parse a decimal string, panicking if it is invalid.
+// Intended for constants and tests.
+func RequireFromString(s string) Decimal {
+	d, err := NewFromString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	sum, overflow := d.units.add(other.units)
+	if overflow {
+		panic(errOverflow)
+	}
+
+	return Decimal{units: sum}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	difference, overflow := d.units.sub(other.units)
+	if overflow {
+		panic(errOverflow)
+	}
+
+	return Decimal{units: difference}
+}
+
+// Product rounded to nearest
+func (d Decimal) Mul(other Decimal) Decimal {
+	n := new(big.Int).Mul(d.units.big(), other.units.big())
+	return Decimal{units: toUnits(divRound(n, big.NewInt(scale), Nearest))}
+}
+
+// Quotient rounded with the given mode. Panics when dividing by zero.
+func (d Decimal) Div(other Decimal, mode RoundingMode) Decimal {
+	if other.IsZero() {
+		panic("decimal: division by zero")
+	}
+
+	n := new(big.Int).Mul(d.units.big(), big.NewInt(scale))
+	return Decimal{units: toUnits(divRound(n, other.units.big(), mode))}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Zero.Sub(d)
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.IsNegative() {
+		return d.Neg()
+	}
+
+	return d
+}
+
+// Round to a multiple of step, e.g. a price filter's tickSize or a lot size's stepSize.
+// A zero or negative step leaves the value unchanged.
+func (d Decimal) RoundToStep(step Decimal, mode RoundingMode) Decimal {
+	if !step.IsPositive() {
+		return d
+	}
+
+	steps := divRound(d.units.big(), step.units.big(), mode)
+	return Decimal{units: toUnits(steps.Mul(steps, step.units.big()))}
+}
+
+// Round to a number of decimal places
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	if places >= Places {
+		return d
+	}
+
+	return d.RoundToStep(New(1, -places), mode)
+}
+
+// -1, 0 or +1 as d is less than, equal to or greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	return d.units.cmp(other.units)
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.units == other.units
+}
+
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// -1, 0 or +1 for negative, zero and positive values
+func (d Decimal) Sign() int {
+	return d.units.cmp(int128{})
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == int128{}
+}
+
+func (d Decimal) IsPositive() bool {
+	return d.Sign() > 0
+}
+
+func (d Decimal) IsNegative() bool {
+	return d.Sign() < 0
+}
+
+func Min(a, b Decimal) Decimal {
+	if a.LessThan(b) {
+		return a
+	}
+
+	return b
+}
+
+func Max(a, b Decimal) Decimal {
+	if a.GreaterThan(b) {
+		return a
+	}
+
+	return b
+}
+
+// Nearest float, for display and statistics only
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Number of significant decimal places, e.g. 2 for a tickSize of "0.01000000"
+func (d Decimal) DecimalPlaces() int {
+	places := Places
+	for frac := d.units.fraction(); places > 0 && frac%10 == 0; frac /= 10 {
+		places--
+	}
+
+	return places
+}
+
+// Shortest exact representation, e.g. "0.3" or "-12"
+func (d Decimal) String() string {
+	return d.StringFixed(d.DecimalPlaces())
+}
+
+// Representation with exactly places decimal places, rounding to nearest if needed
+func (d Decimal) StringFixed(places int) string {
+	places = max(0, min(places, Places))
+	rounded := d.Round(places, Nearest)
+
+	sign := ""
+	if rounded.IsNegative() {
+		sign = "-"
+	}
+
+	whole, frac := new(big.Int).QuoRem(rounded.units.big(), big.NewInt(scale), new(big.Int))
+	whole.Abs(whole)
+	if places == 0 {
+		return fmt.Sprintf("%s%s", sign, whole)
+	}
+
+	digits := fmt.Sprintf("%0*d", Places, frac.Abs(frac))
+	return fmt.Sprintf("%s%s.%s", sign, whole, digits[:places])
+}
+
+// Format to the precision of a step, as the exchange expects on the wire
+func (d Decimal) StringStep(step Decimal) string {
+	return d.StringFixed(step.DecimalPlaces())
+}
+
+// Encode as a JSON string, matching how Binance sends numbers
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Decode from a JSON string or number
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	parsed, err := NewFromString(text)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := NewFromString(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Panic value for results outside the range of a Decimal
+var errOverflow = errors.New("decimal: overflow")
+
+// Units of an intermediate result, panicking when it is out of range
+func toUnits(n *big.Int) int128 {
+	units, ok := fromBig(n)
+	if !ok {
+		panic(errOverflow)
+	}
+
+	return units
+}
+
+// Divide n by divisor, rounding the result with mode
+func divRound(n, divisor *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(n, divisor, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// Sign of the exact result; QuoRem truncates towards zero
+	negative := (n.Sign() < 0) != (divisor.Sign() < 0)
+
+	switch mode {
+	case Floor:
+		if negative {
+			quotient.Sub(quotient, big.NewInt(1))
+		}
+
+	case Ceil:
+		if !negative {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+
+	case Nearest:
+		twice := new(big.Int).Abs(remainder)
+		twice.Lsh(twice, 1)
+
+		if twice.CmpAbs(divisor) >= 0 {
+			if negative {
+				quotient.Sub(quotient, big.NewInt(1))
+			} else {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+
+	return quotient
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+// Signed 128-bit integer in two's complement, value hi*2^64 + lo. Comparable
+// with ==, and the zero value is 0.
+type int128 struct {
+	hi int64
+	lo uint64
+}
+
+var (
+	maxInt128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	minInt128 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	lowBits   = new(big.Int).SetUint64(^uint64(0))
+)
+
+// x + y, and whether the sum left the range
+func (x int128) add(y int128) (int128, bool) {
+	lo, carry := bits.Add64(x.lo, y.lo, 0)
+	sum := int128{hi: x.hi + y.hi + int64(carry), lo: lo}
+
+	// Overflow wraps to the opposite sign of two like-signed operands
+	return sum, (x.hi < 0) == (y.hi < 0) && (sum.hi < 0) != (x.hi < 0)
+}
+
+// x - y, and whether the difference left the range
+func (x int128) sub(y int128) (int128, bool) {
+	lo, borrow := bits.Sub64(x.lo, y.lo, 0)
+	difference := int128{hi: x.hi - y.hi - int64(borrow), lo: lo}
+
+	return difference, (x.hi < 0) != (y.hi < 0) && (difference.hi < 0) != (x.hi < 0)
+}
+
+func (x int128) cmp(y int128) int {
+	switch {
+	case x.hi < y.hi:
+		return -1
+	case x.hi > y.hi:
+		return 1
+	case x.lo < y.lo:
+		return -1
+	case x.lo > y.lo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Units below one whole number, of the absolute value
+func (x int128) fraction() uint64 {
+	// Fast path for values that fit in an int64
+	if x.hi == int64(x.lo)>>63 {
+		v := int64(x.lo)
+		if v < 0 {
+			return uint64(-(v % scale))
+		}
+		return uint64(v % scale)
+	}
+
+	frac := new(big.Int).Rem(x.big(), big.NewInt(scale))
+	return frac.Abs(frac).Uint64()
+}
+
+func (x int128) big() *big.Int {
+	n := big.NewInt(x.hi)
+	n.Lsh(n, 64)
+	return n.Add(n, new(big.Int).SetUint64(x.lo))
+}
+
+// n as an int128, and whether it is in range
+func fromBig(n *big.Int) (int128, bool) {
+	if n.Cmp(maxInt128) > 0 || n.Cmp(minInt128) < 0 {
+		return int128{}, false
+	}
+
+	// And and Rsh treat negative values as two's complement
+	lo := new(big.Int).And(n, lowBits).Uint64()
+	hi := new(big.Int).Rsh(n, 64).Int64()
+	return int128{hi: hi, lo: lo}, true
+}
@@ -0,0 +1,234 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestNewFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "whole number", input: "123", want: "123"},
+		{name: "binance price", input: "40000.00000000", want: "40000"},
+		{name: "smallest unit", input: "0.00000001", want: "0.00000001"},
+		{name: "negative", input: "-1.5", want: "-1.5"},
+		{name: "leading dot", input: ".25", want: "0.25"},
+		{name: "trailing zeros past 8 places", input: "1.1000000000", want: "1.1"},
+		{name: "too many places", input: "0.000000001", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+		{name: "not a number", input: "abc", wantErr: true},
+		{name: "exponent", input: "1e5", wantErr: true},
+		{name: "beyond int64 units", input: "123456789012345.5", want: "123456789012345.5"},
+		{name: "out of range", input: "10000000000000000000000000000000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFromString(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("NewFromString(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	sum := RequireFromString("0.1").Add(RequireFromString("0.2"))
+	if sum.String() != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", sum)
+	}
+
+	notional := RequireFromString("40123.45").Mul(RequireFromString("0.00123"))
+	if notional.String() != "49.3518435" {
+		t.Errorf("40123.45 * 0.00123 = %s, want 49.3518435", notional)
+	}
+
+	// Products too large for int64 units must not overflow in between
+	big := RequireFromString("90000").Mul(RequireFromString("1000"))
+	if big.String() != "90000000" {
+		t.Errorf("90000 * 1000 = %s, want 90000000", big)
+	}
+
+	// Volumes and notionals well beyond int64 units
+	volume := RequireFromString("98765432109876.54321").Add(RequireFromString("12345678901234.5"))
+	if volume.String() != "111111111011111.04321" {
+		t.Errorf("volume sum = %s, want 111111111011111.04321", volume)
+	}
+	if notional := volume.Mul(RequireFromString("0.00001234")); notional.String() != "1371111109.87711027" {
+		t.Errorf("volume * price = %s, want 1371111109.87711027", notional)
+	}
+	if negative := volume.Neg(); negative.String() != "-111111111011111.04321" || !negative.Abs().Equal(volume) {
+		t.Errorf("-volume = %s", negative)
+	}
+
+	third := NewFromInt(1).Div(NewFromInt(3), Floor)
+	if third.String() != "0.33333333" {
+		t.Errorf("1 / 3 = %s, want 0.33333333", third)
+	}
+
+	if got := NewFromInt(2).Div(NewFromInt(3), Ceil); got.String() != "0.66666667" {
+		t.Errorf("2 / 3 rounded up = %s, want 0.66666667", got)
+	}
+}
+
+func TestOverflowPanics(t *testing.T) {
+	largest := Decimal{units: int128{hi: math.MaxInt64, lo: math.MaxUint64}}
+	smallest := Decimal{units: int128{hi: math.MinInt64}}
+	unit := Decimal{units: int128{lo: 1}}
+
+	if got := largest.String(); got != "1701411834604692317316873037158.84105727" {
+		t.Errorf("largest = %s", got)
+	}
+	if got := smallest.String(); got != "-1701411834604692317316873037158.84105728" {
+		t.Errorf("smallest = %s", got)
+	}
+
+	// Results at the edge of the range are exact
+	if got := largest.Sub(unit).Add(unit); !got.Equal(largest) {
+		t.Errorf("largest - unit + unit = %s, want %s", got, largest)
+	}
+	if got := smallest.Add(unit).Sub(unit); !got.Equal(smallest) {
+		t.Errorf("smallest + unit - unit = %s, want %s", got, smallest)
+	}
+	if got := NewFromInt(math.MinInt64); got.String() != "-9223372036854775808" {
+		t.Errorf("NewFromInt(smallest int64) = %s", got)
+	}
+
+	tests := []struct {
+		name string
+		op   func() Decimal
+	}{
+		{"add", func() Decimal { return largest.Add(unit) }},
+		{"add negative", func() Decimal { return smallest.Add(unit.Neg()) }},
+		{"sub", func() Decimal { return smallest.Sub(unit) }},
+		{"sub negative", func() Decimal { return largest.Sub(unit.Neg()) }},
+		{"mul", func() Decimal { return NewFromInt(1e16).Mul(NewFromInt(1e15)) }},
+		{"div", func() Decimal { return largest.Div(RequireFromString("0.5"), Floor) }},
+		{"new", func() Decimal { return New(1, 31) }},
+		{"neg", func() Decimal { return smallest.Neg() }},
+		{"round up to step", func() Decimal { return largest.RoundToStep(NewFromInt(1), Ceil) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic instead of a wrapped result")
+				}
+			}()
+
+			t.Errorf("expected overflow, got %s", tt.op())
+		})
+	}
+}
+
+func TestRoundToStep(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		step  string
+		mode  RoundingMode
+		want  string
+	}{
+		{name: "floor", value: "123.459", step: "0.01", mode: Floor, want: "123.45"},
+		{name: "ceil", value: "123.451", step: "0.01", mode: Ceil, want: "123.46"},
+		{name: "nearest half", value: "123.455", step: "0.01", mode: Nearest, want: "123.46"},
+		{name: "nearest below half", value: "123.454", step: "0.01", mode: Nearest, want: "123.45"},
+		{name: "already aligned", value: "123.45", step: "0.01", mode: Ceil, want: "123.45"},
+		{name: "step larger than one", value: "1234", step: "5", mode: Floor, want: "1230"},
+		{name: "floor negative", value: "-1.25", step: "0.1", mode: Floor, want: "-1.3"},
+		{name: "ceil negative", value: "-1.25", step: "0.1", mode: Ceil, want: "-1.2"},
+		{name: "nearest negative half", value: "-1.25", step: "0.1", mode: Nearest, want: "-1.3"},
+		{name: "zero step", value: "1.23456789", step: "0", mode: Floor, want: "1.23456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RequireFromString(tt.value).RoundToStep(RequireFromString(tt.step), tt.mode)
+			if got.String() != tt.want {
+				t.Errorf("RoundToStep(%s, %s, %s) = %s, want %s", tt.value, tt.step, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringFixed(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{value: "8959.5", places: 2, want: "8959.50"},
+		{value: "0.12345678", places: 8, want: "0.12345678"},
+		{value: "1.005", places: 2, want: "1.01"},
+		{value: "-0.5", places: 0, want: "-1"},
+		{value: "-0.004", places: 2, want: "0.00"},
+		{value: "42", places: 3, want: "42.000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := RequireFromString(tt.value).StringFixed(tt.places); got != tt.want {
+				t.Errorf("StringFixed(%s, %d) = %s, want %s", tt.value, tt.places, got, tt.want)
+			}
+		})
+	}
+
+	step := RequireFromString("0.00100000")
+	if got := RequireFromString("0.5").StringStep(step); got != "0.500" {
+		t.Errorf("StringStep(0.5, 0.001) = %s, want 0.500", got)
+	}
+}
+
+func TestNewFromFloat(t *testing.T) {
+	if got := NewFromFloat(0.1 + 0.2); got.String() != "0.3" {
+		t.Errorf("NewFromFloat(0.1 + 0.2) = %s, want 0.3", got)
+	}
+
+	if got := New(5, -1); got.String() != "0.5" {
+		t.Errorf("New(5, -1) = %s, want 0.5", got)
+	}
+
+	if got := New(12, 3); got.String() != "12000" {
+		t.Errorf("New(12, 3) = %s, want 12000", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var level struct {
+		Price    Decimal `json:"price"`
+		Quantity Decimal `json:"quantity"`
+		Missing  Decimal `json:"missing"`
+	}
+
+	data := []byte(`{"price":"40000.01000000","quantity":1.5,"missing":null}`)
+	if err := json.Unmarshal(data, &level); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if level.Price.String() != "40000.01" || level.Quantity.String() != "1.5" || !level.Missing.IsZero() {
+		t.Errorf("unexpected values: %s %s %s", level.Price, level.Quantity, level.Missing)
+	}
+
+	out, err := json.Marshal(level)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	if string(out) != `{"price":"40000.01","quantity":"1.5","missing":"0"}` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":"abc"}`), &level); err == nil {
+		t.Error("expected an error for an invalid price")
+	}
+}
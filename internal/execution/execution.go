@@ -0,0 +1,479 @@
+// Package execution works a large parent order into the market as a series
+// of smaller child orders.
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+// Most trades trades.recent returns, bounding the market volume one slice can see
+const recentTradesLimit = 1000
+
+// How child order sizes are scheduled
+type Algorithm string
+
+const (
+	TWAP Algorithm = "twap" // Equal sizes in every slice
+	VWAP Algorithm = "vwap" // Sizes following the volume traded in the same window on earlier days
+	POV  Algorithm = "pov"  // A share of the volume the market traded in the last slice
+)
+
+// How child orders are priced
+type Style string
+
+const (
+	Passive    Style = "passive"    // Join the touch on the order's own side and wait to be filled
+	Aggressive Style = "aggressive" // Cross to the opposite touch to be filled now
+)
+
+// Exchange operations an execution needs
+type Exchange interface {
+	GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error)
+	PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error)
+	CancelOrder(symbol string, orderID int64) (*models.Order, error)
+	GetOrderStatus(symbol string, orderID int64) (*models.Order, error)
+	GetRecentTrades(symbol string, limit int) ([]models.MarketTrade, error)
+	GetKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error)
+}
+
+// Order to work into the market
+type ParentOrder struct {
+	Symbol     string
+	Side       string          // BUY or SELL
+	Quantity   decimal.Decimal // Total base quantity
+	Duration   time.Duration   // Time to work the order over
+	LimitPrice decimal.Decimal // Worst price any child may trade at, zero for none
+}
+
+// How the parent order is sliced and priced
+type Params struct {
+	Algorithm        Algorithm
+	Style            Style
+	Interval         time.Duration   // Longest slice; one child order rests per slice
+	Participation    decimal.Decimal // POV share of market volume, e.g. 0.1 for 10%
+	MaxParticipation decimal.Decimal // Cap on any child as a share of the last slice's market volume, zero for none
+	ProfileDays      int             // Earlier days averaged into the VWAP volume profile
+	TickSize         string
+	StepSize         string
+	MinNotional      decimal.Decimal // Children worth less wait for a later slice, zero for none
+}
+
+// Outcome of an execution
+type Report struct {
+	Symbol       string          `json:"symbol"`
+	Side         string          `json:"side"`
+	Algorithm    Algorithm       `json:"algorithm"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	Executed     decimal.Decimal `json:"executed"`
+	Remaining    decimal.Decimal `json:"remaining"`
+	AveragePrice decimal.Decimal `json:"averagePrice"` // Zero when nothing executed
+	ArrivalPrice decimal.Decimal `json:"arrivalPrice"` // Mid price when the execution started
+	SlippageBps  float64         `json:"slippageBps"`  // Average price against arrival, positive when worse
+	Children     int             `json:"children"`
+	Elapsed      time.Duration   `json:"elapsed"`
+}
+
+// Works one parent order. Not safe for concurrent use.
+type Executor struct {
+	exchange Exchange
+	parent   ParentOrder
+	params   Params
+	slices   int
+	slice    time.Duration // Length of every slice, at most the interval
+	weights  []float64     // Share of the parent order per slice, nil for POV
+	now      func() time.Time
+	wait     func(ctx context.Context, until time.Time) error
+
+	child    *models.Order   // Child order of the current slice, nil when none rests
+	executed decimal.Decimal // Base quantity filled by settled children
+	quote    decimal.Decimal // Quote quantity filled by settled children
+	children int
+}
+
+// Check the parent order and parameters before anything is sent, every
+// problem at once
+func (p Params) Validate(parent ParentOrder) error {
+	var errs []error
+
+	if parent.Side != "BUY" && parent.Side != "SELL" {
+		errs = append(errs, fmt.Errorf("side must be BUY or SELL, got %q", parent.Side))
+	}
+	if !parent.Quantity.IsPositive() {
+		errs = append(errs, fmt.Errorf("quantity must be positive, got %s", parent.Quantity))
+	}
+	if parent.LimitPrice.IsNegative() {
+		errs = append(errs, fmt.Errorf("limit price must not be negative, got %s", parent.LimitPrice))
+	}
+	if p.Interval <= 0 || parent.Duration < p.Interval {
+		errs = append(errs, fmt.Errorf("duration %s must cover at least one interval of %s", parent.Duration, p.Interval))
+	}
+
+	switch p.Algorithm {
+	case TWAP, VWAP:
+	case POV:
+		if !p.Participation.IsPositive() || p.Participation.GreaterThan(decimal.NewFromInt(1)) {
+			errs = append(errs, fmt.Errorf("pov participation must be above 0 and at most 1, got %s", p.Participation))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown algorithm %q, use twap, vwap or pov", p.Algorithm))
+	}
+
+	if p.Style != Passive && p.Style != Aggressive {
+		errs = append(errs, fmt.Errorf("unknown style %q, use passive or aggressive", p.Style))
+	}
+	if p.MaxParticipation.IsNegative() || p.MaxParticipation.GreaterThan(decimal.NewFromInt(1)) {
+		errs = append(errs, fmt.Errorf("max participation must be from 0 to 1, got %s", p.MaxParticipation))
+	}
+
+	return errors.Join(errs...)
+}
+
+func New(exchange Exchange, parent ParentOrder, params Params) (*Executor, error) {
+	if err := params.Validate(parent); err != nil {
+		return nil, err
+	}
+
+	// A duration that is not a whole number of intervals is cut into one
+	// more, shorter slice each, so the last slice still ends on time
+	slices := int((parent.Duration + params.Interval - 1) / params.Interval)
+
+	return &Executor{
+		exchange: exchange,
+		parent:   parent,
+		params:   params,
+		slices:   slices,
+		slice:    parent.Duration / time.Duration(slices),
+		now:      time.Now,
+		wait:     waitUntil,
+	}, nil
+}
+
+func (e *Executor) logger() *slog.Logger {
+	return slog.With("algorithm", e.params.Algorithm, "symbol", e.parent.Symbol, "side", e.parent.Side)
+}
+
+// Work the parent order until it is filled, its duration ends or ctx is
+// canceled, leaving no child order resting
+func (e *Executor) Run(ctx context.Context) (Report, error) {
+	logger := e.logger()
+	start := e.now()
+
+	book, err := e.exchange.GetOrderbook(e.parent.Symbol, 1)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to get arrival price: %w", err)
+	}
+	arrival, ok := mid(book)
+	if !ok {
+		return Report{}, errors.New("failed to get arrival price: empty orderbook")
+	}
+
+	switch e.params.Algorithm {
+	case TWAP:
+		e.weights = evenWeights(e.slices)
+	case VWAP:
+		e.weights = e.volumeProfile(start)
+	}
+
+	logger.Info("Starting execution", "quantity", e.parent.Quantity, "duration", e.parent.Duration,
+		"slices", e.slices, "slice", e.slice, "style", e.params.Style, "arrivalPrice", arrival)
+
+	for slice := 0; slice < e.slices; slice++ {
+		settleErr := e.settle()
+		if settleErr != nil {
+			logger.Warn("Failed to settle child order", "error", settleErr)
+		}
+
+		if !e.remaining().IsPositive() || ctx.Err() != nil {
+			break
+		}
+
+		// A child that could not be settled may still be resting, so it keeps the slice
+		if settleErr == nil {
+			if err := e.placeChild(slice); err != nil {
+				logger.Warn("Failed to place child order", "slice", slice+1, "error", err)
+			}
+		}
+
+		if err := e.wait(ctx, start.Add(time.Duration(slice+1)*e.slice)); err != nil {
+			break
+		}
+	}
+
+	var settleErr error
+	if err := e.settle(); err != nil {
+		settleErr = fmt.Errorf("child order %d may still be resting: %w", e.child.OrderID, err)
+	}
+
+	report := e.report(arrival, e.now().Sub(start))
+	logger.Info("Execution finished", "executed", report.Executed, "remaining", report.Remaining,
+		"averagePrice", report.AveragePrice, "slippageBps", fmt.Sprintf("%.2f", report.SlippageBps), "children", report.Children)
+
+	return report, settleErr
+}
+
+// Quantity still to execute
+func (e *Executor) remaining() decimal.Decimal {
+	return e.parent.Quantity.Sub(e.executed)
+}
+
+// Size and place the child order for a slice
+func (e *Executor) placeChild(slice int) error {
+	size := e.remaining()
+
+	if e.weights != nil {
+		// Catch up on earlier slices that filled short
+		target := e.parent.Quantity.Mul(decimal.NewFromFloat(cumulative(e.weights, slice+1)))
+		size = decimal.Min(size, target.Sub(e.executed))
+	}
+
+	if e.params.Algorithm == POV || e.params.MaxParticipation.IsPositive() {
+		volume, err := e.marketVolume(e.slice)
+		if err != nil {
+			return err
+		}
+
+		if e.params.Algorithm == POV {
+			size = decimal.Min(size, volume.Mul(e.params.Participation))
+		}
+		if e.params.MaxParticipation.IsPositive() {
+			size = decimal.Min(size, volume.Mul(e.params.MaxParticipation))
+		}
+	}
+
+	quantity, err := decimal.NewFromString(utils.FormatQuantity(size, e.params.StepSize))
+	if err != nil || !quantity.IsPositive() {
+		return nil
+	}
+
+	book, err := e.exchange.GetOrderbook(e.parent.Symbol, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get orderbook: %w", err)
+	}
+
+	price, ok := e.childPrice(book)
+	if !ok {
+		return errors.New("no price on the side the child would trade against")
+	}
+
+	if e.params.MinNotional.IsPositive() && quantity.Mul(price).LessThan(e.params.MinNotional) {
+		e.logger().Debug("Child below the minimum notional, waiting for a larger one", "quantity", quantity, "price", price)
+		return nil
+	}
+
+	priceStr := utils.FormatPrice(price, e.params.TickSize, utils.PriceRounding(e.parent.Side))
+	order, err := e.exchange.PlaceOrder(e.parent.Symbol, e.parent.Side, "LIMIT", priceStr, quantity.String())
+	if err != nil {
+		return err
+	}
+
+	e.child = order
+	e.children++
+	e.logger().Info("Placed child order", "slice", slice+1, "orderId", order.OrderID, "quantity", quantity, "price", priceStr)
+	return nil
+}
+
+// Price of a child order for the style, held to the limit price
+func (e *Executor) childPrice(book *models.ParsedOrderBook) (decimal.Decimal, bool) {
+	ownSide, oppositeSide := book.Bids, book.Asks
+	if e.parent.Side == "SELL" {
+		ownSide, oppositeSide = book.Asks, book.Bids
+	}
+
+	levels := ownSide
+	if e.params.Style == Aggressive {
+		levels = oppositeSide
+	}
+	if len(levels) == 0 {
+		return decimal.Zero, false
+	}
+
+	price := levels[0].Price
+	if limit := e.parent.LimitPrice; limit.IsPositive() {
+		if e.parent.Side == "BUY" {
+			price = decimal.Min(price, limit)
+		} else {
+			price = decimal.Max(price, limit)
+		}
+	}
+
+	return price, true
+}
+
+// Cancel the resting child order and count what it filled
+func (e *Executor) settle() error {
+	if e.child == nil {
+		return nil
+	}
+
+	order, err := e.exchange.GetOrderStatus(e.parent.Symbol, e.child.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if isOpen(order.Status) {
+		canceled, err := e.exchange.CancelOrder(e.parent.Symbol, e.child.OrderID)
+		if err != nil {
+			// It may have filled meanwhile; the next settle looks again
+			return err
+		}
+		order = canceled
+	}
+
+	executed, err := decimal.NewFromString(orZero(order.ExecutedQty))
+	if err != nil {
+		return fmt.Errorf("invalid executed quantity %q: %w", order.ExecutedQty, err)
+	}
+	quote, err := decimal.NewFromString(orZero(order.CummulativeQuoteQty))
+	if err != nil {
+		return fmt.Errorf("invalid quote quantity %q: %w", order.CummulativeQuoteQty, err)
+	}
+
+	e.executed = e.executed.Add(executed)
+	e.quote = e.quote.Add(quote)
+	e.child = nil
+
+	if executed.IsPositive() {
+		e.logger().Info("Child order filled", "orderId", order.OrderID, "executed", executed, "total", e.executed)
+	}
+
+	return nil
+}
+
+// Base volume the market traded over the last window, from recent trades or,
+// when more traded than one request returns, from 1m klines
+func (e *Executor) marketVolume(window time.Duration) (decimal.Decimal, error) {
+	trades, err := e.exchange.GetRecentTrades(e.parent.Symbol, recentTradesLimit)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get recent trades: %w", err)
+	}
+
+	now := e.now()
+	since := now.Add(-window)
+
+	volume := decimal.Zero
+	oldest := now.UnixMilli()
+	for _, trade := range trades {
+		oldest = min(oldest, trade.Time)
+		if trade.Time < since.UnixMilli() {
+			continue
+		}
+
+		quantity, err := decimal.NewFromString(trade.Qty)
+		if err != nil {
+			continue
+		}
+		volume = volume.Add(quantity)
+	}
+
+	// A full page that starts inside the window misses the trades before it
+	if len(trades) >= recentTradesLimit && oldest > since.UnixMilli() {
+		e.logger().Debug("Recent trades do not cover the slice, measuring volume on klines", "window", window)
+		return e.klineVolume(since, now)
+	}
+
+	return volume, nil
+}
+
+// Base volume traded from since to now on 1m klines, counting the share of
+// each candle inside the window
+func (e *Executor) klineVolume(since, now time.Time) (decimal.Decimal, error) {
+	candles, err := e.exchange.GetKlines(e.parent.Symbol, "1m", models.KlineQuery{
+		StartTime: since.Truncate(time.Minute),
+		EndTime:   now,
+		Limit:     klinesLimit,
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get klines: %w", err)
+	}
+
+	volume := decimal.Zero
+	for _, candle := range candles {
+		// The open candle has only traded up to now
+		end := candle.CloseTime.Add(time.Millisecond)
+		if end.After(now) {
+			end = now
+		}
+
+		from := candle.OpenTime
+		if from.Before(since) {
+			from = since
+		}
+
+		length, overlap := end.Sub(candle.OpenTime).Milliseconds(), end.Sub(from).Milliseconds()
+		if length <= 0 || overlap <= 0 {
+			continue
+		}
+
+		share := candle.Volume.Mul(decimal.NewFromInt(overlap)).Div(decimal.NewFromInt(length), decimal.Floor)
+		volume = volume.Add(share)
+	}
+
+	return volume, nil
+}
+
+func (e *Executor) report(arrival decimal.Decimal, elapsed time.Duration) Report {
+	report := Report{
+		Symbol:       e.parent.Symbol,
+		Side:         e.parent.Side,
+		Algorithm:    e.params.Algorithm,
+		Quantity:     e.parent.Quantity,
+		Executed:     e.executed,
+		Remaining:    e.remaining(),
+		ArrivalPrice: arrival,
+		Children:     e.children,
+		Elapsed:      elapsed,
+	}
+
+	if e.executed.IsPositive() {
+		report.AveragePrice = e.quote.Div(e.executed, decimal.Nearest)
+
+		slippage := report.AveragePrice.Sub(arrival).Float64() / arrival.Float64() * 10_000
+		if e.parent.Side == "SELL" {
+			slippage = -slippage
+		}
+		report.SlippageBps = slippage
+	}
+
+	return report
+}
+
+func mid(book *models.ParsedOrderBook) (decimal.Decimal, bool) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return decimal.Zero, false
+	}
+
+	return book.Bids[0].Price.Add(book.Asks[0].Price).Div(decimal.NewFromInt(2), decimal.Nearest), true
+}
+
+func isOpen(status string) bool {
+	return status == string(models.OrderStatusNew) || status == string(models.OrderStatusPartiallyFilled)
+}
+
+func orZero(value string) string {
+	if value == "" {
+		return "0"
+	}
+
+	return value
+}
+
+// Sleep until a time or until ctx is canceled
+func waitUntil(ctx context.Context, until time.Time) error {
+	timer := time.NewTimer(time.Until(until))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
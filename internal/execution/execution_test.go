@@ -0,0 +1,341 @@
+package execution
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+var start = time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+// Exchange with a fixed book that fills a share of every child order
+type fakeExchange struct {
+	book     *models.ParsedOrderBook
+	fill     decimal.Decimal // Share of each child filled before it is settled
+	trades   []models.MarketTrade
+	klines   []models.Candle
+	placed   []models.Order
+	canceled []int64
+}
+
+func newFakeExchange() *fakeExchange {
+	return &fakeExchange{
+		book: &models.ParsedOrderBook{
+			Bids: []models.PriceLevel{{Price: decimal.NewFromInt(99), Quantity: decimal.NewFromInt(5)}},
+			Asks: []models.PriceLevel{{Price: decimal.NewFromInt(101), Quantity: decimal.NewFromInt(5)}},
+		},
+		fill: decimal.NewFromInt(1),
+	}
+}
+
+func (f *fakeExchange) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
+	return f.book, nil
+}
+
+func (f *fakeExchange) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	order := models.Order{Symbol: symbol, OrderID: int64(len(f.placed) + 1), Side: side, Type: orderType,
+		Price: price, OrigQty: quantity, Status: string(models.OrderStatusNew)}
+	f.placed = append(f.placed, order)
+	return &order, nil
+}
+
+func (f *fakeExchange) GetOrderStatus(symbol string, orderID int64) (*models.Order, error) {
+	order := f.placed[orderID-1]
+
+	executed := decimal.RequireFromString(order.OrigQty).Mul(f.fill)
+	order.ExecutedQty = executed.String()
+	order.CummulativeQuoteQty = executed.Mul(decimal.RequireFromString(order.Price)).String()
+
+	order.Status = string(models.OrderStatusPartiallyFilled)
+	if f.fill.Equal(decimal.NewFromInt(1)) {
+		order.Status = string(models.OrderStatusFilled)
+	}
+
+	return &order, nil
+}
+
+func (f *fakeExchange) CancelOrder(symbol string, orderID int64) (*models.Order, error) {
+	f.canceled = append(f.canceled, orderID)
+
+	order, _ := f.GetOrderStatus(symbol, orderID)
+	order.Status = string(models.OrderStatusCanceled)
+	return order, nil
+}
+
+func (f *fakeExchange) GetRecentTrades(symbol string, limit int) ([]models.MarketTrade, error) {
+	return f.trades, nil
+}
+
+func (f *fakeExchange) GetKlines(symbol, interval string, query models.KlineQuery) ([]models.Candle, error) {
+	return f.klines, nil
+}
+
+// Executor on a fake clock that jumps to each slice's end instead of sleeping
+func newExecutor(t *testing.T, exchange Exchange, parent ParentOrder, params Params) *Executor {
+	t.Helper()
+
+	if params.TickSize == "" {
+		params.TickSize, params.StepSize = "0.01", "0.001"
+	}
+
+	executor, err := New(exchange, parent, params)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	clock := start
+	executor.now = func() time.Time { return clock }
+	executor.wait = func(ctx context.Context, until time.Time) error {
+		clock = until
+		return ctx.Err()
+	}
+
+	return executor
+}
+
+// Quantities and prices of the placed children
+func children(exchange *fakeExchange) []string {
+	var placed []string
+	for _, order := range exchange.placed {
+		placed = append(placed, order.OrigQty+"@"+order.Price)
+	}
+
+	return placed
+}
+
+func TestTWAPSlicesEvenlyAndCatchesUp(t *testing.T) {
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: decimal.NewFromInt(10), Duration: 4 * time.Minute}
+	params := Params{Algorithm: TWAP, Style: Passive, Interval: time.Minute}
+
+	tests := []struct {
+		name     string
+		fill     string
+		want     []string
+		executed string
+	}{
+		{"full fills", "1", []string{"2.5@99.00", "2.5@99.00", "2.5@99.00", "2.5@99.00"}, "10"},
+		{"half fills", "0.5", []string{"2.5@99.00", "3.75@99.00", "4.375@99.00", "4.687@99.00"}, "7.656"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchange := newFakeExchange()
+			exchange.fill = decimal.RequireFromString(tt.fill)
+
+			report, err := newExecutor(t, exchange, parent, params).Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+
+			if got := children(exchange); !slices.Equal(got, tt.want) {
+				t.Errorf("children = %v, want %v", got, tt.want)
+			}
+
+			if report.Executed.String() != tt.executed || !report.Executed.Add(report.Remaining).Equal(parent.Quantity) {
+				t.Errorf("executed %s with %s remaining, want %s executed", report.Executed, report.Remaining, tt.executed)
+			}
+
+			// Buying passively at 99 against an arrival mid of 100
+			if report.AveragePrice.String() != "99" || report.SlippageBps != -100 {
+				t.Errorf("average price %s, slippage %.2f bps, want 99 and -100", report.AveragePrice, report.SlippageBps)
+			}
+		})
+	}
+}
+
+func TestSlicesEndWithTheDuration(t *testing.T) {
+	exchange := newFakeExchange()
+	exchange.fill = decimal.NewFromInt(1)
+
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: decimal.NewFromInt(10), Duration: 10 * time.Minute}
+	params := Params{Algorithm: TWAP, Style: Passive, Interval: 3 * time.Minute}
+
+	executor := newExecutor(t, exchange, parent, params)
+
+	var deadlines []time.Duration
+	wait := executor.wait
+	executor.wait = func(ctx context.Context, until time.Time) error {
+		deadlines = append(deadlines, until.Sub(start))
+		return wait(ctx, until)
+	}
+
+	if _, err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	// Four slices of 2m30s rather than three of 3m ending a minute early
+	want := []time.Duration{150 * time.Second, 300 * time.Second, 450 * time.Second, 600 * time.Second}
+	if !slices.Equal(deadlines, want) {
+		t.Errorf("slice ends = %v, want %v", deadlines, want)
+	}
+	if got := children(exchange); !slices.Equal(got, []string{"2.5@99.00", "2.5@99.00", "2.5@99.00", "2.5@99.00"}) {
+		t.Errorf("children = %v, want an even share in every slice", got)
+	}
+}
+
+func TestPOVFollowsMarketVolumeWithinTheLimit(t *testing.T) {
+	exchange := newFakeExchange()
+	exchange.trades = []models.MarketTrade{
+		{Qty: "100", Time: start.Add(-2 * time.Minute).UnixMilli()}, // Before the last slice
+		{Qty: "20", Time: start.Add(-30 * time.Second).UnixMilli()},
+		{Qty: "10", Time: start.UnixMilli()},
+	}
+
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "SELL", Quantity: decimal.NewFromInt(10), Duration: 3 * time.Minute,
+		LimitPrice: decimal.NewFromInt(100)}
+	params := Params{Algorithm: POV, Style: Aggressive, Interval: time.Minute, Participation: decimal.RequireFromString("0.1")}
+
+	report, err := newExecutor(t, exchange, parent, params).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	// 10% of the 30 then the 10 traded in each minute; crossing to the bid at 99 would breach the limit
+	if got, want := children(exchange), []string{"3@100.00", "1@100.00"}; !slices.Equal(got, want) {
+		t.Errorf("children = %v, want %v", got, want)
+	}
+
+	if report.Executed.String() != "4" || report.Remaining.String() != "6" {
+		t.Errorf("executed %s with %s remaining, want 4 and 6", report.Executed, report.Remaining)
+	}
+}
+
+func TestMaxParticipationCapsChildren(t *testing.T) {
+	exchange := newFakeExchange()
+	exchange.trades = []models.MarketTrade{{Qty: "5", Time: start.UnixMilli()}}
+
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: decimal.NewFromInt(10), Duration: time.Minute}
+	params := Params{Algorithm: TWAP, Style: Aggressive, Interval: time.Minute, MaxParticipation: decimal.RequireFromString("0.1")}
+
+	if _, err := newExecutor(t, exchange, parent, params).Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if got := children(exchange); len(got) != 1 || got[0] != "0.5@101.00" {
+		t.Errorf("children = %v, want one 0.5@101.00 capped at 10%% of the market", got)
+	}
+}
+
+func TestMarketVolumeFallsBackToKlines(t *testing.T) {
+	exchange := newFakeExchange()
+
+	// A full page of trades from the last ten seconds of a 30s slice
+	for i := range recentTradesLimit {
+		exchange.trades = append(exchange.trades, models.MarketTrade{Qty: "1", Time: start.Add(-10*time.Second + time.Duration(i)*time.Millisecond).UnixMilli()})
+	}
+	exchange.klines = []models.Candle{
+		{OpenTime: start.Add(-2 * time.Minute), CloseTime: start.Add(-time.Minute - time.Millisecond), Volume: decimal.NewFromInt(500)},
+		{OpenTime: start.Add(-time.Minute), CloseTime: start.Add(-time.Millisecond), Volume: decimal.NewFromInt(6000)},
+		{OpenTime: start, CloseTime: start.Add(time.Minute - time.Millisecond), Volume: decimal.NewFromInt(7)},
+	}
+
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: decimal.NewFromInt(10), Duration: time.Minute}
+	executor := newExecutor(t, exchange, parent, Params{Algorithm: POV, Style: Passive, Interval: 30 * time.Second, Participation: decimal.RequireFromString("0.1")})
+
+	volume, err := executor.marketVolume(30 * time.Second)
+	if err != nil {
+		t.Fatalf("marketVolume() returned error: %v", err)
+	}
+
+	// Half of the last closed minute; the earlier minute is outside the slice
+	// and the open one has not traded yet
+	if volume.String() != "3000" {
+		t.Errorf("volume = %s, want 3000 from klines", volume)
+	}
+
+	// A page shorter than the limit holds every trade of the slice
+	exchange.trades = exchange.trades[:10]
+	if volume, _ := executor.marketVolume(30 * time.Second); volume.String() != "10" {
+		t.Errorf("volume = %s, want 10 from trades", volume)
+	}
+}
+
+func TestVWAPFollowsTheVolumeProfile(t *testing.T) {
+	yesterday := start.Add(-24 * time.Hour)
+	candle := func(offset time.Duration, volume int64) models.Candle {
+		return models.Candle{OpenTime: yesterday.Add(offset), Volume: decimal.NewFromInt(volume)}
+	}
+
+	exchange := newFakeExchange()
+	exchange.klines = []models.Candle{candle(0, 1), candle(time.Minute, 3), candle(5*time.Minute, 50)}
+
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: decimal.NewFromInt(8), Duration: 2 * time.Minute}
+	params := Params{Algorithm: VWAP, Style: Passive, Interval: time.Minute, ProfileDays: 1}
+
+	if _, err := newExecutor(t, exchange, parent, params).Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if got := children(exchange); len(got) != 2 || got[0] != "2@99.00" || got[1] != "6@99.00" {
+		t.Errorf("children = %v, want 2 then 6 following a 1:3 profile", got)
+	}
+}
+
+func TestBuildVolumeProfile(t *testing.T) {
+	candles := []models.Candle{
+		{OpenTime: start.Add(-48 * time.Hour), Volume: decimal.NewFromInt(2)},
+		{OpenTime: start.Add(-24*time.Hour + 90*time.Second), Volume: decimal.NewFromInt(6)},
+		{OpenTime: start.Add(-24*time.Hour - time.Minute), Volume: decimal.NewFromInt(100)}, // Before the window
+	}
+
+	weights := BuildVolumeProfile(candles, start, time.Minute, 2)
+	if len(weights) != 2 || weights[0] != 0.25 || weights[1] != 0.75 {
+		t.Errorf("BuildVolumeProfile() = %v, want [0.25 0.75]", weights)
+	}
+
+	if weights := BuildVolumeProfile(candles[2:], start, time.Minute, 2); weights != nil {
+		t.Errorf("expected no profile without volume in the window, got %v", weights)
+	}
+
+	// Minute klines spread over the 30 second slices they overlap
+	kline := func(offset time.Duration, volume int64) models.Candle {
+		open := start.Add(-24*time.Hour + offset)
+		return models.Candle{OpenTime: open, CloseTime: open.Add(time.Minute - time.Millisecond), Volume: decimal.NewFromInt(volume)}
+	}
+	klines := []models.Candle{kline(-30*time.Second, 4), kline(30*time.Second, 4), kline(90*time.Second, 8)}
+
+	want := []float64{0.2, 0.2, 0.2, 0.4}
+	if weights := BuildVolumeProfile(klines, start, 30*time.Second, 4); !slices.Equal(weights, want) {
+		t.Errorf("BuildVolumeProfile() of minute klines = %v, want %v", weights, want)
+	}
+}
+
+func TestNewRejectsInvalidOrders(t *testing.T) {
+	parent := ParentOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: decimal.NewFromInt(1), Duration: time.Minute}
+	params := Params{Algorithm: TWAP, Style: Passive, Interval: time.Minute}
+
+	tests := []struct {
+		name   string
+		modify func(*ParentOrder, *Params)
+	}{
+		{"side", func(p *ParentOrder, _ *Params) { p.Side = "HOLD" }},
+		{"quantity", func(p *ParentOrder, _ *Params) { p.Quantity = decimal.Zero }},
+		{"duration shorter than an interval", func(p *ParentOrder, _ *Params) { p.Duration = time.Second }},
+		{"algorithm", func(_ *ParentOrder, q *Params) { q.Algorithm = "iceberg" }},
+		{"pov without participation", func(_ *ParentOrder, q *Params) { q.Algorithm = POV }},
+		{"style", func(_ *ParentOrder, q *Params) { q.Style = "sneaky" }},
+		{"participation cap above 1", func(_ *ParentOrder, q *Params) { q.MaxParticipation = decimal.NewFromInt(2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, q := parent, params
+			tt.modify(&p, &q)
+
+			if err := q.Validate(p); err == nil {
+				t.Error("expected Validate() to reject the order")
+			}
+			if _, err := New(newFakeExchange(), p, q); err == nil {
+				t.Error("expected New() to reject the order")
+			}
+		})
+	}
+
+	if err := params.Validate(parent); err != nil {
+		t.Errorf("Validate() rejected a valid order: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package execution
+
+import (
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Kline intervals a volume profile can be built from, shortest first
+var profileIntervals = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+	{"1h", time.Hour},
+}
+
+const (
+	defaultProfileDays = 5    // Days of history in a VWAP profile
+	klinesLimit        = 1000 // Most klines one request returns
+)
+
+func evenWeights(slices int) []float64 {
+	weights := make([]float64, slices)
+	for i := range weights {
+		weights[i] = 1 / float64(slices)
+	}
+
+	return weights
+}
+
+// Share of the parent order due by the end of the first n slices
+func cumulative(weights []float64, n int) float64 {
+	if n >= len(weights) {
+		return 1
+	}
+
+	var total float64
+	for _, weight := range weights[:n] {
+		total += weight
+	}
+
+	return total
+}
+
+// Share of the volume traded in each slice of the window starting at start
+// on the days the candles cover, by time of day. A candle's volume is spread
+// evenly over the slices it overlaps, or counted at its open time when it has
+// no close time. Nil when the candles hold no volume inside the window.
+func BuildVolumeProfile(candles []models.Candle, start time.Time, interval time.Duration, slices int) []float64 {
+	weights := make([]float64, slices)
+	window := interval * time.Duration(slices)
+
+	var total float64
+	for _, candle := range candles {
+		volume := candle.Volume.Float64()
+		length := max(candle.CloseTime.Sub(candle.OpenTime)+time.Millisecond, 0)
+
+		// Offset into the window on the candle's own day, negative for a
+		// candle that opened before the window but runs into it
+		offset := candle.OpenTime.Sub(start) % (24 * time.Hour)
+		if offset < 0 {
+			offset += 24 * time.Hour
+		}
+		if offset+length > 24*time.Hour {
+			offset -= 24 * time.Hour
+		}
+
+		if length == 0 {
+			if offset < window {
+				weights[int(offset/interval)] += volume
+				total += volume
+			}
+			continue
+		}
+
+		for from, end := max(offset, 0), min(offset+length, window); from < end; {
+			i := int(from / interval)
+			to := min(time.Duration(i+1)*interval, end)
+
+			share := volume * float64(to-from) / float64(length)
+			weights[i] += share
+			total += share
+
+			from = to
+		}
+	}
+
+	if total <= 0 {
+		return nil
+	}
+
+	for i := range weights {
+		weights[i] /= total
+	}
+
+	return weights
+}
+
+// Weights from the same window on earlier days, or even weights when no
+// history is available
+func (e *Executor) volumeProfile(start time.Time) []float64 {
+	logger := e.logger()
+
+	days := e.params.ProfileDays
+	if days < 1 {
+		days = defaultProfileDays
+	}
+
+	// The shortest interval that covers the window in one request
+	interval := profileIntervals[len(profileIntervals)-1]
+	for _, candidate := range profileIntervals {
+		if e.parent.Duration <= candidate.duration*klinesLimit {
+			interval = candidate
+			break
+		}
+	}
+
+	var candles []models.Candle
+	for day := 1; day <= days; day++ {
+		from := start.Add(-time.Duration(day) * 24 * time.Hour)
+
+		history, err := e.exchange.GetKlines(e.parent.Symbol, interval.name, models.KlineQuery{
+			StartTime: from,
+			EndTime:   from.Add(e.parent.Duration - time.Millisecond),
+			Limit:     klinesLimit,
+		})
+		if err != nil {
+			logger.Warn("Failed to get volume history", "day", day, "error", err)
+			continue
+		}
+
+		candles = append(candles, history...)
+	}
+
+	weights := BuildVolumeProfile(candles, start, e.slice, e.slices)
+	if weights == nil {
+		logger.Warn("No volume history for the window, using an even schedule")
+		return evenWeights(e.slices)
+	}
+
+	return weights
+}
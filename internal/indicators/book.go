@@ -0,0 +1,52 @@
+package indicators
+
+import (
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Order book imbalance over the top levels on each side, from -1 (only asks)
+// to 1 (only bids). False for an empty book.
+func Imbalance(book *models.ParsedOrderBook, levels int) (float64, bool) {
+	bids := depth(book.Bids, levels)
+	asks := depth(book.Asks, levels)
+
+	total := bids.Add(asks)
+	if !total.IsPositive() {
+		return 0, false
+	}
+
+	return bids.Sub(asks).Float64() / total.Float64(), true
+}
+
+// Mid price weighted by the opposite side's top of book quantity, which leans
+// towards the side likely to trade next. False unless both sides have a level.
+func Microprice(book *models.ParsedOrderBook) (decimal.Decimal, bool) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return decimal.Zero, false
+	}
+
+	bid, ask := book.Bids[0], book.Asks[0]
+
+	total := bid.Quantity.Add(ask.Quantity)
+	if !total.IsPositive() {
+		return decimal.Zero, false
+	}
+
+	weighted := bid.Price.Mul(ask.Quantity).Add(ask.Price.Mul(bid.Quantity))
+	return weighted.Div(total, decimal.Nearest), true
+}
+
+// Total quantity on the best levels of one side, or all of them when levels is 0
+func depth(side []models.PriceLevel, levels int) decimal.Decimal {
+	if levels <= 0 || levels > len(side) {
+		levels = len(side)
+	}
+
+	total := decimal.Zero
+	for _, level := range side[:levels] {
+		total = total.Add(level.Quantity)
+	}
+
+	return total
+}
@@ -0,0 +1,50 @@
+package indicators
+
+// Indicator over a single series of values, such as closing prices.
+// Every update costs O(1) regardless of the period.
+type Indicator interface {
+	Update(value float64)
+	Value() float64 // Latest value, zero until Ready
+	Ready() bool    // Enough values have been seen for Value to be meaningful
+}
+
+var (
+	_ Indicator = (*SMA)(nil)
+	_ Indicator = (*EMA)(nil)
+	_ Indicator = (*RSI)(nil)
+	_ Indicator = (*MACD)(nil)
+	_ Indicator = (*Bollinger)(nil)
+)
+
+// Fixed size window of the most recent values
+type window struct {
+	values []float64
+	next   int  // Index the next value is written to
+	full   bool // Every slot holds a value
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size)}
+}
+
+// Store value, returning the value it evicted and whether there was one
+func (w *window) push(value float64) (float64, bool) {
+	evicted, ok := w.values[w.next], w.full
+	w.values[w.next] = value
+
+	w.next++
+	if w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+
+	return evicted, ok
+}
+
+func (w *window) len() int {
+	if w.full {
+		return len(w.values)
+	}
+
+	return w.next
+}
@@ -0,0 +1,193 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Closing prices from the StockCharts RSI worked example
+var closes = []float64{
+	44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+	45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439,
+	46.2122, 46.2521, 45.7137, 46.4515, 45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672,
+	43.4205, 42.6628, 43.1314,
+}
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+// Feed closes to an indicator, returning the value after each one
+func feed(indicator Indicator) []float64 {
+	values := make([]float64, len(closes))
+	for i, c := range closes {
+		indicator.Update(c)
+		values[i] = indicator.Value()
+	}
+
+	return values
+}
+
+func TestRSIMatchesPublishedValues(t *testing.T) {
+	// RSI(14) from the same worked example, rounded to 2 places
+	want := []float64{
+		70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+		54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+	}
+
+	rsi := NewRSI(14)
+	for i, c := range closes {
+		rsi.Update(c)
+
+		if i < 14 {
+			if rsi.Ready() {
+				t.Fatalf("RSI ready after %d values", i+1)
+			}
+			continue
+		}
+
+		if got := rsi.Value(); !near(got, want[i-14], 0.005) {
+			t.Errorf("RSI after %d values = %.4f, want %.2f", i+1, got, want[i-14])
+		}
+	}
+}
+
+func TestIndicatorsMatchGoldenValues(t *testing.T) {
+	// Computed with the textbook batch definitions over the whole window
+	tests := []struct {
+		name      string
+		indicator Indicator
+		readyAt   int // Values needed before Ready
+		want      float64
+	}{
+		{"SMA(10)", NewSMA(10), 10, 44.37969},
+		{"EMA(10)", NewEMA(10), 10, 44.120148356901375},
+		{"MACD(12,26,5) histogram", NewMACD(12, 26, 5), 30, -0.20949882052331603},
+		{"Bollinger(20) middle", NewBollinger(20, 2), 20, 45.24261},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := feed(tt.indicator)
+
+			if got := values[len(values)-1]; !near(got, tt.want, 1e-9) {
+				t.Errorf("final value = %.12f, want %.12f", got, tt.want)
+			}
+
+			if values[tt.readyAt-2] != 0 {
+				t.Errorf("expected no value before %d updates, got %f", tt.readyAt, values[tt.readyAt-2])
+			}
+		})
+	}
+}
+
+func TestMACDLines(t *testing.T) {
+	macd := NewMACD(12, 26, 5)
+	feed(macd)
+
+	if !near(macd.MACD(), -0.4747310494070831, 1e-9) || !near(macd.Signal(), -0.2652322288837671, 1e-9) {
+		t.Errorf("MACD = %.12f, signal = %.12f", macd.MACD(), macd.Signal())
+	}
+}
+
+func TestBollingerBands(t *testing.T) {
+	bands := NewBollinger(20, 2)
+	feed(bands)
+
+	if !near(bands.Upper(), 47.62346641557823, 1e-9) || !near(bands.Lower(), 42.86175358442177, 1e-9) {
+		t.Errorf("bands = %.12f to %.12f", bands.Lower(), bands.Upper())
+	}
+
+	flat := NewBollinger(3, 2)
+	for range 3 {
+		flat.Update(0.1)
+	}
+
+	if flat.StdDev() != 0 || flat.Upper() != flat.Lower() {
+		t.Errorf("expected flat prices to give zero width bands, got %g", flat.StdDev())
+	}
+}
+
+func TestATR(t *testing.T) {
+	// True ranges 2, 2, 3, 1, 3
+	bars := [][3]float64{{10, 8, 9}, {11, 9, 10}, {12, 9, 11}, {11, 10, 10.5}, {13, 10, 12}}
+	want := []float64{0, 0, 7.0 / 3, 17.0 / 9, 61.0 / 27}
+
+	atr := NewATR(3)
+	for i, bar := range bars {
+		atr.Update(bar[0], bar[1], bar[2])
+
+		if got := atr.Value(); !near(got, want[i], 1e-12) {
+			t.Errorf("ATR after bar %d = %f, want %f", i+1, got, want[i])
+		}
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	trades := [][2]float64{{100, 1}, {102, 3}, {101, 2}}
+
+	cumulative, rolling := NewVWAP(0), NewVWAP(2)
+	for _, trade := range trades {
+		cumulative.Update(trade[0], trade[1])
+		rolling.Update(trade[0], trade[1])
+	}
+
+	if !near(cumulative.Value(), 608.0/6, 1e-12) || !near(rolling.Value(), 101.6, 1e-12) {
+		t.Errorf("VWAP = %f cumulative, %f over 2 trades", cumulative.Value(), rolling.Value())
+	}
+
+	rolling.Reset()
+	if rolling.Ready() || rolling.Value() != 0 {
+		t.Error("expected no VWAP after Reset")
+	}
+
+	rolling.UpdateCandle(models.Candle{
+		High:   decimal.NewFromInt(12),
+		Low:    decimal.NewFromInt(9),
+		Close:  decimal.NewFromInt(12),
+		Volume: decimal.NewFromInt(5),
+	})
+	if rolling.Value() != 11 {
+		t.Errorf("expected a candle to count at its typical price, got %f", rolling.Value())
+	}
+}
+
+func TestBookMetrics(t *testing.T) {
+	level := func(price, quantity string) models.PriceLevel {
+		return models.PriceLevel{Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(quantity)}
+	}
+
+	book := &models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("100", "3"), level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1"), level("102", "0")},
+	}
+
+	tests := []struct {
+		levels int
+		want   float64
+	}{
+		{1, 0.5},
+		{2, 0.6},
+		{0, 0.6},
+	}
+
+	for _, tt := range tests {
+		if got, ok := Imbalance(book, tt.levels); !ok || !near(got, tt.want, 1e-12) {
+			t.Errorf("Imbalance(%d levels) = %f, want %f", tt.levels, got, tt.want)
+		}
+	}
+
+	if price, ok := Microprice(book); !ok || price.String() != "100.75" {
+		t.Errorf("Microprice() = %s, want 100.75 leaning towards the thin ask", price)
+	}
+
+	if _, ok := Microprice(&models.ParsedOrderBook{Bids: book.Bids}); ok {
+		t.Error("expected no microprice without asks")
+	}
+	if _, ok := Imbalance(&models.ParsedOrderBook{}, 5); ok {
+		t.Error("expected no imbalance for an empty book")
+	}
+}
@@ -0,0 +1,69 @@
+package indicators
+
+// Simple moving average over the last period values
+type SMA struct {
+	window *window
+	sum    float64
+}
+
+func NewSMA(period int) *SMA {
+	return &SMA{window: newWindow(max(period, 1))}
+}
+
+func (s *SMA) Update(value float64) {
+	if evicted, ok := s.window.push(value); ok {
+		s.sum -= evicted
+	}
+
+	s.sum += value
+}
+
+func (s *SMA) Value() float64 {
+	if !s.Ready() {
+		return 0
+	}
+
+	return s.sum / float64(s.window.len())
+}
+
+func (s *SMA) Ready() bool {
+	return s.window.full
+}
+
+// Exponential moving average with smoothing 2 / (period + 1), seeded with
+// the simple average of the first period values
+type EMA struct {
+	period int
+	alpha  float64
+	seed   float64 // Sum of the values seen before the average is ready
+	count  int
+	value  float64
+}
+
+func NewEMA(period int) *EMA {
+	period = max(period, 1)
+	return &EMA{period: period, alpha: 2 / float64(period+1)}
+}
+
+func (e *EMA) Update(value float64) {
+	if e.count < e.period {
+		e.count++
+		e.seed += value
+
+		if e.count == e.period {
+			e.value = e.seed / float64(e.period)
+		}
+
+		return
+	}
+
+	e.value += e.alpha * (value - e.value)
+}
+
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+func (e *EMA) Ready() bool {
+	return e.count == e.period
+}
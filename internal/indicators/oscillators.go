@@ -0,0 +1,114 @@
+package indicators
+
+// Relative strength index with Wilder's smoothing, from 0 to 100
+type RSI struct {
+	period  int
+	last    float64 // Previous value
+	changes int     // Changes seen, up to period
+	gain    float64 // Average gain
+	loss    float64 // Average loss
+	started bool    // A first value has been seen
+}
+
+func NewRSI(period int) *RSI {
+	return &RSI{period: max(period, 1)}
+}
+
+func (r *RSI) Update(value float64) {
+	if !r.started {
+		r.last, r.started = value, true
+		return
+	}
+
+	change := value - r.last
+	r.last = value
+
+	gain, loss := max(change, 0), max(-change, 0)
+	n := float64(r.period)
+
+	// Plain averages over the first period changes, then Wilder's smoothing
+	if r.changes < r.period {
+		r.changes++
+		r.gain += gain / n
+		r.loss += loss / n
+		return
+	}
+
+	r.gain = (r.gain*(n-1) + gain) / n
+	r.loss = (r.loss*(n-1) + loss) / n
+}
+
+func (r *RSI) Value() float64 {
+	if !r.Ready() {
+		return 0
+	}
+
+	if r.loss == 0 {
+		if r.gain == 0 {
+			return 50
+		}
+
+		return 100
+	}
+
+	return 100 - 100/(1+r.gain/r.loss)
+}
+
+func (r *RSI) Ready() bool {
+	return r.changes == r.period
+}
+
+// Moving average convergence divergence: the difference between a fast and a
+// slow EMA, its signal line, and the histogram between them
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+}
+
+// Usually 12, 26 and 9
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+func (m *MACD) Update(value float64) {
+	m.fast.Update(value)
+	m.slow.Update(value)
+
+	// The signal line averages MACD values, which start once both EMAs are ready
+	if m.fast.Ready() && m.slow.Ready() {
+		m.signal.Update(m.MACD())
+	}
+}
+
+// Fast EMA minus slow EMA
+func (m *MACD) MACD() float64 {
+	if !m.fast.Ready() || !m.slow.Ready() {
+		return 0
+	}
+
+	return m.fast.Value() - m.slow.Value()
+}
+
+// EMA of the MACD line
+func (m *MACD) Signal() float64 {
+	return m.signal.Value()
+}
+
+// MACD line minus signal line
+func (m *MACD) Histogram() float64 {
+	if !m.Ready() {
+		return 0
+	}
+
+	return m.MACD() - m.Signal()
+}
+
+// Histogram, so MACD can be used as an Indicator
+func (m *MACD) Value() float64 {
+	return m.Histogram()
+}
+
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
@@ -0,0 +1,126 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Bollinger bands: a simple moving average with bands a number of population
+// standard deviations above and below it
+type Bollinger struct {
+	window *window
+	k      float64
+	sum    float64
+	sumSq  float64
+}
+
+// Usually a period of 20 and 2 standard deviations
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{window: newWindow(max(period, 1)), k: k}
+}
+
+func (b *Bollinger) Update(value float64) {
+	if evicted, ok := b.window.push(value); ok {
+		b.sum -= evicted
+		b.sumSq -= evicted * evicted
+	}
+
+	b.sum += value
+	b.sumSq += value * value
+}
+
+// Moving average
+func (b *Bollinger) Middle() float64 {
+	if !b.Ready() {
+		return 0
+	}
+
+	return b.sum / float64(b.window.len())
+}
+
+func (b *Bollinger) Upper() float64 {
+	return b.Middle() + b.k*b.StdDev()
+}
+
+func (b *Bollinger) Lower() float64 {
+	return b.Middle() - b.k*b.StdDev()
+}
+
+// Population standard deviation over the window
+func (b *Bollinger) StdDev() float64 {
+	if !b.Ready() {
+		return 0
+	}
+
+	n := float64(b.window.len())
+	mean := b.sum / n
+
+	// Rounding in the running sums can leave a tiny negative variance
+	return math.Sqrt(max(b.sumSq/n-mean*mean, 0))
+}
+
+// Width of the bands relative to the middle
+func (b *Bollinger) Bandwidth() float64 {
+	if middle := b.Middle(); middle != 0 {
+		return (b.Upper() - b.Lower()) / middle
+	}
+
+	return 0
+}
+
+// Middle band, so Bollinger can be used as an Indicator
+func (b *Bollinger) Value() float64 {
+	return b.Middle()
+}
+
+func (b *Bollinger) Ready() bool {
+	return b.window.full
+}
+
+// Average true range with Wilder's smoothing
+type ATR struct {
+	period    int
+	lastClose float64
+	count     int // True ranges seen, up to period
+	value     float64
+}
+
+func NewATR(period int) *ATR {
+	return &ATR{period: max(period, 1)}
+}
+
+// Add a bar. The first bar's true range is its high minus its low.
+func (a *ATR) Update(high, low, close float64) {
+	trueRange := high - low
+	if a.count > 0 {
+		trueRange = max(trueRange, math.Abs(high-a.lastClose), math.Abs(low-a.lastClose))
+	}
+	a.lastClose = close
+
+	n := float64(a.period)
+	if a.count < a.period {
+		a.count++
+		a.value += trueRange / n
+		return
+	}
+
+	a.value = (a.value*(n-1) + trueRange) / n
+}
+
+// Add a candle
+func (a *ATR) UpdateCandle(candle models.Candle) {
+	a.Update(candle.High.Float64(), candle.Low.Float64(), candle.Close.Float64())
+}
+
+func (a *ATR) Value() float64 {
+	if !a.Ready() {
+		return 0
+	}
+
+	return a.value
+}
+
+func (a *ATR) Ready() bool {
+	return a.count == a.period
+}
@@ -0,0 +1,68 @@
+package indicators
+
+import "github.com/iamramtin/binance-trader/internal/models"
+
+// Volume weighted average price, over every trade since the last Reset or
+// over a rolling window of trades or bars
+type VWAP struct {
+	prices   *window // Price times volume per entry, nil when cumulative
+	volumes  *window
+	notional float64
+	volume   float64
+}
+
+// VWAP over the last window entries, or cumulative when window is 0
+func NewVWAP(window int) *VWAP {
+	v := &VWAP{}
+	if window > 0 {
+		v.prices, v.volumes = newWindow(window), newWindow(window)
+	}
+
+	return v
+}
+
+// Add a trade, or a bar at its typical price
+func (v *VWAP) Update(price, volume float64) {
+	notional := price * volume
+
+	if v.prices != nil {
+		if evicted, ok := v.prices.push(notional); ok {
+			v.notional -= evicted
+		}
+		if evicted, ok := v.volumes.push(volume); ok {
+			v.volume -= evicted
+		}
+	}
+
+	v.notional += notional
+	v.volume += volume
+}
+
+// Add a candle at its typical price, (high + low + close) / 3
+func (v *VWAP) UpdateCandle(candle models.Candle) {
+	typical := (candle.High.Float64() + candle.Low.Float64() + candle.Close.Float64()) / 3
+	v.Update(typical, candle.Volume.Float64())
+}
+
+func (v *VWAP) Value() float64 {
+	if v.volume <= 0 {
+		return 0
+	}
+
+	return v.notional / v.volume
+}
+
+// Some volume has traded
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
+
+// Start over, e.g. at the start of a session
+func (v *VWAP) Reset() {
+	window := 0
+	if v.prices != nil {
+		window = len(v.prices.values)
+	}
+
+	*v = *NewVWAP(window)
+}
@@ -0,0 +1,212 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Logged in place of a credential
+const Redacted = "[REDACTED]"
+
+// Secrets shorter than this are not scrubbed from text, they would mask ordinary words
+const minSecretLength = 8
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Attribute keys whose values are always redacted, compared case-insensitively
+var sensitiveKeys = map[string]bool{
+	"apikey":        true,
+	"api_key":       true,
+	"secretkey":     true,
+	"secret_key":    true,
+	"signature":     true,
+	"token":         true,
+	"control_token": true,
+	"authorization": true,
+	"password":      true,
+	"x-mbx-apikey":  true,
+}
+
+type Options struct {
+	Level  slog.Level
+	Format string // FormatText or FormatJSON
+}
+
+// Parse a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", name)
+	}
+
+	return level, nil
+}
+
+// Create a logger writing to the shared output, with credentials redacted
+func New(options Options) *slog.Logger {
+	handlerOptions := &slog.HandlerOptions{Level: options.Level}
+
+	var handler slog.Handler
+	if options.Format == FormatJSON {
+		handler = slog.NewJSONHandler(output, handlerOptions)
+	} else {
+		handler = slog.NewTextHandler(output, handlerOptions)
+	}
+
+	return slog.New(&redactingHandler{next: handler})
+}
+
+// Make New(options) the default logger, which the log package also writes through
+func Setup(options Options) {
+	slog.SetDefault(New(options))
+}
+
+// Writer every logger created by New shares, so the destination can change
+// after loggers have been handed out
+var output = &switchWriter{w: os.Stderr}
+
+type switchWriter struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p)
+}
+
+// Send log output to w, such as a dashboard log pane
+func SetOutput(w io.Writer) {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+
+	output.w = w
+}
+
+var (
+	secrets   []string
+	secretsMu sync.RWMutex
+)
+
+// Register values, such as API keys, that must never appear in a log line
+func AddSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	for _, value := range values {
+		if len(value) >= minSecretLength {
+			secrets = append(secrets, value)
+		}
+	}
+}
+
+// Replace every registered secret in s
+func Scrub(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+
+	return s
+}
+
+// Redact sensitive attributes and scrub registered secrets from the
+// message and every attribute before passing the record on
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, Scrub(r.Message), r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+
+	a.Value = redactValue(a.Value.Resolve())
+	return a
+}
+
+func redactValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.StringValue(Scrub(v.String()))
+
+	case slog.KindGroup:
+		attrs := v.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			redacted[i] = redactAttr(a)
+		}
+		return slog.GroupValue(redacted...)
+
+	case slog.KindAny:
+		switch value := v.Any().(type) {
+		case map[string]string:
+			redacted := make(map[string]string, len(value))
+			for k, s := range value {
+				if sensitiveKeys[strings.ToLower(k)] {
+					s = Redacted
+				}
+				redacted[k] = Scrub(s)
+			}
+			return slog.AnyValue(redacted)
+
+		case map[string]any:
+			redacted := make(map[string]any, len(value))
+			for k, a := range value {
+				redacted[k] = redactAttr(slog.Any(k, a)).Value.Any()
+			}
+			return slog.AnyValue(redacted)
+
+		case error:
+			return slog.StringValue(Scrub(value.Error()))
+
+		default:
+			// Anything else is logged in its formatted form, so scrub that
+			if s := fmt.Sprintf("%+v", value); Scrub(s) != s {
+				return slog.StringValue(Scrub(s))
+			}
+		}
+	}
+
+	return v
+}
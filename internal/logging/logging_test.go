@@ -0,0 +1,123 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	const apiKey = "vmPUZE6mv9SD5VNHk4HlWFsOr6aKE2zvsw0MuIgwCIPy6utIco14y7Ju91duEh8A"
+	AddSecrets(apiKey, "short")
+
+	tests := []struct {
+		name string
+		log  func(logger *slog.Logger)
+	}{
+		{
+			name: "sensitive key",
+			log:  func(logger *slog.Logger) { logger.Info("Request", "signature", "abc123", "apiKey", apiKey) },
+		},
+		{
+			name: "secret in message",
+			log:  func(logger *slog.Logger) { logger.Info("Sending key " + apiKey) },
+		},
+		{
+			name: "secret in error",
+			log:  func(logger *slog.Logger) { logger.Error("Failed", "error", errors.New("bad key "+apiKey)) },
+		},
+		{
+			name: "params map",
+			log: func(logger *slog.Logger) {
+				logger.Info("Params", "params", map[string]string{"symbol": "BTCUSDT", "apiKey": apiKey, "signature": "abc123"})
+			},
+		},
+		{
+			name: "nested group",
+			log:  func(logger *slog.Logger) { logger.Info("Request", slog.Group("request", "token", "abc123")) },
+		},
+		{
+			name: "logger attributes",
+			log:  func(logger *slog.Logger) { logger.With("key", apiKey).Info("Connected") },
+		},
+		{
+			name: "formatted struct",
+			log: func(logger *slog.Logger) {
+				logger.Info("Config", "config", struct{ Key string }{apiKey})
+			},
+		},
+	}
+
+	for _, format := range []string{FormatText, FormatJSON} {
+		for _, tt := range tests {
+			t.Run(format+"/"+tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				SetOutput(&buf)
+				defer SetOutput(os.Stderr)
+
+				tt.log(New(Options{Level: slog.LevelDebug, Format: format}))
+
+				line := buf.String()
+				if strings.Contains(line, apiKey) || strings.Contains(line, "abc123") {
+					t.Errorf("credential reached the log: %s", line)
+				}
+
+				if !strings.Contains(line, Redacted) {
+					t.Errorf("expected %s in the log: %s", Redacted, line)
+				}
+			})
+		}
+	}
+}
+
+func TestShortSecretsAreNotScrubbed(t *testing.T) {
+	AddSecrets("key")
+
+	if got := Scrub("monkey business"); got != "monkey business" {
+		t.Errorf("expected a short secret to be ignored, got %q", got)
+	}
+}
+
+func TestJSONFormatAndLevel(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	logger := New(Options{Level: slog.LevelWarn, Format: FormatJSON})
+	logger.Info("Hidden")
+	logger.Warn("Placed order", "symbol", "BTCUSDT", "orderId", 42)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", buf.String(), err)
+	}
+
+	if record["msg"] != "Placed order" || record["symbol"] != "BTCUSDT" || record["orderId"] != float64(42) {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.name, got, err)
+		}
+	}
+}
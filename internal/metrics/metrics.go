@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prefix of every metric name
+const Namespace = "binance"
+
+// Registry holding every metric the application exports.
+// Packages register instruments with Factory; components whose values are
+// read at scrape time implement prometheus.Collector and are registered by main.
+var Registry = prometheus.NewRegistry()
+
+// Creates instruments registered with Registry
+var Factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Serve Registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// HTTP server exposing /metrics
+type Server struct {
+	http *http.Server
+}
+
+// Create a server on addr; an address without a host binds to localhost
+func NewServer(addr string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+
+	return &Server{http: &http.Server{
+		Addr:              utils.ListenAddr(addr),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}}
+}
+
+// Listen and serve in the background. Returns once the address is bound.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("metrics server: %w", err)
+	}
+
+	slog.Info("Serving metrics", "url", fmt.Sprintf("http://%s/metrics", listener.Addr()))
+
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
@@ -0,0 +1,110 @@
+package models
+
+import "encoding/json"
+
+// Combined stream envelope or control response from the market data streams endpoint
+type StreamMessage struct {
+	Stream string          `json:"stream,omitempty"` // Stream name, set for market data events
+	Data   json.RawMessage `json:"data,omitempty"`   // Event payload
+	ID     *uint64         `json:"id,omitempty"`     // Control request ID, set for control responses
+	Result json.RawMessage `json:"result,omitempty"` // Control response result
+	Error  *APIError       `json:"error,omitempty"`  // Control response error
+}
+
+// Control request to the market data streams endpoint
+type StreamRequest struct {
+	Method string   `json:"method"`           // SUBSCRIBE, UNSUBSCRIBE or LIST_SUBSCRIPTIONS
+	Params []string `json:"params,omitempty"` // Stream names
+	ID     uint64   `json:"id"`               // ID used to match the response
+}
+
+// Raw trade (<symbol>@trade)
+type TradeEvent struct {
+	EventType    string `json:"e"`
+	EventTime    int64  `json:"E"`
+	Symbol       string `json:"s"`
+	TradeID      int64  `json:"t"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+	IsBestMatch  bool   `json:"M"`
+}
+
+// Aggregated trade (<symbol>@aggTrade)
+type AggTradeEvent struct {
+	EventType    string `json:"e"`
+	EventTime    int64  `json:"E"`
+	Symbol       string `json:"s"`
+	AggTradeID   int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	FirstTradeID int64  `json:"f"`
+	LastTradeID  int64  `json:"l"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+	IsBestMatch  bool   `json:"M"`
+}
+
+// Best bid and ask update (<symbol>@bookTicker)
+type BookTickerEvent struct {
+	UpdateID    int64  `json:"u"`
+	Symbol      string `json:"s"`
+	BidPrice    string `json:"b"`
+	BidQuantity string `json:"B"`
+	AskPrice    string `json:"a"`
+	AskQuantity string `json:"A"`
+}
+
+// Candlestick update (<symbol>@kline_<interval>)
+type KlineEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	Kline     Kline  `json:"k"`
+}
+
+// Single candlestick
+type Kline struct {
+	StartTime           int64  `json:"t"`
+	CloseTime           int64  `json:"T"`
+	Symbol              string `json:"s"`
+	Interval            string `json:"i"`
+	FirstTradeID        int64  `json:"f"`
+	LastTradeID         int64  `json:"L"`
+	Open                string `json:"o"`
+	Close               string `json:"c"`
+	High                string `json:"h"`
+	Low                 string `json:"l"`
+	Volume              string `json:"v"`
+	NumberOfTrades      int64  `json:"n"`
+	IsClosed            bool   `json:"x"`
+	QuoteVolume         string `json:"q"`
+	TakerBuyBaseVolume  string `json:"V"`
+	TakerBuyQuoteVolume string `json:"Q"`
+	Ignore              string `json:"B"`
+}
+
+// Diff depth update (<symbol>@depth)
+type DepthUpdateEvent struct {
+	EventType     string     `json:"e"`
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	Bids          [][]string `json:"b"` // Bids as [price, quantity] pairs
+	Asks          [][]string `json:"a"` // Asks as [price, quantity] pairs
+}
+
+// Rolling 24h mini ticker (<symbol>@miniTicker)
+type MiniTickerEvent struct {
+	EventType   string `json:"e"`
+	EventTime   int64  `json:"E"`
+	Symbol      string `json:"s"`
+	Close       string `json:"c"`
+	Open        string `json:"o"`
+	High        string `json:"h"`
+	Low         string `json:"l"`
+	Volume      string `json:"v"`
+	QuoteVolume string `json:"q"`
+}
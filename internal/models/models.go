@@ -1,12 +1,17 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+)
 
 // WebSocket API request to Binance
 type WebSocketRequest struct {
-	ID     string `json:"id"`     // Arbitrary ID used to match responses to requests
-	Method string `json:"method"` // Request method name
-	Params any `json:"params,omitempty"` // Request parameters. May be omitted if there are no parameters
+	ID     string `json:"id"`               // Arbitrary ID used to match responses to requests
+	Method string `json:"method"`           // Request method name
+	Params any    `json:"params,omitempty"` // Request parameters. May be omitted if there are no parameters
 }
 
 // WebSocket API response from Binance
@@ -21,8 +26,8 @@ type WebSocketResponse struct {
 
 // Error returned from Binance
 type APIError struct {
-	Code int    `json:code`
-	Msg  string `json:msg`
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
 }
 
 // Rate limit information
@@ -71,6 +76,7 @@ type Order struct {
 	Side                    string `json:"side"`
 	WorkingTime             int64  `json:"workingTime"`
 	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	Simulated               bool   `json:"simulated,omitempty"` // Filled locally by a dry run, never sent to the exchange
 }
 
 // Parameters for placing an order
@@ -85,18 +91,18 @@ type OrderParams struct {
 	Timestamp        int64  `json:"timestamp"` // Unix timestamp in milliseconds
 }
 
-// Parsed version of the orderbook with float values
+// Parsed version of the orderbook with decimal values
 type ParsedOrderBook struct {
-	Symbol       string
-	LastUpdateID int
-	Bids         []PriceLevel
-	Asks         []PriceLevel
+	Symbol       string       `json:"symbol"`
+	LastUpdateID int          `json:"lastUpdateId"`
+	Bids         []PriceLevel `json:"bids"`
+	Asks         []PriceLevel `json:"asks"`
 }
 
 // Price level in the orderbook
 type PriceLevel struct {
-	Price    float64
-	Quantity float64
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
 }
 
 type AccountResponse struct {
@@ -134,8 +140,122 @@ type AccountInfo struct {
 
 // Single asset balance
 type Balance struct {
-	Asset  string `json:"asset"`
-	Free   string `json:"free"`
-	Locked string `json:"locked"`
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+}
+
+// Account trade from myTrades
+type Trade struct {
+	Symbol          string `json:"symbol"`
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	OrderListID     int64  `json:"orderListId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+	IsBestMatch     bool   `json:"isBestMatch"`
+}
+
+// Public trade from trades.recent
+type MarketTrade struct {
+	ID           int64  `json:"id"`
+	Price        string `json:"price"`
+	Qty          string `json:"qty"`
+	QuoteQty     string `json:"quoteQty"`
+	Time         int64  `json:"time"`
+	IsBuyerMaker bool   `json:"isBuyerMaker"` // The taker sold
+	IsBestMatch  bool   `json:"isBestMatch"`
+}
+
+// Commission rates for an order, from order.test with computeCommissionRates
+type OrderTestResult struct {
+	StandardCommissionForOrder CommissionRates `json:"standardCommissionForOrder"`
+	TaxCommissionForOrder      CommissionRates `json:"taxCommissionForOrder"`
+	Discount                   struct {
+		EnabledForAccount bool   `json:"enabledForAccount"`
+		EnabledForSymbol  bool   `json:"enabledForSymbol"`
+		DiscountAsset     string `json:"discountAsset"`
+		Discount          string `json:"discount"`
+	} `json:"discount"`
+}
+
+// Maker and taker commission as fractions of the traded amount
+type CommissionRates struct {
+	Maker string `json:"maker"`
+	Taker string `json:"taker"`
+}
+
+// Optional bounds for a klines query, zero values are left to the exchange
+type KlineQuery struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int    // Most candles returned, up to 1000 (default 500)
+	TimeZone  string // Offset for interval boundaries, e.g. "+08:00" (default UTC)
+}
+
+// OHLCV bar from klines or built locally from trades
+type Candle struct {
+	Symbol              string          `json:"symbol"`
+	OpenTime            time.Time       `json:"openTime"`
+	CloseTime           time.Time       `json:"closeTime"`
+	Open                decimal.Decimal `json:"open"`
+	High                decimal.Decimal `json:"high"`
+	Low                 decimal.Decimal `json:"low"`
+	Close               decimal.Decimal `json:"close"`
+	Volume              decimal.Decimal `json:"volume"`      // Base asset traded
+	QuoteVolume         decimal.Decimal `json:"quoteVolume"` // Quote asset traded
+	Trades              int64           `json:"trades"`
+	TakerBuyVolume      decimal.Decimal `json:"takerBuyVolume"`      // Base asset bought by takers
+	TakerBuyQuoteVolume decimal.Decimal `json:"takerBuyQuoteVolume"` // Quote asset spent by takers
+	Closed              bool            `json:"closed"`              // False while the bar is still forming
 }
 
+// Trading rules from exchangeInfo
+type ExchangeInfo struct {
+	Timezone   string       `json:"timezone"`
+	ServerTime int64        `json:"serverTime"`
+	RateLimits []RateLimit  `json:"rateLimits"`
+	Symbols    []SymbolInfo `json:"symbols"`
+}
+
+// Trading rules for a single symbol
+type SymbolInfo struct {
+	Symbol              string         `json:"symbol"`
+	Status              string         `json:"status"`
+	BaseAsset           string         `json:"baseAsset"`
+	BaseAssetPrecision  int            `json:"baseAssetPrecision"`
+	QuoteAsset          string         `json:"quoteAsset"`
+	QuoteAssetPrecision int            `json:"quoteAssetPrecision"`
+	OrderTypes          []string       `json:"orderTypes"`
+	Filters             []SymbolFilter `json:"filters"`
+}
+
+// Symbol filter. Only the fields for its FilterType are set.
+type SymbolFilter struct {
+	FilterType  string `json:"filterType"`
+	MinPrice    string `json:"minPrice,omitempty"`    // PRICE_FILTER
+	MaxPrice    string `json:"maxPrice,omitempty"`    // PRICE_FILTER
+	TickSize    string `json:"tickSize,omitempty"`    // PRICE_FILTER
+	MinQty      string `json:"minQty,omitempty"`      // LOT_SIZE, MARKET_LOT_SIZE
+	MaxQty      string `json:"maxQty,omitempty"`      // LOT_SIZE, MARKET_LOT_SIZE
+	StepSize    string `json:"stepSize,omitempty"`    // LOT_SIZE, MARKET_LOT_SIZE
+	MinNotional string `json:"minNotional,omitempty"` // NOTIONAL, MIN_NOTIONAL
+	MaxNotional string `json:"maxNotional,omitempty"` // NOTIONAL
+}
+
+// Filter of the given type, if the symbol has one
+func (s SymbolInfo) Filter(filterType string) (SymbolFilter, bool) {
+	for _, filter := range s.Filters {
+		if filter.FilterType == filterType {
+			return filter, true
+		}
+	}
+
+	return SymbolFilter{}, false
+}
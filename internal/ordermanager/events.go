@@ -0,0 +1,62 @@
+package ordermanager
+
+import (
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Kind of change to a tracked order
+type EventType string
+
+const (
+	EventTracked EventType = "tracked" // Order placed and tracked for the first time
+	EventUpdated EventType = "updated" // New status or fill for a tracked order
+	EventRemoved EventType = "removed" // Order no longer tracked
+)
+
+// Events buffered per subscriber before further events are dropped
+const eventBuffer = 64
+
+// Change to a tracked order
+type OrderEvent struct {
+	Type  EventType    `json:"type"`
+	Order models.Order `json:"order"`
+	Time  time.Time    `json:"time"`
+}
+
+// Receive an event for every change to a tracked order until the returned
+// function is called. Events are dropped for subscribers that fall behind,
+// so order tracking never waits on a slow reader.
+func (m *Manager) Subscribe() (<-chan OrderEvent, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.nextSubscriber
+	m.nextSubscriber++
+
+	events := make(chan OrderEvent, eventBuffer)
+	m.subscribers[id] = events
+
+	return events, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if _, exists := m.subscribers[id]; exists {
+			delete(m.subscribers, id)
+			close(events)
+		}
+	}
+}
+
+// Send an event to every subscriber, called with mu held
+func (m *Manager) publish(eventType EventType, order models.Order) {
+	event := OrderEvent{Type: eventType, Order: order, Time: time.Now()}
+
+	for _, events := range m.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
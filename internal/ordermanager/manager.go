@@ -2,7 +2,7 @@ package ordermanager
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -17,17 +17,21 @@ type OrderState struct {
 	Updated        bool      // Whether the order has been updated
 }
 
-// Track and manage orders
+// Track and manage orders across symbols
 type Manager struct {
-	orders       map[int64]*OrderState  // Map of orderID to OrderState
-	clientOrders map[string]*OrderState // Map of clientOrderID to OrderState
-	mu           sync.RWMutex           // Mutex for thread safety
+	orders       map[string]map[int64]*OrderState // Map of symbol to orderID to OrderState
+	clientOrders map[string]*OrderState           // Map of clientOrderID to OrderState
+	mu           sync.RWMutex                     // Mutex for thread safety
+
+	subscribers    map[int]chan OrderEvent // Order event subscribers by ID
+	nextSubscriber int
 }
 
 func New() *Manager {
 	return &Manager{
-		orders:       make(map[int64]*OrderState),
+		orders:       make(map[string]map[int64]*OrderState),
 		clientOrders: make(map[string]*OrderState),
+		subscribers:  make(map[int]chan OrderEvent),
 	}
 }
 
@@ -43,15 +47,22 @@ func (m *Manager) TrackOrder(order *models.Order) {
 		Updated:        false,
 	}
 
-	// Store by order ID
-	m.orders[order.OrderID] = state
+	// Store by symbol and order ID, order IDs are only unique per symbol
+	symbolOrders, exists := m.orders[order.Symbol]
+	if !exists {
+		symbolOrders = make(map[int64]*OrderState)
+		m.orders[order.Symbol] = symbolOrders
+	}
+	symbolOrders[order.OrderID] = state
 
 	// Also store by client order ID if available
 	if order.ClientOrderID != "" {
 		m.clientOrders[order.ClientOrderID] = state
 	}
 
-	log.Printf("Tracking new order: %d (%s)", order.OrderID, order.ClientOrderID)
+	m.publish(EventTracked, state.Order)
+
+	slog.Info("Tracking new order", "symbol", order.Symbol, "orderId", order.OrderID, "clientOrderId", order.ClientOrderID)
 }
 
 // Update an existing order
@@ -59,7 +70,7 @@ func (m *Manager) UpdateOrder(order *models.Order) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	state, exists := m.orders[order.OrderID]
+	state, exists := m.orders[order.Symbol][order.OrderID]
 	if !exists {
 		state, exists = m.clientOrders[order.ClientOrderID]
 		if !exists {
@@ -71,23 +82,24 @@ func (m *Manager) UpdateOrder(order *models.Order) error {
 	state.LastUpdateTime = time.Now()
 	state.Updated = true
 
-	log.Printf("Updated order %d (%s) status: %s", order.OrderID, order.ClientOrderID, order.Status)
+	m.publish(EventUpdated, state.Order)
+
+	slog.Info("Updated order", "symbol", order.Symbol, "orderId", order.OrderID, "clientOrderId", order.ClientOrderID, "status", order.Status)
 	return nil
 }
 
 // Retrieve an order
-func (m *Manager) GetOrder(orderID int64) (*models.Order, error) {
+func (m *Manager) GetOrder(symbol string, orderID int64) (*models.Order, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	state, exists := m.orders[orderID]
+	state, exists := m.orders[symbol][orderID]
 	if !exists {
-		if !exists {
-			return nil, fmt.Errorf("order not found: %d", orderID)
-		}
+		return nil, fmt.Errorf("order not found: %s %d", symbol, orderID)
 	}
 
-	return &state.Order, nil
+	order := state.Order
+	return &order, nil
 }
 
 // Retrieve an order by client ID
@@ -97,12 +109,11 @@ func (m *Manager) GetClientOrders(clientOrderID string) (*models.Order, error) {
 
 	state, exists := m.clientOrders[clientOrderID]
 	if !exists {
-		if !exists {
-			return nil, fmt.Errorf("order not found: %s", clientOrderID)
-		}
+		return nil, fmt.Errorf("order not found: %s", clientOrderID)
 	}
 
-	return &state.Order, nil
+	order := state.Order
+	return &order, nil
 }
 
 // Retrieve all orders
@@ -110,23 +121,53 @@ func (m *Manager) GetAllOrders() []models.Order {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	orders := make([]models.Order, 0, len(m.orders))
-	for _, state := range m.orders {
+	orders := make([]models.Order, 0, len(m.clientOrders))
+	for _, symbolOrders := range m.orders {
+		for _, state := range symbolOrders {
+			orders = append(orders, state.Order)
+		}
+	}
+
+	return orders
+}
+
+// Retrieve all orders for a symbol
+func (m *Manager) GetOrdersBySymbol(symbol string) []models.Order {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	orders := make([]models.Order, 0, len(m.orders[symbol]))
+	for _, state := range m.orders[symbol] {
 		orders = append(orders, state.Order)
 	}
 
 	return orders
 }
 
+// Symbols with at least one tracked order
+func (m *Manager) GetSymbols() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	symbols := make([]string, 0, len(m.orders))
+	for symbol := range m.orders {
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
+
 // Return all orders with the specified status
 func (m *Manager) GetOrdersByStatus(status models.OrderStatus) []models.Order {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	orders := make([]models.Order, 0, len(m.orders))
-	for _, state := range m.orders {
-		if models.OrderStatus(state.Order.Status) == status {
-			orders = append(orders, state.Order)
+	orders := make([]models.Order, 0)
+	for _, symbolOrders := range m.orders {
+		for _, state := range symbolOrders {
+			if models.OrderStatus(state.Order.Status) == status {
+				orders = append(orders, state.Order)
+			}
 		}
 	}
 
@@ -143,21 +184,36 @@ func (m *Manager) GetOrdersByStatuses(statuses []models.OrderStatus) []models.Or
 		statusSet[status] = struct{}{}
 	}
 
-	orders := make([]models.Order, 0, len(m.orders))
-	for _, state := range m.orders {
-		if _, exists := statusSet[models.OrderStatus(state.Order.Status)]; exists {
-			orders = append(orders, state.Order)
+	orders := make([]models.Order, 0)
+	for _, symbolOrders := range m.orders {
+		for _, state := range symbolOrders {
+			if _, exists := statusSet[models.OrderStatus(state.Order.Status)]; exists {
+				orders = append(orders, state.Order)
+			}
 		}
 	}
 
 	return orders
 }
 
-// Return all active orders (not filled, canceled, or rejected)
-func (m *Manager) GetActiveOrders() []models.Order {
+// Return active orders for a single symbol
+func (m *Manager) GetActiveOrdersBySymbol(symbol string) []models.Order {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	orders := make([]models.Order, 0)
+	for _, state := range m.orders[symbol] {
+		status := models.OrderStatus(state.Order.Status)
+		if status == models.OrderStatusNew || status == models.OrderStatusPartiallyFilled {
+			orders = append(orders, state.Order)
+		}
+	}
+
+	return orders
+}
+
+// Return all active orders (not filled, canceled, or rejected)
+func (m *Manager) GetActiveOrders() []models.Order {
 	return m.GetOrdersByStatuses([]models.OrderStatus{models.OrderStatusNew, models.OrderStatusPartiallyFilled})
 }
 
@@ -172,60 +228,63 @@ func (m *Manager) GetCanceledOrders() []models.Order {
 }
 
 // Remove an order from tracking
-func (m *Manager) RemoveOrder(orderID int64) error {
+func (m *Manager) RemoveOrder(symbol string, orderID int64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	order, err := m.GetOrder(orderID)
-	if err != nil {
-		return err
+	state, exists := m.orders[symbol][orderID]
+	if !exists {
+		return fmt.Errorf("order not found: %s %d", symbol, orderID)
 	}
 
-	delete(m.orders, orderID)
-	if order.ClientOrderID != "" {
-		delete(m.clientOrders, order.ClientOrderID)
+	delete(m.orders[symbol], orderID)
+	if len(m.orders[symbol]) == 0 {
+		delete(m.orders, symbol)
+	}
+
+	if state.Order.ClientOrderID != "" {
+		delete(m.clientOrders, state.Order.ClientOrderID)
 	}
 
-	log.Printf("Removed order from tracking: %d", orderID)
+	m.publish(EventRemoved, state.Order)
+
+	slog.Info("Removed order from tracking", "symbol", symbol, "orderId", orderID)
 	return nil
 }
 
 // Print a summary of the current orders
 func (m *Manager) PrintOrderSummary() {
 	if m == nil {
-		log.Println("Warning: Order manager is nil")
+		slog.Warn("Order manager is nil")
 		return
 	}
 
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	orders := m.GetAllOrders()
 
-	log.Println("===== ORDER SUMMARY =====")
-	log.Printf("Total Orders: %d", len(m.orders))
+	slog.Info("Order summary", "total", len(orders))
 
-	// Count by status
-	statusCounts := make(map[string]int)
-	for _, state := range m.orders {
-		if state == nil {
-			continue
+	// Count by symbol and status
+	statusCounts := make(map[string]map[string]int)
+	for _, order := range orders {
+		if statusCounts[order.Symbol] == nil {
+			statusCounts[order.Symbol] = make(map[string]int)
 		}
-		statusCounts[state.Order.Status]++
+		statusCounts[order.Symbol][order.Status]++
 	}
 
-	for status, count := range statusCounts {
-		log.Printf("Status %s: %d orders", status, count)
+	for symbol, counts := range statusCounts {
+		for status, count := range counts {
+			slog.Info("Orders", "symbol", symbol, "status", status, "count", count)
+		}
 	}
 
-	log.Println("=========================")
-
 	filledOrders := m.GetOrdersByStatus("FILLED")
 	if len(filledOrders) > 0 {
-		log.Printf("Found %d filled orders", len(filledOrders))
+		slog.Info("Found filled orders", "count", len(filledOrders))
 
 		// For now, just log them
 		for _, order := range filledOrders {
-			log.Printf("Filled order: %d, Side: %s, Qty: %s, Price: %s",
-				order.OrderID, order.Side, order.ExecutedQty, order.Price)
+			slog.Debug("Filled order", "symbol", order.Symbol, "orderId", order.OrderID, "side", order.Side, "quantity", order.ExecutedQty, "price", order.Price)
 		}
 	}
 }
@@ -1,9 +1,12 @@
 package ordermanager
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/iamramtin/binance-trader/internal/decimal"
 	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestTrackOrder(t *testing.T) {
@@ -24,7 +27,7 @@ func TestTrackOrder(t *testing.T) {
 	manager.TrackOrder(order)
 
 	// Verify the order was tracked
-	trackedOrder, err := manager.GetOrder(12345)
+	trackedOrder, err := manager.GetOrder("BTCUSDT", 12345)
 	if err != nil {
 		t.Errorf("GetOrder() returned error: %v", err)
 	}
@@ -72,7 +75,7 @@ func TestUpdateOrder(t *testing.T) {
 	}
 
 	// Verify the order was updated
-	trackedOrder, err := manager.GetOrder(12345)
+	trackedOrder, err := manager.GetOrder("BTCUSDT", 12345)
 	if err != nil {
 		t.Errorf("GetOrder() returned error: %v", err)
 	}
@@ -118,3 +121,131 @@ func TestGetOrdersByStatus(t *testing.T) {
 		t.Errorf("GetOrdersByStatus(\"CANCELED\") returned %d orders; want 1", len(canceledOrders))
 	}
 }
+
+func TestOrdersAreIndexedBySymbol(t *testing.T) {
+	manager := New()
+
+	// Order IDs are only unique per symbol
+	manager.TrackOrder(&models.Order{OrderID: 1, Status: "NEW", Symbol: "BTCUSDT", Side: "BUY"})
+	manager.TrackOrder(&models.Order{OrderID: 1, Status: "FILLED", Symbol: "ETHUSDT", Side: "SELL"})
+	manager.TrackOrder(&models.Order{OrderID: 2, Status: "NEW", Symbol: "ETHUSDT", Side: "BUY"})
+
+	btcOrder, err := manager.GetOrder("BTCUSDT", 1)
+	if err != nil || btcOrder.Side != "BUY" {
+		t.Errorf("GetOrder(\"BTCUSDT\", 1) = %v, %v; want BUY order", btcOrder, err)
+	}
+
+	ethOrder, err := manager.GetOrder("ETHUSDT", 1)
+	if err != nil || ethOrder.Side != "SELL" {
+		t.Errorf("GetOrder(\"ETHUSDT\", 1) = %v, %v; want SELL order", ethOrder, err)
+	}
+
+	if orders := manager.GetOrdersBySymbol("ETHUSDT"); len(orders) != 2 {
+		t.Errorf("GetOrdersBySymbol(\"ETHUSDT\") returned %d orders; want 2", len(orders))
+	}
+
+	if orders := manager.GetActiveOrdersBySymbol("ETHUSDT"); len(orders) != 1 {
+		t.Errorf("GetActiveOrdersBySymbol(\"ETHUSDT\") returned %d orders; want 1", len(orders))
+	}
+
+	if err := manager.RemoveOrder("ETHUSDT", 1); err != nil {
+		t.Errorf("RemoveOrder() returned error: %v", err)
+	}
+
+	if _, err := manager.GetOrder("BTCUSDT", 1); err != nil {
+		t.Errorf("RemoveOrder() removed the wrong symbol's order: %v", err)
+	}
+
+	if len(manager.GetAllOrders()) != 2 {
+		t.Errorf("GetAllOrders() returned %d orders; want 2", len(manager.GetAllOrders()))
+	}
+}
+
+func TestSubscribeReceivesOrderEvents(t *testing.T) {
+	manager := New()
+	events, unsubscribe := manager.Subscribe()
+
+	order := &models.Order{Symbol: "BTCUSDT", OrderID: 1, Status: "NEW"}
+	manager.TrackOrder(order)
+
+	order.Status = "FILLED"
+	if err := manager.UpdateOrder(order); err != nil {
+		t.Fatalf("UpdateOrder() returned error: %v", err)
+	}
+
+	if err := manager.RemoveOrder("BTCUSDT", 1); err != nil {
+		t.Fatalf("RemoveOrder() returned error: %v", err)
+	}
+
+	for _, want := range []EventType{EventTracked, EventUpdated, EventRemoved} {
+		if event := <-events; event.Type != want || event.Order.OrderID != 1 {
+			t.Errorf("expected %s event for order 1, got %s for %d", want, event.Type, event.Order.OrderID)
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+
+	if _, open := <-events; open {
+		t.Error("expected the channel to be closed after unsubscribing")
+	}
+
+	// Publishing without subscribers must not block
+	manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 2})
+}
+
+func TestPosition(t *testing.T) {
+	manager := New()
+	manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 1, Side: "BUY", ExecutedQty: "2", CummulativeQuoteQty: "200", Status: "FILLED"})
+	manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 2, Side: "SELL", ExecutedQty: "0.5", CummulativeQuoteQty: "55", Status: "CANCELED"})
+	manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 3, Side: "SELL", ExecutedQty: "0", CummulativeQuoteQty: "0", Status: "NEW"})
+	manager.TrackOrder(&models.Order{Symbol: "ETHUSDT", OrderID: 1, Side: "BUY", ExecutedQty: "1", CummulativeQuoteQty: "10", Status: "FILLED"})
+
+	p := manager.Position("BTCUSDT")
+
+	if p.Base.String() != "1.5" || p.Quote.String() != "-145" || p.Fills != 2 {
+		t.Errorf("unexpected position: base %s quote %s fills %d", p.Base, p.Quote, p.Fills)
+	}
+
+	if pnl := p.PnL(decimal.NewFromInt(100)); pnl.String() != "5" {
+		t.Errorf("expected PnL 5 at 100, got %s", pnl)
+	}
+
+	// Meme coin quantities add up past what int64 units could hold
+	manager.TrackOrder(&models.Order{Symbol: "PEPEUSDT", OrderID: 1, Side: "BUY", ExecutedQty: "90000000000", CummulativeQuoteQty: "900000", Status: "FILLED"})
+	manager.TrackOrder(&models.Order{Symbol: "PEPEUSDT", OrderID: 2, Side: "BUY", ExecutedQty: "90000000000", CummulativeQuoteQty: "900000", Status: "FILLED"})
+
+	if p := manager.Position("PEPEUSDT"); p.Base.String() != "180000000000" || p.Quote.String() != "-1800000" {
+		t.Errorf("unexpected position: base %s quote %s", p.Base, p.Quote)
+	}
+}
+
+func TestCollectOrderMetrics(t *testing.T) {
+	manager := New()
+	manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 1, Side: "BUY", ExecutedQty: "2", CummulativeQuoteQty: "200", Status: "FILLED"})
+	manager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 2, Side: "SELL", ExecutedQty: "0.5", CummulativeQuoteQty: "55", Status: "PARTIALLY_FILLED"})
+	manager.TrackOrder(&models.Order{Symbol: "ETHUSDT", OrderID: 1, Side: "BUY", ExecutedQty: "0", CummulativeQuoteQty: "0", Status: "CANCELED"})
+
+	expected := `
+# HELP binance_executed_quantity Base asset executed across tracked orders.
+# TYPE binance_executed_quantity gauge
+binance_executed_quantity{side="BUY",symbol="BTCUSDT"} 2
+binance_executed_quantity{side="BUY",symbol="ETHUSDT"} 0
+binance_executed_quantity{side="SELL",symbol="BTCUSDT"} 0.5
+# HELP binance_open_orders Tracked orders that are new or partially filled.
+# TYPE binance_open_orders gauge
+binance_open_orders{symbol="BTCUSDT"} 1
+binance_open_orders{symbol="ETHUSDT"} 0
+# HELP binance_orders Tracked orders by symbol and status.
+# TYPE binance_orders gauge
+binance_orders{status="CANCELED",symbol="ETHUSDT"} 1
+binance_orders{status="FILLED",symbol="BTCUSDT"} 1
+binance_orders{status="PARTIALLY_FILLED",symbol="BTCUSDT"} 1
+`
+
+	err := testutil.CollectAndCompare(manager, strings.NewReader(expected),
+		"binance_executed_quantity", "binance_open_orders", "binance_orders")
+	if err != nil {
+		t.Error(err)
+	}
+}
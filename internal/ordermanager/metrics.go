@@ -0,0 +1,81 @@
+package ordermanager
+
+import (
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/metrics"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ordersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "orders"),
+		"Tracked orders by symbol and status.",
+		[]string{"symbol", "status"}, nil)
+
+	openOrdersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "open_orders"),
+		"Tracked orders that are new or partially filled.",
+		[]string{"symbol"}, nil)
+
+	executedQuantityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "executed_quantity"),
+		"Base asset executed across tracked orders.",
+		[]string{"symbol", "side"}, nil)
+
+	executedQuoteDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "", "executed_quote_quantity"),
+		"Quote asset executed across tracked orders.",
+		[]string{"symbol", "side"}, nil)
+)
+
+func (m *Manager) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ordersDesc
+	ch <- openOrdersDesc
+	ch <- executedQuantityDesc
+	ch <- executedQuoteDesc
+}
+
+// Report order counts and executed volume at scrape time
+func (m *Manager) Collect(ch chan<- prometheus.Metric) {
+	type key struct{ symbol, label string }
+
+	counts := make(map[key]int)
+	open := make(map[string]int)
+	executed := make(map[key]decimal.Decimal)
+	quote := make(map[key]decimal.Decimal)
+
+	for _, order := range m.GetAllOrders() {
+		counts[key{order.Symbol, order.Status}]++
+
+		// Report zero rather than nothing for symbols whose orders are all closed
+		open[order.Symbol] += 0
+		if status := models.OrderStatus(order.Status); status == models.OrderStatusNew || status == models.OrderStatusPartiallyFilled {
+			open[order.Symbol]++
+		}
+
+		side := key{order.Symbol, order.Side}
+		if qty, err := decimal.NewFromString(order.ExecutedQty); err == nil {
+			executed[side] = executed[side].Add(qty)
+		}
+		if qty, err := decimal.NewFromString(order.CummulativeQuoteQty); err == nil {
+			quote[side] = quote[side].Add(qty)
+		}
+	}
+
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(ordersDesc, prometheus.GaugeValue, float64(count), k.symbol, k.label)
+	}
+
+	for symbol, count := range open {
+		ch <- prometheus.MustNewConstMetric(openOrdersDesc, prometheus.GaugeValue, float64(count), symbol)
+	}
+
+	for k, qty := range executed {
+		ch <- prometheus.MustNewConstMetric(executedQuantityDesc, prometheus.GaugeValue, qty.Float64(), k.symbol, k.label)
+	}
+
+	for k, qty := range quote {
+		ch <- prometheus.MustNewConstMetric(executedQuoteDesc, prometheus.GaugeValue, qty.Float64(), k.symbol, k.label)
+	}
+}
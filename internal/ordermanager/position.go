@@ -0,0 +1,53 @@
+package ordermanager
+
+import (
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Net result of the tracked fills on one symbol
+type Position struct {
+	Symbol string
+	Base   decimal.Decimal // Base asset bought minus sold
+	Quote  decimal.Decimal // Quote asset received minus spent
+	Fills  int             // Orders with any executed quantity
+}
+
+// Add up the executed part of every tracked order on a symbol,
+// including partially filled and canceled ones
+func (m *Manager) Position(symbol string) Position {
+	p := Position{Symbol: symbol}
+
+	for _, order := range m.GetOrdersBySymbol(symbol) {
+		p.add(order)
+	}
+
+	return p
+}
+
+func (p *Position) add(order models.Order) {
+	executed, err := decimal.NewFromString(order.ExecutedQty)
+	if err != nil || executed.IsZero() {
+		return
+	}
+
+	quote, err := decimal.NewFromString(order.CummulativeQuoteQty)
+	if err != nil {
+		return
+	}
+
+	p.Fills++
+
+	if order.Side == "BUY" {
+		p.Base = p.Base.Add(executed)
+		p.Quote = p.Quote.Sub(quote)
+	} else {
+		p.Base = p.Base.Sub(executed)
+		p.Quote = p.Quote.Add(quote)
+	}
+}
+
+// Profit in the quote asset if the position were closed at mark
+func (p Position) PnL(mark decimal.Decimal) decimal.Decimal {
+	return p.Quote.Add(p.Base.Mul(mark))
+}
@@ -0,0 +1,192 @@
+package secrets
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// Time allowed for one signing agent request
+const agentTimeout = 2 * time.Second
+
+// Signing agent protocol: one JSON request per line on a Unix socket, each
+// answered by one JSON response line. The agent holds the secret key, so the
+// trader only ever sees signatures.
+type agentRequest struct {
+	Op      string `json:"op"` // "apiKey" or "sign"
+	Payload string `json:"payload,omitempty"`
+}
+
+type agentResponse struct {
+	APIKey    string `json:"apiKey,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Client of a signing agent listening on a Unix socket
+type Agent struct {
+	path   string
+	apiKey string
+	conn   net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex
+}
+
+// Connect to the agent at path and fetch the API key it signs for
+func DialAgent(path string) (*Agent, error) {
+	a := &Agent{path: path}
+
+	response, err := a.roundTrip(agentRequest{Op: "apiKey"})
+	if err != nil {
+		return nil, fmt.Errorf("signing agent %s: %w", path, err)
+	}
+
+	if response.APIKey == "" {
+		a.Close()
+		return nil, fmt.Errorf("signing agent %s: no API key", path)
+	}
+
+	a.apiKey = response.APIKey
+	return a, nil
+}
+
+func (a *Agent) APIKey() string {
+	return a.apiKey
+}
+
+func (a *Agent) Sign(payload string) (string, error) {
+	response, err := a.roundTrip(agentRequest{Op: "sign", Payload: payload})
+	if err != nil {
+		return "", fmt.Errorf("signing agent %s: %w", a.path, err)
+	}
+
+	return response.Signature, nil
+}
+
+func (a *Agent) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.disconnect()
+}
+
+// Send a request, reconnecting once if the connection was lost
+func (a *Agent) roundTrip(request agentRequest) (agentResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var response agentResponse
+		if response, err = a.send(request); err == nil {
+			if response.Error != "" {
+				return response, errors.New(response.Error)
+			}
+
+			return response, nil
+		}
+
+		a.disconnect()
+	}
+
+	return agentResponse{}, err
+}
+
+func (a *Agent) send(request agentRequest) (agentResponse, error) {
+	if a.conn == nil {
+		conn, err := net.DialTimeout("unix", a.path, agentTimeout)
+		if err != nil {
+			return agentResponse{}, err
+		}
+
+		a.conn, a.reader = conn, bufio.NewReader(conn)
+	}
+
+	a.conn.SetDeadline(time.Now().Add(agentTimeout))
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return agentResponse{}, err
+	}
+
+	if _, err := a.conn.Write(append(data, '\n')); err != nil {
+		return agentResponse{}, err
+	}
+
+	line, err := a.reader.ReadBytes('\n')
+	if err != nil {
+		return agentResponse{}, err
+	}
+
+	var response agentResponse
+	if err := json.Unmarshal(line, &response); err != nil {
+		return agentResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return response, nil
+}
+
+// Close the connection, called with mu held
+func (a *Agent) disconnect() error {
+	if a.conn == nil {
+		return nil
+	}
+
+	err := a.conn.Close()
+	a.conn, a.reader = nil, nil
+	return err
+}
+
+// Answer signing agent requests on listener with provider's key until the listener is closed
+func Serve(listener net.Listener, provider Provider) error {
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		go serveConn(conn, provider)
+	}
+}
+
+func serveConn(conn net.Conn, provider Provider) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		var request agentRequest
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+
+		var response agentResponse
+		switch request.Op {
+		case "apiKey":
+			response.APIKey = provider.APIKey()
+		case "sign":
+			signature, err := provider.Sign(request.Payload)
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response.Signature = signature
+			slog.Debug("Signed request")
+		default:
+			response.Error = fmt.Sprintf("unknown op %q", request.Op)
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
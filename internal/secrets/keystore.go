@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Returned when a keystore cannot be decrypted with the passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted keystore")
+
+const (
+	keystoreVersion = 1
+	keystoreKDF     = "pbkdf2-sha256"
+	saltSize        = 16
+	keySize         = 32 // AES-256
+)
+
+// PBKDF2 rounds for new keystores. Existing keystores record their own.
+var keystoreIterations = 600_000
+
+// Keys encrypted with AES-256-GCM under a key derived from a passphrase
+type keystoreFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Encrypt keys into a new keystore file, readable only by its owner.
+// An existing file is never overwritten.
+func CreateKeystore(path string, keys Keys, passphrase string) error {
+	if keys.APIKey == "" || keys.SecretKey == "" {
+		return errors.New("both keys are required")
+	}
+	if passphrase == "" {
+		return errors.New("passphrase must not be empty")
+	}
+
+	plaintext, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	store := keystoreFile{
+		Version:    keystoreVersion,
+		KDF:        keystoreKDF,
+		Iterations: keystoreIterations,
+		Salt:       make([]byte, saltSize),
+	}
+	if _, err := rand.Read(store.Salt); err != nil {
+		return err
+	}
+
+	aead, err := store.cipher(passphrase)
+	if err != nil {
+		return err
+	}
+
+	store.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(store.Nonce); err != nil {
+		return err
+	}
+	store.Ciphertext = aead.Seal(nil, store.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create keystore: %w", err)
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+
+	return file.Close()
+}
+
+// Decrypt the keys in a keystore file
+func OpenKeystore(path, passphrase string) (Keys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Keys{}, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var store keystoreFile
+	if err := json.Unmarshal(data, &store); err != nil {
+		return Keys{}, fmt.Errorf("keystore %s: %w", path, err)
+	}
+
+	if store.Version != keystoreVersion || store.KDF != keystoreKDF || store.Iterations < 1 {
+		return Keys{}, fmt.Errorf("keystore %s: unsupported version %d with %s", path, store.Version, store.KDF)
+	}
+
+	aead, err := store.cipher(passphrase)
+	if err != nil {
+		return Keys{}, err
+	}
+
+	if len(store.Nonce) != aead.NonceSize() {
+		return Keys{}, ErrWrongPassphrase
+	}
+
+	plaintext, err := aead.Open(nil, store.Nonce, store.Ciphertext, nil)
+	if err != nil {
+		return Keys{}, ErrWrongPassphrase
+	}
+
+	var keys Keys
+	if err := json.Unmarshal(plaintext, &keys); err != nil {
+		return Keys{}, ErrWrongPassphrase
+	}
+
+	return keys, nil
+}
+
+func (s *keystoreFile) cipher(passphrase string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2([]byte(passphrase), s.Salt, s.Iterations, keySize))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// PBKDF2 with HMAC-SHA-256 from RFC 8018
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	blocks := (keyLen + prf.Size() - 1) / prf.Size()
+
+	var key []byte
+	counter := make([]byte, 4)
+
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
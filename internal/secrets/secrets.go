@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+// API key and request signatures, wherever the secret key is kept
+type Provider interface {
+	APIKey() string
+	Sign(payload string) (string, error)
+}
+
+// Exchange API key pair
+type Keys struct {
+	APIKey    string `json:"apiKey"`
+	SecretKey string `json:"secretKey"`
+}
+
+// Credentials held in memory, from the environment, the config file, secret
+// files or a keystore
+type Static struct {
+	apiKey string
+	signer utils.HMACSigner
+}
+
+func NewStatic(keys Keys) *Static {
+	return &Static{apiKey: keys.APIKey, signer: utils.HMACSigner(keys.SecretKey)}
+}
+
+func (s *Static) APIKey() string {
+	return s.apiKey
+}
+
+func (s *Static) Sign(payload string) (string, error) {
+	return s.signer.Sign(payload)
+}
+
+// Read a secret from a file such as a Docker secret under /run/secrets,
+// ignoring surrounding whitespace
+func ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+var testKeys = Keys{APIKey: "test-api-key", SecretKey: "test-secret-key"}
+
+func TestPBKDF2(t *testing.T) {
+	// Test vectors for PBKDF2-HMAC-SHA256 from RFC 7914
+	tests := []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, tt := range tests {
+		if got := hex.EncodeToString(pbkdf2([]byte("password"), []byte("salt"), tt.iterations, 32)); got != tt.want {
+			t.Errorf("pbkdf2 with %d iterations = %s, want %s", tt.iterations, got, tt.want)
+		}
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	keystoreIterations = 1000
+	t.Cleanup(func() { keystoreIterations = 600_000 })
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := CreateKeystore(path, testKeys, "correct horse"); err != nil {
+		t.Fatalf("CreateKeystore() returned error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected a file readable only by its owner, got %v (%v)", info.Mode(), err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), testKeys.SecretKey) {
+		t.Error("keystore contains the secret key in plain text")
+	}
+
+	keys, err := OpenKeystore(path, "correct horse")
+	if err != nil || keys != testKeys {
+		t.Errorf("OpenKeystore() = %+v, %v", keys, err)
+	}
+
+	if _, err := OpenKeystore(path, "wrong horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	if err := CreateKeystore(path, testKeys, "correct horse"); err == nil {
+		t.Error("expected an existing keystore not to be overwritten")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "binance_secret_key")
+	os.WriteFile(path, []byte("  secret\n"), 0o600)
+	if secret, err := ReadFile(path); err != nil || secret != "secret" {
+		t.Errorf("ReadFile() = %q, %v", secret, err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	os.WriteFile(empty, []byte("\n"), 0o600)
+	if _, err := ReadFile(empty); err == nil {
+		t.Error("expected an empty secret file to be rejected")
+	}
+}
+
+func TestAgentSignsWithoutTheSecretKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signer.sock")
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- Serve(listener, NewStatic(testKeys)) }()
+
+	agent, err := DialAgent(path)
+	if err != nil {
+		t.Fatalf("DialAgent() returned error: %v", err)
+	}
+	defer agent.Close()
+
+	if agent.APIKey() != testKeys.APIKey {
+		t.Errorf("expected the agent's API key, got %q", agent.APIKey())
+	}
+
+	params := map[string]string{"symbol": "BTCUSDT", "timestamp": "1"}
+	got, err := utils.GenerateSignature(agent, params)
+	want, _ := utils.GenerateSignature(utils.HMACSigner(testKeys.SecretKey), params)
+	if err != nil || got != want {
+		t.Errorf("agent signature = %q, %v, want %q", got, err, want)
+	}
+
+	// A dropped connection is redialed
+	agent.conn.Close()
+	if _, err := agent.Sign("timestamp=2"); err != nil {
+		t.Errorf("Sign() after a dropped connection returned error: %v", err)
+	}
+
+	listener.Close()
+	if err := <-served; err != nil {
+		t.Errorf("Serve() returned error: %v", err)
+	}
+}
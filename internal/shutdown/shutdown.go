@@ -0,0 +1,222 @@
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+	"github.com/iamramtin/binance-trader/internal/trader"
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+// Exchange operations needed to take every open order off the book
+type Client interface {
+	CancelAllOrders(symbol string) ([]models.Order, error)
+	GetOpenOrders(symbol string) ([]models.Order, error)
+	GetOrderManager() *ordermanager.Manager
+}
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultPollInterval = 500 * time.Millisecond
+)
+
+type Options struct {
+	Symbols      []string      // Symbols to flatten on top of those with tracked orders
+	Timeout      time.Duration // Time allowed to confirm every order is closed, 30 seconds when zero
+	PollInterval time.Duration // Time between open order checks, 500ms when zero
+	StateFile    string        // Write the report and final order state here when set
+	DryRun       bool          // Orders were simulated, recorded in the report
+}
+
+// Outcome of a shutdown
+type Report struct {
+	Time      time.Time         `json:"time"`
+	DryRun    bool              `json:"dryRun,omitempty"` // Orders were simulated and never reached the exchange
+	Canceled  []models.Order    `json:"canceled"`         // Orders canceled by the shutdown
+	Remaining []models.Order    `json:"remaining"`        // Orders still open when the deadline passed
+	Errors    map[string]string `json:"errors,omitempty"` // Symbols that could not be confirmed flat, with the last error
+	Orders    []models.Order    `json:"orders"`           // Every order tracked this session, in its final known state
+}
+
+// Whether every symbol was confirmed to have no open orders
+func (r Report) Clean() bool {
+	return len(r.Remaining) == 0 && len(r.Errors) == 0
+}
+
+// Stop strategies and take every open order off the book before the process exits
+type Coordinator struct {
+	client  Client
+	runner  *trader.Runner // Nil when no strategies are running
+	options Options
+}
+
+func New(client Client, runner *trader.Runner, options Options) *Coordinator {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+
+	return &Coordinator{client: client, runner: runner, options: options}
+}
+
+// Stop every strategy, cancel all open orders on every symbol and poll until
+// the exchange reports none left or the timeout passes. Symbols that still
+// have open orders get their cancel request repeated on every poll.
+func (c *Coordinator) Run(ctx context.Context) Report {
+	if c.runner != nil {
+		c.runner.StopAll()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.options.Timeout)
+	defer cancel()
+
+	report := Report{DryRun: c.options.DryRun, Canceled: []models.Order{}, Remaining: []models.Order{}}
+	open := make(map[string][]models.Order) // Last open orders seen per symbol
+	failures := make(map[string]error)      // Last error per symbol
+
+	pending := c.symbols()
+	slog.Info("Canceling every open order before exit", "symbols", pending, "timeout", c.options.Timeout)
+
+	for len(pending) > 0 {
+		for _, symbol := range pending {
+			canceled, err := c.client.CancelAllOrders(symbol)
+			report.Canceled = append(report.Canceled, canceled...)
+
+			// Canceling with nothing open is an error on Binance, so only the check below counts
+			if err != nil {
+				failures[symbol] = err
+			}
+		}
+
+		var unconfirmed []string
+		for _, symbol := range pending {
+			orders, err := c.client.GetOpenOrders(symbol)
+			if err != nil {
+				failures[symbol] = fmt.Errorf("failed to check open orders: %w", err)
+				unconfirmed = append(unconfirmed, symbol)
+				continue
+			}
+
+			if len(orders) > 0 {
+				open[symbol] = orders
+				unconfirmed = append(unconfirmed, symbol)
+				continue
+			}
+
+			delete(open, symbol)
+			delete(failures, symbol)
+			slog.Info("Confirmed no open orders", "symbol", symbol)
+		}
+
+		pending = unconfirmed
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, symbol := range pending {
+				report.Remaining = append(report.Remaining, open[symbol]...)
+
+				if err, ok := failures[symbol]; ok && len(open[symbol]) == 0 {
+					if report.Errors == nil {
+						report.Errors = make(map[string]string)
+					}
+					report.Errors[symbol] = err.Error()
+				}
+			}
+			pending = nil
+		case <-time.After(c.options.PollInterval):
+		}
+	}
+
+	report.Time = time.Now()
+	report.Orders = c.client.GetOrderManager().GetAllOrders()
+	sortOrders(report.Orders)
+
+	c.log(report)
+
+	if c.options.StateFile != "" {
+		if err := report.Save(c.options.StateFile); err != nil {
+			slog.Error("Failed to save final state", "path", c.options.StateFile, "error", err)
+		} else {
+			slog.Info("Saved final state", "path", c.options.StateFile)
+		}
+	}
+
+	return report
+}
+
+func (c *Coordinator) log(report Report) {
+	if report.Clean() {
+		slog.Info("Every open order is canceled", "canceled", len(report.Canceled))
+		return
+	}
+
+	for _, order := range report.Remaining {
+		slog.Error("Order left open on the exchange",
+			"symbol", order.Symbol, "orderId", order.OrderID, "clientOrderId", order.ClientOrderID,
+			"side", order.Side, "price", order.Price, "quantity", order.OrigQty, "status", order.Status)
+	}
+
+	for symbol, err := range report.Errors {
+		slog.Error("Could not confirm open orders are canceled", "symbol", symbol, "error", err)
+	}
+}
+
+// Configured symbols and symbols with tracked orders, sorted
+func (c *Coordinator) symbols() []string {
+	seen := make(map[string]bool)
+	var symbols []string
+
+	add := func(symbol string) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	for _, symbol := range c.options.Symbols {
+		add(symbol)
+	}
+
+	if c.runner != nil {
+		for _, symbol := range c.runner.Symbols() {
+			add(symbol)
+		}
+	}
+
+	for _, symbol := range c.client.GetOrderManager().GetSymbols() {
+		add(symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Write the report as JSON, replacing path only once the file is complete
+func (r Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	return utils.WriteFileAtomic(path, append(data, '\n'))
+}
+
+func sortOrders(orders []models.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Symbol != orders[j].Symbol {
+			return orders[i].Symbol < orders[j].Symbol
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+}
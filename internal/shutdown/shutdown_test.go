@@ -0,0 +1,168 @@
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+	"github.com/iamramtin/binance-trader/internal/trader"
+)
+
+// Exchange whose open orders are canceled by CancelAllOrders, except on stuck symbols
+type fakeClient struct {
+	open        map[string][]models.Order
+	stuck       map[string]bool // Cancel requests never take effect
+	statusErr   error
+	cancelCalls map[string]int
+	manager     *ordermanager.Manager
+	mu          sync.Mutex
+}
+
+func newFakeClient(orders ...models.Order) *fakeClient {
+	c := &fakeClient{
+		open:        make(map[string][]models.Order),
+		stuck:       make(map[string]bool),
+		cancelCalls: make(map[string]int),
+		manager:     ordermanager.New(),
+	}
+
+	for _, order := range orders {
+		c.open[order.Symbol] = append(c.open[order.Symbol], order)
+		c.manager.TrackOrder(&order)
+	}
+
+	return c
+}
+
+func (c *fakeClient) CancelAllOrders(symbol string) ([]models.Order, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cancelCalls[symbol]++
+
+	if len(c.open[symbol]) == 0 {
+		return nil, errors.New("API error: Unknown order sent.")
+	}
+
+	if c.stuck[symbol] {
+		return nil, errors.New("API error: timeout")
+	}
+
+	var canceled []models.Order
+	for _, order := range c.open[symbol] {
+		order.Status = "CANCELED"
+		c.manager.UpdateOrder(&order)
+		canceled = append(canceled, order)
+	}
+	delete(c.open, symbol)
+
+	return canceled, nil
+}
+
+func (c *fakeClient) GetOpenOrders(symbol string) ([]models.Order, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.statusErr != nil {
+		return nil, c.statusErr
+	}
+
+	return c.open[symbol], nil
+}
+
+func (c *fakeClient) GetOrderManager() *ordermanager.Manager {
+	return c.manager
+}
+
+type stubStrategy struct {
+	symbol string
+	active bool
+}
+
+func (s *stubStrategy) Symbol() string { return s.symbol }
+func (s *stubStrategy) Start()         { s.active = true }
+func (s *stubStrategy) Stop()          { s.active = false }
+func (s *stubStrategy) IsActive() bool { return s.active }
+
+func TestRunCancelsEveryOpenOrder(t *testing.T) {
+	client := newFakeClient(
+		models.Order{Symbol: "BTCUSDT", OrderID: 1, Side: "BUY", Status: "NEW"},
+		models.Order{Symbol: "BTCUSDT", OrderID: 2, Side: "SELL", Status: "NEW"},
+		models.Order{Symbol: "ETHUSDT", OrderID: 1, Side: "BUY", Status: "PARTIALLY_FILLED"},
+	)
+
+	strategy := &stubStrategy{symbol: "BNBUSDT", active: true}
+	runner := trader.NewRunner()
+	runner.Add(strategy)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	report := New(client, runner, Options{Symbols: []string{"SOLUSDT"}, StateFile: path}).Run(context.Background())
+
+	if strategy.active {
+		t.Error("expected strategies to be stopped")
+	}
+
+	if !report.Clean() || len(report.Canceled) != 3 {
+		t.Errorf("expected a clean shutdown canceling 3 orders, got %+v", report)
+	}
+
+	// Symbols without orders still get a cancel request, in case orders were never tracked
+	for _, symbol := range []string{"BNBUSDT", "BTCUSDT", "ETHUSDT", "SOLUSDT"} {
+		if client.cancelCalls[symbol] != 1 {
+			t.Errorf("expected one cancel request for %s, got %d", symbol, client.cancelCalls[symbol])
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected a state file: %v", err)
+	}
+
+	var saved Report
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("invalid state file: %v", err)
+	}
+
+	if len(saved.Orders) != 3 || saved.Orders[0].Status != "CANCELED" {
+		t.Errorf("expected the final state of 3 canceled orders, got %+v", saved.Orders)
+	}
+}
+
+func TestRunReportsOrdersLeftOpen(t *testing.T) {
+	stuck := models.Order{Symbol: "BTCUSDT", OrderID: 7, Side: "SELL", Status: "NEW"}
+	client := newFakeClient(stuck, models.Order{Symbol: "ETHUSDT", OrderID: 1, Status: "NEW"})
+	client.stuck["BTCUSDT"] = true
+
+	start := time.Now()
+	report := New(client, nil, Options{Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond}).Run(context.Background())
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected to retry until the timeout, gave up after %v", elapsed)
+	}
+
+	if report.Clean() || len(report.Remaining) != 1 || report.Remaining[0].OrderID != 7 {
+		t.Errorf("expected order 7 to be reported as left open, got %+v", report.Remaining)
+	}
+
+	if client.cancelCalls["BTCUSDT"] < 2 || client.cancelCalls["ETHUSDT"] != 1 {
+		t.Errorf("expected cancel retries only on the stuck symbol, got %v", client.cancelCalls)
+	}
+}
+
+func TestRunReportsUnconfirmedSymbols(t *testing.T) {
+	client := newFakeClient(models.Order{Symbol: "BTCUSDT", OrderID: 1, Status: "NEW"})
+	client.statusErr = errors.New("connection lost")
+
+	report := New(client, nil, Options{Timeout: 20 * time.Millisecond, PollInterval: 5 * time.Millisecond}).Run(context.Background())
+
+	if report.Clean() || report.Errors["BTCUSDT"] == "" {
+		t.Errorf("expected BTCUSDT to be reported as unconfirmed, got %+v", report)
+	}
+}
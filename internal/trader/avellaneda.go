@@ -0,0 +1,182 @@
+package trader
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/indicators"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Defaults for the estimation windows left zero in AvellanedaStoikovParams
+const (
+	defaultVolatilityWindow = 20
+	defaultTradeWindow      = 500
+)
+
+// Parameters of the Avellaneda-Stoikov model. Prices are in quote asset and
+// time in seconds, so gamma and kappa are both per unit of price.
+type AvellanedaStoikovParams struct {
+	Gamma            float64       // Risk aversion, higher skews quotes harder against inventory
+	Kappa            float64       // Decay of order arrival with distance from the mid, zero to estimate it from trades
+	Horizon          time.Duration // Time left in the session, held constant so quotes don't collapse at its end
+	VolatilityWindow int           // Mid price changes in the volatility estimate
+	TradeWindow      int           // Recent trades in the kappa estimate
+}
+
+// Avellaneda-Stoikov quotes: a reservation price moved away from the mid
+// against the inventory held, and a spread set by risk aversion, volatility
+// and how quickly fills fall off with distance from the mid.
+//
+//	r = s - q * gamma * sigma^2 * T
+//	spread = gamma * sigma^2 * T + 2/gamma * ln(1 + gamma/kappa)
+//
+// Quotes are only priced from the market maker's refresh loop, so the
+// estimates are not guarded.
+type AvellanedaStoikov struct {
+	params   AvellanedaStoikovParams
+	trades   RecentTrades
+	now      func() time.Time
+	variance *indicators.SMA // Squared mid changes per second
+	lastMid  float64
+	lastSeen time.Time // Time of lastMid, zero before the first book
+	kappa    float64   // Last kappa estimated from trades
+}
+
+func NewAvellanedaStoikov(params AvellanedaStoikovParams, trades RecentTrades) *AvellanedaStoikov {
+	if params.VolatilityWindow < 1 {
+		params.VolatilityWindow = defaultVolatilityWindow
+	}
+	if params.TradeWindow < 1 {
+		params.TradeWindow = defaultTradeWindow
+	}
+
+	return &AvellanedaStoikov{
+		params:   params,
+		trades:   trades,
+		now:      time.Now,
+		variance: indicators.NewSMA(params.VolatilityWindow),
+	}
+}
+
+func (a *AvellanedaStoikov) Name() string { return "avellaneda-stoikov" }
+func (a *AvellanedaStoikov) Levels() int  { return 1 }
+
+func (a *AvellanedaStoikov) Describe() string {
+	kappa := "estimated"
+	if a.params.Kappa > 0 {
+		kappa = fmt.Sprintf("%g", a.params.Kappa)
+	}
+
+	return fmt.Sprintf("avellaneda-stoikov gamma=%g kappa=%s horizon=%s", a.params.Gamma, kappa, a.params.Horizon)
+}
+
+func (a *AvellanedaStoikov) Quotes(book *models.ParsedOrderBook, state QuoteState) (Quote, error) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return Quote{}, errEmptyBook
+	}
+	if !state.OrderQuantity.IsPositive() {
+		return Quote{}, fmt.Errorf("order quantity must be positive to measure inventory")
+	}
+
+	mid := book.Bids[0].Price.Add(book.Asks[0].Price).Div(two, decimal.Nearest)
+	s := mid.Float64()
+
+	a.observe(s)
+	if !a.variance.Ready() {
+		return Quote{}, fmt.Errorf("collecting volatility samples, %d needed", a.params.VolatilityWindow+1)
+	}
+
+	kappa, err := a.arrivalDecay(book.Symbol, s)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	gamma := a.params.Gamma
+	risk := gamma * a.variance.Value() * a.params.Horizon.Seconds()
+	q := state.Inventory.Float64() / state.OrderQuantity.Float64()
+
+	reservation := s - q*risk
+	spread := risk + 2/gamma*math.Log(1+gamma/kappa)
+
+	// Never quote tighter than the configured spread
+	spread = math.Max(spread, 2*s*state.SpreadPercentage.Float64()/100)
+
+	if math.IsNaN(reservation) || math.IsInf(reservation, 0) || math.IsNaN(spread) || math.IsInf(spread, 0) {
+		return Quote{}, fmt.Errorf("model produced no finite quote (reservation %g, spread %g)", reservation, spread)
+	}
+
+	return Quote{
+		Bid:       decimal.NewFromFloat(reservation - spread/2),
+		Ask:       decimal.NewFromFloat(reservation + spread/2),
+		FairValue: decimal.NewFromFloat(reservation),
+	}, nil
+}
+
+// Add the squared mid change since the last book, per second, to the
+// variance estimate
+func (a *AvellanedaStoikov) observe(mid float64) {
+	now := a.now()
+
+	if !a.lastSeen.IsZero() {
+		if elapsed := now.Sub(a.lastSeen).Seconds(); elapsed > 0 {
+			change := mid - a.lastMid
+			a.variance.Update(change * change / elapsed)
+		}
+	}
+
+	a.lastMid, a.lastSeen = mid, now
+}
+
+// Configured kappa, or one estimated from how far recent trades printed
+// from the mid. With arrivals decaying as exp(-kappa * distance), the
+// distances are exponential and kappa is their count over their sum.
+func (a *AvellanedaStoikov) arrivalDecay(symbol string, mid float64) (float64, error) {
+	if a.params.Kappa > 0 {
+		return a.params.Kappa, nil
+	}
+
+	trades, err := a.trades.GetRecentTrades(symbol, a.params.TradeWindow)
+	if err != nil {
+		if a.kappa > 0 {
+			slog.Warn("Recent trades unavailable, keeping the last kappa", "symbol", symbol, "error", err)
+			return a.kappa, nil
+		}
+
+		return 0, fmt.Errorf("failed to estimate kappa: %w", err)
+	}
+
+	if kappa, ok := estimateKappa(trades, mid); ok {
+		a.kappa = kappa
+	}
+
+	if a.kappa <= 0 {
+		return 0, fmt.Errorf("failed to estimate kappa: no trades away from the mid")
+	}
+
+	return a.kappa, nil
+}
+
+func estimateKappa(trades []models.MarketTrade, mid float64) (float64, bool) {
+	var count int
+	var distance float64
+
+	for _, trade := range trades {
+		price, err := decimal.NewFromString(trade.Price)
+		if err != nil {
+			continue
+		}
+
+		distance += math.Abs(price.Float64() - mid)
+		count++
+	}
+
+	if count == 0 || distance <= 0 {
+		return 0, false
+	}
+
+	return float64(count) / distance, true
+}
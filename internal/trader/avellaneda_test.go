@@ -0,0 +1,107 @@
+package trader
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// One tick wide book around mid
+func bookAround(mid float64) *models.ParsedOrderBook {
+	return &models.ParsedOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []models.PriceLevel{{Price: decimal.NewFromFloat(mid - 0.5), Quantity: decimal.NewFromInt(1)}},
+		Asks:   []models.PriceLevel{{Price: decimal.NewFromFloat(mid + 0.5), Quantity: decimal.NewFromInt(1)}},
+	}
+}
+
+// Model that has seen mids of 100, 101 and 100 a second apart, a variance
+// of 1 per second
+func warmModel(t *testing.T, params AvellanedaStoikovParams) *AvellanedaStoikov {
+	t.Helper()
+
+	model := NewAvellanedaStoikov(params, fakeTrades{})
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	model.now = func() time.Time { return clock }
+
+	state := QuoteState{OrderQuantity: decimal.NewFromInt(1)}
+	for i, mid := range []float64{100, 101} {
+		if _, err := model.Quotes(bookAround(mid), state); err == nil {
+			t.Fatalf("expected quotes to wait for volatility samples, got none after %d books", i+1)
+		}
+		clock = clock.Add(time.Second)
+	}
+
+	return model
+}
+
+func TestAvellanedaStoikovQuotes(t *testing.T) {
+	params := AvellanedaStoikovParams{Gamma: 0.1, Kappa: 10, Horizon: 10 * time.Second, VolatilityWindow: 2}
+
+	// gamma * sigma^2 * T = 1, so the spread is 1 + 20 * ln(1.01)
+	spread := 1 + 20*math.Log(1.01)
+
+	tests := []struct {
+		name      string
+		inventory int64
+		spreadPct string
+		fair      float64
+		bid, ask  float64
+	}{
+		{"flat", 0, "0", 100, 100 - spread/2, 100 + spread/2},
+		{"long", 2, "0", 98, 98 - spread/2, 98 + spread/2},
+		{"short", -1, "0", 101, 101 - spread/2, 101 + spread/2},
+		{"spread floor", 0, "5", 100, 95, 105},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := warmModel(t, params)
+
+			quote, err := model.Quotes(bookAround(100), QuoteState{
+				Inventory:        decimal.NewFromInt(tt.inventory),
+				OrderQuantity:    decimal.NewFromInt(1),
+				SpreadPercentage: decimal.RequireFromString(tt.spreadPct),
+			})
+			if err != nil {
+				t.Fatalf("Quotes() returned error: %v", err)
+			}
+
+			if !nearPrice(quote.FairValue, tt.fair) || !nearPrice(quote.Bid, tt.bid) || !nearPrice(quote.Ask, tt.ask) {
+				t.Errorf("quote = %s / %s around %s, want %.8f / %.8f around %.8f",
+					quote.Bid, quote.Ask, quote.FairValue, tt.bid, tt.ask, tt.fair)
+			}
+		})
+	}
+}
+
+func TestAvellanedaStoikovEstimatesKappaFromTrades(t *testing.T) {
+	// Distances from the mid of 100 sum to 2 over 4 trades
+	trades := []models.MarketTrade{{Price: "99.5"}, {Price: "100.5"}, {Price: "101"}, {Price: "100"}, {Price: "bad"}}
+
+	if kappa, ok := estimateKappa(trades, 100); !ok || kappa != 2 {
+		t.Errorf("estimateKappa() = %g, %v, want 2", kappa, ok)
+	}
+	if _, ok := estimateKappa([]models.MarketTrade{{Price: "100"}}, 100); ok {
+		t.Error("expected no estimate from trades at the mid")
+	}
+
+	model := &AvellanedaStoikov{params: AvellanedaStoikovParams{TradeWindow: 10}, trades: fakeTrades{trades: trades}}
+	if kappa, err := model.arrivalDecay("BTCUSDT", 100); err != nil || kappa != 2 {
+		t.Fatalf("arrivalDecay() = %g, %v, want 2", kappa, err)
+	}
+
+	// The last estimate carries over an outage
+	model.trades = fakeTrades{err: errors.New("timeout")}
+	if kappa, err := model.arrivalDecay("BTCUSDT", 100); err != nil || kappa != 2 {
+		t.Errorf("arrivalDecay() after an outage = %g, %v, want 2", kappa, err)
+	}
+}
+
+func nearPrice(got decimal.Decimal, want float64) bool {
+	return math.Abs(got.Float64()-want) < 1e-6
+}
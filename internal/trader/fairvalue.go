@@ -0,0 +1,166 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/indicators"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+var errEmptyBook = errors.New("empty orderbook")
+
+// Price the market maker centres its quotes on
+type FairValue interface {
+	Name() string
+	Levels() int // Book levels per side the estimate reads
+	Estimate(book *models.ParsedOrderBook) (decimal.Decimal, error)
+}
+
+// Recent public trades on a symbol, oldest first
+type RecentTrades interface {
+	GetRecentTrades(symbol string, limit int) ([]models.MarketTrade, error)
+}
+
+var (
+	_ FairValue = MidPrice{}
+	_ FairValue = Microprice{}
+	_ FairValue = (*DepthWeighted)(nil)
+	_ FairValue = (*TradeFlow)(nil)
+)
+
+// Halfway between the best bid and ask
+type MidPrice struct{}
+
+func (MidPrice) Name() string { return "mid" }
+func (MidPrice) Levels() int  { return 1 }
+
+func (MidPrice) Estimate(book *models.ParsedOrderBook) (decimal.Decimal, error) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return decimal.Zero, errEmptyBook
+	}
+
+	return book.Bids[0].Price.Add(book.Asks[0].Price).Div(two, decimal.Nearest), nil
+}
+
+// Best bid and ask weighted by the size on the opposite side, so a thin ask
+// pulls the fair value up towards it
+type Microprice struct{}
+
+func (Microprice) Name() string { return "microprice" }
+func (Microprice) Levels() int  { return 1 }
+
+func (Microprice) Estimate(book *models.ParsedOrderBook) (decimal.Decimal, error) {
+	price, ok := indicators.Microprice(book)
+	if !ok {
+		return decimal.Zero, errEmptyBook
+	}
+
+	return price, nil
+}
+
+// Microprice extended to several levels: the volume weighted price of each
+// side's top levels, weighted by the depth of the opposite side
+type DepthWeighted struct {
+	levels int
+}
+
+func NewDepthWeighted(levels int) *DepthWeighted {
+	return &DepthWeighted{levels: max(levels, 1)}
+}
+
+func (d *DepthWeighted) Name() string { return fmt.Sprintf("depth-%d", d.levels) }
+func (d *DepthWeighted) Levels() int  { return d.levels }
+
+func (d *DepthWeighted) Estimate(book *models.ParsedOrderBook) (decimal.Decimal, error) {
+	bidPrice, bidDepth := weightedPrice(book.Bids, d.levels)
+	askPrice, askDepth := weightedPrice(book.Asks, d.levels)
+
+	total := bidDepth.Add(askDepth)
+	if !bidDepth.IsPositive() || !askDepth.IsPositive() {
+		return decimal.Zero, errEmptyBook
+	}
+
+	weighted := bidPrice.Mul(askDepth).Add(askPrice.Mul(bidDepth))
+	return weighted.Div(total, decimal.Nearest), nil
+}
+
+// Volume weighted price and total quantity of a side's top levels
+func weightedPrice(side []models.PriceLevel, levels int) (decimal.Decimal, decimal.Decimal) {
+	notional, depth := decimal.Zero, decimal.Zero
+	for _, level := range side[:min(levels, len(side))] {
+		notional = notional.Add(level.Price.Mul(level.Quantity))
+		depth = depth.Add(level.Quantity)
+	}
+
+	if !depth.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+
+	return notional.Div(depth, decimal.Nearest), depth
+}
+
+// Another estimate shifted towards the side takers have been trading on.
+// When every recent trade was a taker buy, the fair value moves up by weight
+// times half the spread.
+type TradeFlow struct {
+	base   FairValue
+	trades RecentTrades
+	window int             // Recent trades considered
+	weight decimal.Decimal // Shift per unit of flow, as a fraction of half the spread
+}
+
+func NewTradeFlow(base FairValue, trades RecentTrades, window int, weight decimal.Decimal) *TradeFlow {
+	return &TradeFlow{base: base, trades: trades, window: max(window, 1), weight: weight}
+}
+
+func (f *TradeFlow) Name() string { return f.base.Name() + "+trade-flow" }
+func (f *TradeFlow) Levels() int  { return f.base.Levels() }
+
+func (f *TradeFlow) Estimate(book *models.ParsedOrderBook) (decimal.Decimal, error) {
+	fair, err := f.base.Estimate(book)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	// Without trades the base estimate still quotes
+	trades, err := f.trades.GetRecentTrades(book.Symbol, f.window)
+	if err != nil {
+		slog.Warn("Trade flow unavailable, using the base fair value", "symbol", book.Symbol, "error", err)
+		return fair, nil
+	}
+
+	flow, ok := tradeFlow(trades)
+	if !ok {
+		return fair, nil
+	}
+
+	halfSpread := book.Asks[0].Price.Sub(book.Bids[0].Price).Div(two, decimal.Nearest)
+	return fair.Add(halfSpread.Mul(f.weight).Mul(flow)), nil
+}
+
+// Taker buy volume minus taker sell volume over their total, from -1 to 1
+func tradeFlow(trades []models.MarketTrade) (decimal.Decimal, bool) {
+	bought, sold := decimal.Zero, decimal.Zero
+	for _, trade := range trades {
+		quantity, err := decimal.NewFromString(trade.Qty)
+		if err != nil {
+			continue
+		}
+
+		if trade.IsBuyerMaker {
+			sold = sold.Add(quantity)
+		} else {
+			bought = bought.Add(quantity)
+		}
+	}
+
+	total := bought.Add(sold)
+	if !total.IsPositive() {
+		return decimal.Zero, false
+	}
+
+	return bought.Sub(sold).Div(total, decimal.Nearest), true
+}
@@ -0,0 +1,105 @@
+package trader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Recent trades answered from fixed data
+type fakeTrades struct {
+	trades []models.MarketTrade
+	err    error
+}
+
+func (f fakeTrades) GetRecentTrades(symbol string, limit int) ([]models.MarketTrade, error) {
+	return f.trades, f.err
+}
+
+// Fair value fixed regardless of the book
+type fixedFairValue string
+
+func (f fixedFairValue) Name() string { return "fixed" }
+func (f fixedFairValue) Levels() int  { return 1 }
+
+func (f fixedFairValue) Estimate(book *models.ParsedOrderBook) (decimal.Decimal, error) {
+	return decimal.RequireFromString(string(f)), nil
+}
+
+func TestFairValueEstimators(t *testing.T) {
+	book := &models.ParsedOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []models.PriceLevel{level("100", "3"), level("99", "5")},
+		Asks:   []models.PriceLevel{level("101", "1"), level("102", "3")},
+	}
+
+	// Taker buys of 3 against taker sells of 1 give a flow of 0.5
+	flow := fakeTrades{trades: []models.MarketTrade{
+		{Qty: "2", IsBuyerMaker: false},
+		{Qty: "1", IsBuyerMaker: true},
+		{Qty: "1", IsBuyerMaker: false},
+	}}
+	half := decimal.RequireFromString("0.5")
+
+	tests := []struct {
+		fairValue FairValue
+		want      string
+	}{
+		{MidPrice{}, "100.5"},
+		{Microprice{}, "100.75"},        // (100 * 1 + 101 * 3) / 4
+		{NewDepthWeighted(1), "100.75"}, // One level is the microprice
+		{NewDepthWeighted(2), "100.95833333"},
+		{NewTradeFlow(MidPrice{}, flow, 100, half), "100.625"}, // Half a spread of 1, times 0.5 weight and 0.5 flow
+		{NewTradeFlow(MidPrice{}, fakeTrades{}, 100, half), "100.5"},
+		{NewTradeFlow(MidPrice{}, fakeTrades{err: errors.New("timeout")}, 100, half), "100.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fairValue.Name(), func(t *testing.T) {
+			got, err := tt.fairValue.Estimate(book)
+			if err != nil || got.String() != tt.want {
+				t.Errorf("Estimate() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+
+	for _, fairValue := range []FairValue{MidPrice{}, Microprice{}, NewDepthWeighted(5), NewTradeFlow(Microprice{}, flow, 10, half)} {
+		if _, err := fairValue.Estimate(&models.ParsedOrderBook{Bids: book.Bids}); err == nil {
+			t.Errorf("%s: expected an error for a book without asks", fairValue.Name())
+		}
+	}
+}
+
+func TestQuotesCentreOnFairValueInsideTheTouch(t *testing.T) {
+	tests := []struct {
+		name      string
+		fairValue FairValue
+		bid, ask  string
+	}{
+		{"microprice", Microprice{}, "99.74", "101.76"},
+		{"above the ask", fixedFairValue("150"), "99.99", "102.01"},
+		{"below the bid", fixedFairValue("50"), "99.00", "101.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockBinanceClient(&models.ParsedOrderBook{
+				Bids: []models.PriceLevel{level("100", "3")},
+				Asks: []models.PriceLevel{level("101", "1")},
+			})
+
+			maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01", WithFairValue(tt.fairValue))
+			maker.active = true
+
+			if err := maker.updateMarketState(); err != nil {
+				t.Fatalf("updateMarketState() returned error: %v", err)
+			}
+
+			if bid, ask := client.placedOrders[0].Price, client.placedOrders[1].Price; bid != tt.bid || ask != tt.ask {
+				t.Errorf("quotes = %s / %s, want %s / %s", bid, ask, tt.bid, tt.ask)
+			}
+		})
+	}
+}
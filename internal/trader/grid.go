@@ -0,0 +1,604 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+	"github.com/iamramtin/binance-trader/internal/utils"
+)
+
+// Time between checks of the grid's resting orders
+const gridRefreshInterval = 10 * time.Second
+
+// How the prices of a grid are spaced between its bounds
+type GridSpacing string
+
+const (
+	GridArithmetic GridSpacing = "arithmetic" // Equal price steps
+	GridGeometric  GridSpacing = "geometric"  // Equal percentage steps
+)
+
+// Shape of a grid and where its state is kept
+type GridParams struct {
+	Lower     decimal.Decimal // Lowest grid price
+	Upper     decimal.Decimal // Highest grid price
+	Levels    int             // Prices in the grid, including both bounds
+	Spacing   GridSpacing
+	Quantity  decimal.Decimal // Quantity of every order
+	TickSize  string          // Price tick size for the symbol
+	StateFile string          // Grid state is saved here after every change and resumed from on start
+}
+
+// Persisted state of a grid. The shape is saved with the orders so a grid is
+// never resumed with different prices.
+type GridState struct {
+	Symbol     string          `json:"symbol"`
+	Lower      decimal.Decimal `json:"lower"`
+	Upper      decimal.Decimal `json:"upper"`
+	Spacing    GridSpacing     `json:"spacing"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Levels     []GridLevel     `json:"levels"`
+	RoundTrips int             `json:"roundTrips"` // Fills that closed an earlier fill one level away
+	Profit     decimal.Decimal `json:"profit"`     // Quote asset earned by the round trips, before fees
+}
+
+// One price of the grid and the order kept there
+type GridLevel struct {
+	Price   decimal.Decimal  `json:"price"`
+	Side    string           `json:"side,omitempty"`    // Side of the order kept here, empty for the free level
+	OrderID int64            `json:"orderId,omitempty"` // Resting order, zero while it still has to be placed
+	Entry   *decimal.Decimal `json:"entry,omitempty"`   // Price of the fill this order closes, nil for opening orders
+	Filled  *decimal.Decimal `json:"filled,omitempty"`  // Executed by canceled orders here, nil while none has traded
+}
+
+// Quantity still to trade at the level
+func (l GridLevel) remaining(quantity decimal.Decimal) decimal.Decimal {
+	if l.Filled == nil {
+		return quantity
+	}
+
+	return quantity.Sub(*l.Filled)
+}
+
+var _ Strategy = (*Grid)(nil)
+
+// Buy and sell LIMIT orders at fixed prices between two bounds. One level is
+// always free: buys rest below it and sells above it. When an order fills,
+// its level becomes the free one and the opposite order is placed on the
+// level that was free, one level away, so every later fill there closes a
+// round trip for one grid step of profit.
+type Grid struct {
+	client  api.Exchange
+	symbol  string
+	params  GridParams
+	state   GridState
+	active  bool
+	paused  bool       // Whether placing orders is suspended while the grid stays active
+	resumed bool       // Set by Start until the first check of the orders saved while stopped
+	mu      sync.Mutex // Guards active, paused, state and the file it is saved to
+	cancel  context.CancelFunc
+	stopped chan struct{} // Closed when the refresh loop has returned
+}
+
+// Lay out a grid, resuming the one saved in the state file when it has
+// the same shape
+func NewGrid(client api.Exchange, symbol string, params GridParams) (*Grid, error) {
+	prices, err := GridPrices(params)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Grid{client: client, symbol: symbol, params: params}
+
+	g.state = GridState{
+		Symbol:   symbol,
+		Lower:    params.Lower,
+		Upper:    params.Upper,
+		Spacing:  params.Spacing,
+		Quantity: params.Quantity,
+		Levels:   make([]GridLevel, len(prices)),
+	}
+	for i, price := range prices {
+		g.state.Levels[i].Price = price
+	}
+
+	if params.StateFile == "" {
+		return g, nil
+	}
+
+	saved, err := loadGridState(params.StateFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return g, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := g.state.matches(saved); err != nil {
+		return nil, fmt.Errorf("state file %s holds a different grid, move it away to start a new one: %w", params.StateFile, err)
+	}
+
+	g.state = saved
+	return g, nil
+}
+
+// Prices of a grid in ascending order, rounded onto ticks
+func GridPrices(params GridParams) ([]decimal.Decimal, error) {
+	if params.Levels < 2 {
+		return nil, fmt.Errorf("a grid needs at least 2 levels, got %d", params.Levels)
+	}
+	if !params.Lower.IsPositive() || !params.Upper.GreaterThan(params.Lower) {
+		return nil, fmt.Errorf("grid bounds must satisfy 0 < lower < upper, got %s to %s", params.Lower, params.Upper)
+	}
+	if !params.Quantity.IsPositive() {
+		return nil, fmt.Errorf("grid order quantity must be positive, got %s", params.Quantity)
+	}
+
+	steps := int64(params.Levels - 1)
+	ratio := math.Pow(params.Upper.Float64()/params.Lower.Float64(), 1/float64(steps))
+
+	prices := make([]decimal.Decimal, params.Levels)
+	for i := range prices {
+		var price decimal.Decimal
+
+		switch params.Spacing {
+		case GridArithmetic:
+			step := params.Upper.Sub(params.Lower).Mul(decimal.NewFromInt(int64(i))).Div(decimal.NewFromInt(steps), decimal.Nearest)
+			price = params.Lower.Add(step)
+		case GridGeometric:
+			price = decimal.NewFromFloat(params.Lower.Float64() * math.Pow(ratio, float64(i)))
+		default:
+			return nil, fmt.Errorf("unknown grid spacing %q, use arithmetic or geometric", params.Spacing)
+		}
+
+		rounded, err := decimal.NewFromString(utils.FormatPrice(price, params.TickSize, decimal.Nearest))
+		if err != nil {
+			return nil, fmt.Errorf("failed to round grid price %s: %w", price, err)
+		}
+
+		if i > 0 && !rounded.GreaterThan(prices[i-1]) {
+			return nil, fmt.Errorf("grid levels %s and %s round to the same tick, use fewer levels", prices[i-1], price)
+		}
+
+		prices[i] = rounded
+	}
+
+	return prices, nil
+}
+
+func (g *Grid) Symbol() string {
+	return g.symbol
+}
+
+func (g *Grid) IsActive() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.active
+}
+
+func (g *Grid) IsPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.paused
+}
+
+// Stop placing orders and cancel the resting ones, keeping the refresh loop
+// alive so fills are still picked up. Levels keep their side, so the orders
+// are placed again on Resume.
+func (g *Grid) Pause() {
+	g.mu.Lock()
+	if g.paused {
+		g.mu.Unlock()
+		return
+	}
+
+	g.paused = true
+	g.mu.Unlock()
+
+	g.logger().Info("Pausing grid and canceling its orders")
+	g.cancelOrders()
+}
+
+// Place the missing orders again from the next refresh
+func (g *Grid) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		g.logger().Info("Resuming grid")
+	}
+
+	g.paused = false
+}
+
+// Round trips completed and the quote asset they earned, before fees
+func (g *Grid) GridProfit() (decimal.Decimal, int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.state.Profit, g.state.RoundTrips
+}
+
+// Net result of the fills on the traded symbol
+func (g *Grid) Position() ordermanager.Position {
+	return g.client.GetOrderManager().Position(g.symbol)
+}
+
+// Copy of the grid's state
+func (g *Grid) State() GridState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := g.state
+	state.Levels = append([]GridLevel(nil), g.state.Levels...)
+	return state
+}
+
+func (g *Grid) logger() *slog.Logger {
+	return slog.With("strategy", "grid", "symbol", g.symbol)
+}
+
+func (g *Grid) Start() {
+	g.mu.Lock()
+	if g.active {
+		g.mu.Unlock()
+		g.logger().Warn("Grid is already running")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.active = true
+	g.resumed = true
+	g.cancel = cancel
+	g.stopped = make(chan struct{})
+	stopped := g.stopped
+	g.mu.Unlock()
+
+	go g.loop(ctx, stopped)
+}
+
+// Stop refreshing and cancel the resting orders. Their levels keep their
+// side, so the orders are placed again when the grid is resumed.
+func (g *Grid) Stop() {
+	g.mu.Lock()
+	if !g.active {
+		g.mu.Unlock()
+		g.logger().Warn("Grid is not running")
+		return
+	}
+
+	g.active = false
+	g.cancel()
+	stopped := g.stopped
+	g.mu.Unlock()
+
+	<-stopped
+
+	logger := g.logger()
+	logger.Info("Stopping grid and canceling its orders")
+	g.cancelOrders()
+
+	profit, roundTrips := g.GridProfit()
+	logger.Info("Grid stopped", "roundTrips", roundTrips, "profit", profit)
+}
+
+// Cancel every resting order, freeing its level for the rest to be placed again
+func (g *Grid) cancelOrders() {
+	for i, level := range g.State().Levels {
+		if level.OrderID == 0 {
+			continue
+		}
+
+		g.cancelOrder(i, level.OrderID)
+	}
+}
+
+func (g *Grid) cancelOrder(i int, orderID int64) {
+	order, err := g.client.CancelOrder(g.symbol, orderID)
+	if err != nil {
+		g.logger().Warn("Failed to cancel order", "orderId", orderID, "error", err)
+		return
+	}
+
+	// An order that filled while canceling is picked up by the next refresh
+	if order.Status == string(models.OrderStatusCanceled) {
+		g.update(func(s *GridState) {
+			if s.Levels[i].OrderID == orderID {
+				s.release(i, order.ExecutedQty)
+			}
+		})
+	}
+}
+
+func (g *Grid) loop(ctx context.Context, stopped chan struct{}) {
+	defer close(stopped)
+
+	logger := g.logger()
+	logger.Info("Starting grid", "lower", g.params.Lower, "upper", g.params.Upper,
+		"levels", len(g.state.Levels), "spacing", g.params.Spacing, "quantity", g.params.Quantity)
+
+	ticker := time.NewTicker(gridRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.refresh(ctx); err != nil {
+			logger.Warn("Failed to refresh grid", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Lay out a new grid, pick up fills and place every missing order unless paused
+func (g *Grid) refresh(ctx context.Context) error {
+	if !g.State().laidOut() {
+		if err := g.layout(); err != nil {
+			return err
+		}
+	}
+
+	g.checkFills(ctx)
+
+	for i, level := range g.State().Levels {
+		if ctx.Err() != nil || g.IsPaused() {
+			return nil
+		}
+		if level.Side == "" || level.OrderID != 0 {
+			continue
+		}
+
+		price := utils.FormatPrice(level.Price, g.params.TickSize, decimal.Nearest)
+		quantity := level.remaining(g.params.Quantity).String()
+		order, err := g.client.PlaceOrder(g.symbol, level.Side, "LIMIT", price, quantity)
+		if err != nil {
+			return fmt.Errorf("failed to place %s order at %s: %w", level.Side, price, err)
+		}
+
+		g.logger().Info("Placed grid order", "side", level.Side, "orderId", order.OrderID, "quantity", quantity, "price", price)
+
+		var paused bool
+		g.update(func(s *GridState) {
+			paused = g.paused
+			s.Levels[i].OrderID = order.OrderID
+		})
+
+		if paused {
+			// Pause may have swept the orders before this one was recorded
+			g.cancelOrder(i, order.OrderID)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Give every level but the one nearest the mid price an opening order
+func (g *Grid) layout() error {
+	book, err := g.client.GetOrderbook(g.symbol, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get orderbook: %w", err)
+	}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return errEmptyBook
+	}
+
+	mid := book.Bids[0].Price.Add(book.Asks[0].Price).Div(two, decimal.Nearest)
+
+	g.update(func(s *GridState) {
+		gap := 0
+		for i, level := range s.Levels {
+			if level.Price.Sub(mid).Abs().LessThan(s.Levels[gap].Price.Sub(mid).Abs()) {
+				gap = i
+			}
+		}
+
+		for i := range s.Levels {
+			switch {
+			case i < gap:
+				s.Levels[i].Side = "BUY"
+			case i > gap:
+				s.Levels[i].Side = "SELL"
+			}
+		}
+	})
+
+	g.logger().Info("Laid out grid", "mid", mid)
+	return nil
+}
+
+// Check every resting order, freeing the levels that filled and giving the
+// opposite order to the level one step away
+func (g *Grid) checkFills(ctx context.Context) {
+	logger := g.logger()
+
+	var filled []int
+	for i, level := range g.State().Levels {
+		if ctx.Err() != nil {
+			return
+		}
+		if level.OrderID == 0 {
+			continue
+		}
+
+		order, err := g.client.GetOrderStatus(g.symbol, level.OrderID)
+		if err != nil {
+			logger.Warn("Failed to check order", "orderId", level.OrderID, "error", err)
+			continue
+		}
+
+		switch models.OrderStatus(order.Status) {
+		case models.OrderStatusFilled:
+			filled = append(filled, i)
+		case models.OrderStatusCanceled, models.OrderStatusExpired, models.OrderStatusRejected:
+			// The grid frees its levels when it cancels, so an order canceled
+			// while it runs was canceled outside it, most likely by an
+			// operator flattening the book. Pause rather than place it again;
+			// the rest is placed on Resume. Orders canceled while the grid was
+			// stopped are placed again on start.
+			var released, done bool
+			g.update(func(s *GridState) {
+				if s.Levels[i].OrderID != level.OrderID {
+					return // Released by Pause meanwhile
+				}
+				if !g.resumed {
+					g.paused = true
+				}
+				s.release(i, order.ExecutedQty)
+				released = true
+				done = !s.Levels[i].remaining(s.Quantity).IsPositive()
+			})
+
+			switch {
+			case !released:
+			case g.IsPaused():
+				logger.Warn("Grid order canceled outside the grid, pausing", "orderId", level.OrderID, "status", order.Status, "executedQty", order.ExecutedQty)
+			default:
+				logger.Info("Grid order canceled while stopped", "orderId", level.OrderID, "status", order.Status, "executedQty", order.ExecutedQty)
+			}
+			if done {
+				filled = append(filled, i)
+			}
+		}
+	}
+
+	g.update(func(s *GridState) {
+		g.resumed = false
+
+		// Free every filled level first so fills on neighbouring levels can
+		// take each other's place
+		fills := make([]GridLevel, len(filled))
+		for k, i := range filled {
+			fills[k] = s.Levels[i]
+			s.Levels[i] = GridLevel{Price: s.Levels[i].Price}
+		}
+
+		for k, i := range filled {
+			fill := fills[k]
+
+			if fill.Entry != nil {
+				profit := fill.Price.Sub(*fill.Entry).Abs().Mul(s.Quantity)
+				s.Profit = s.Profit.Add(profit)
+				s.RoundTrips++
+				logger.Info("Grid round trip", "side", fill.Side, "entry", *fill.Entry, "exit", fill.Price,
+					"profit", profit, "totalProfit", s.Profit, "roundTrips", s.RoundTrips)
+			}
+
+			next, side := i+1, "SELL"
+			if fill.Side == "SELL" {
+				next, side = i-1, "BUY"
+			}
+
+			if next < 0 || next >= len(s.Levels) {
+				logger.Warn("Grid order filled at the edge of the grid", "side", fill.Side, "price", fill.Price)
+				continue
+			}
+			if s.Levels[next].Side != "" {
+				logger.Warn("Level for the opposite order is taken", "side", side, "price", s.Levels[next].Price)
+				continue
+			}
+
+			entry := fill.Price
+			s.Levels[next] = GridLevel{Price: s.Levels[next].Price, Side: side, Entry: &entry}
+			logger.Info("Grid order filled", "side", fill.Side, "price", fill.Price, "next", side, "nextPrice", s.Levels[next].Price)
+		}
+	})
+}
+
+// Change the state and save it
+func (g *Grid) update(change func(*GridState)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	change(&g.state)
+
+	if g.params.StateFile == "" {
+		return
+	}
+
+	if err := g.state.save(g.params.StateFile); err != nil {
+		g.logger().Error("Failed to save grid state", "path", g.params.StateFile, "error", err)
+	}
+}
+
+// Whether any level has been given an order
+func (s GridState) laidOut() bool {
+	for _, level := range s.Levels {
+		if level.Side != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether saved describes the same grid
+func (s GridState) matches(saved GridState) error {
+	if saved.Symbol != s.Symbol || saved.Spacing != s.Spacing || !saved.Quantity.Equal(s.Quantity) || len(saved.Levels) != len(s.Levels) {
+		return fmt.Errorf("saved %s %s grid of %d levels of %s", saved.Symbol, saved.Spacing, len(saved.Levels), saved.Quantity)
+	}
+
+	for i, level := range saved.Levels {
+		if !level.Price.Equal(s.Levels[i].Price) {
+			return fmt.Errorf("saved level %d at %s, want %s", i, level.Price, s.Levels[i].Price)
+		}
+	}
+
+	return nil
+}
+
+// Free level i of an order that is no longer resting, keeping what it
+// executed so the order placed again only covers the rest
+func (s *GridState) release(i int, executedQty string) {
+	s.Levels[i].OrderID = 0
+
+	executed, err := decimal.NewFromString(executedQty)
+	if err != nil || !executed.IsPositive() {
+		return
+	}
+
+	if filled := s.Levels[i].Filled; filled != nil {
+		executed = executed.Add(*filled)
+	}
+	s.Levels[i].Filled = &executed
+}
+
+func loadGridState(path string) (GridState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GridState{}, err
+	}
+
+	var state GridState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return GridState{}, fmt.Errorf("failed to read grid state %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// Write the state as JSON, replacing path only once the file is complete
+func (s GridState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode grid state: %w", err)
+	}
+
+	return utils.WriteFileAtomic(path, append(data, '\n'))
+}
@@ -0,0 +1,313 @@
+package trader
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+func gridParams(stateFile string) GridParams {
+	return GridParams{
+		Lower:     decimal.NewFromInt(90),
+		Upper:     decimal.NewFromInt(110),
+		Levels:    5,
+		Spacing:   GridArithmetic,
+		Quantity:  decimal.RequireFromString("0.5"),
+		TickSize:  "0.01",
+		StateFile: stateFile,
+	}
+}
+
+// Mark a placed order filled where the grid checks its status
+func fill(client *MockBinanceClient, orderID int64) {
+	order := *client.placedOrders[orderID-1]
+	order.Status = string(models.OrderStatusFilled)
+	client.orderManager.TrackOrder(&order)
+}
+
+// Sides and prices of the grid's levels, "-" for the free one
+func gridSides(state GridState) []string {
+	sides := make([]string, len(state.Levels))
+	for i, level := range state.Levels {
+		sides[i] = level.Side + "@" + level.Price.String()
+		if level.Side == "" {
+			sides[i] = "-@" + level.Price.String()
+		}
+	}
+
+	return sides
+}
+
+func TestGridPrices(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  GridParams
+		want    []string
+		wantErr bool
+	}{
+		{"arithmetic", gridParams(""), []string{"90", "95", "100", "105", "110"}, false},
+		{"geometric", GridParams{Lower: decimal.NewFromInt(100), Upper: decimal.NewFromInt(400), Levels: 3, Spacing: GridGeometric, Quantity: decimal.NewFromInt(1), TickSize: "0.01"}, []string{"100", "200", "400"}, false},
+		{"levels on one tick", GridParams{Lower: decimal.NewFromInt(100), Upper: decimal.RequireFromString("100.02"), Levels: 5, Spacing: GridArithmetic, Quantity: decimal.NewFromInt(1), TickSize: "0.01"}, nil, true},
+		{"inverted bounds", GridParams{Lower: decimal.NewFromInt(110), Upper: decimal.NewFromInt(90), Levels: 5, Spacing: GridArithmetic, Quantity: decimal.NewFromInt(1), TickSize: "0.01"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices, err := GridPrices(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GridPrices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for i, price := range prices {
+				if price.String() != tt.want[i] {
+					t.Errorf("price %d = %s, want %s", i, price, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGridReplacesFillsAndResumes(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "grid.json")
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	grid, err := NewGrid(client, "BTCUSDT", gridParams(stateFile))
+	if err != nil {
+		t.Fatalf("NewGrid() returned error: %v", err)
+	}
+
+	ctx := context.Background()
+	steps := []struct {
+		name       string
+		fill       int64 // Order filled before the refresh, zero for none
+		sides      []string
+		placed     int
+		profit     string
+		roundTrips int
+	}{
+		{"layout around the mid", 0, []string{"BUY@90", "BUY@95", "-@100", "SELL@105", "SELL@110"}, 4, "0", 0},
+		{"buy filled", 2, []string{"BUY@90", "-@95", "SELL@100", "SELL@105", "SELL@110"}, 5, "0", 0},
+		{"closing sell filled", 5, []string{"BUY@90", "BUY@95", "-@100", "SELL@105", "SELL@110"}, 6, "2.5", 1},
+	}
+
+	for _, step := range steps {
+		if step.fill != 0 {
+			fill(client, step.fill)
+		}
+
+		if err := grid.refresh(ctx); err != nil {
+			t.Fatalf("%s: refresh() returned error: %v", step.name, err)
+		}
+
+		state := grid.State()
+		if got := gridSides(state); !slices.Equal(got, step.sides) {
+			t.Errorf("%s: levels = %v, want %v", step.name, got, step.sides)
+		}
+		if len(client.placedOrders) != step.placed {
+			t.Errorf("%s: placed %d orders, want %d", step.name, len(client.placedOrders), step.placed)
+		}
+		if profit, roundTrips := grid.GridProfit(); profit.String() != step.profit || roundTrips != step.roundTrips {
+			t.Errorf("%s: profit = %s over %d round trips, want %s over %d", step.name, profit, roundTrips, step.profit, step.roundTrips)
+		}
+	}
+
+	resumed, err := NewGrid(client, "BTCUSDT", gridParams(stateFile))
+	if err != nil {
+		t.Fatalf("NewGrid() resuming returned error: %v", err)
+	}
+	if profit, roundTrips := resumed.GridProfit(); profit.String() != "2.5" || roundTrips != 1 {
+		t.Errorf("resumed profit = %s over %d round trips, want 2.5 over 1", profit, roundTrips)
+	}
+	if got := gridSides(resumed.State()); !slices.Equal(got, steps[len(steps)-1].sides) {
+		t.Errorf("resumed levels = %v", got)
+	}
+
+	reshaped := gridParams(stateFile)
+	reshaped.Levels = 6
+	if _, err := NewGrid(client, "BTCUSDT", reshaped); err == nil {
+		t.Error("expected a saved grid of another shape to be refused")
+	}
+}
+
+func TestGridReplacesOnlyTheUnfilledRest(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	grid, _ := NewGrid(client, "BTCUSDT", gridParams(""))
+	ctx := context.Background()
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	// The buy at 90 traded 0.2 of its 0.5 before it was canceled
+	canceled := *client.placedOrders[0]
+	canceled.Status = string(models.OrderStatusCanceled)
+	canceled.ExecutedQty = "0.2"
+	client.orderManager.TrackOrder(&canceled)
+
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	// Canceled outside the grid, so it waits to be resumed
+	if len(client.placedOrders) != 4 || !grid.IsPaused() {
+		t.Fatalf("expected the grid to pause without placing again, placed %d", len(client.placedOrders))
+	}
+
+	grid.Resume()
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	replaced := client.placedOrders[len(client.placedOrders)-1]
+	if len(client.placedOrders) != 5 || replaced.Price != "90.00" || replaced.OrigQty != "0.3" {
+		t.Fatalf("expected the rest of 0.3 placed again at 90, got %s @ %s", replaced.OrigQty, replaced.Price)
+	}
+
+	// Once the rest fills the level counts as filled
+	fill(client, replaced.OrderID)
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	if got := grid.State().Levels[0]; got.Side != "" || got.Filled != nil {
+		t.Errorf("expected the filled level to be freed, got %+v", got)
+	}
+}
+
+func TestGridStopKeepsSidesForResume(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	grid, _ := NewGrid(client, "BTCUSDT", gridParams(""))
+	if err := grid.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	grid.Start()
+	grid.Stop()
+
+	if len(client.canceledOrders) != 4 {
+		t.Errorf("expected the 4 resting orders to be canceled, got %v", client.canceledOrders)
+	}
+
+	for _, level := range grid.State().Levels {
+		if level.OrderID != 0 {
+			t.Errorf("expected no resting order at %s after Stop, got %d", level.Price, level.OrderID)
+		}
+	}
+
+	if got := gridSides(grid.State()); got[0] != "BUY@90" || got[4] != "SELL@110" {
+		t.Errorf("expected levels to keep their sides, got %v", got)
+	}
+}
+
+func TestGridOrdersStayCanceledAfterCancelAll(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	grid, _ := NewGrid(client, "BTCUSDT", gridParams(""))
+	ctx := context.Background()
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	// An operator cancels every open order on the symbol while the grid runs
+	for _, placed := range client.placedOrders {
+		canceled := *placed
+		canceled.Status = string(models.OrderStatusCanceled)
+		client.orderManager.TrackOrder(&canceled)
+	}
+
+	for range 2 {
+		if err := grid.refresh(ctx); err != nil {
+			t.Fatalf("refresh() returned error: %v", err)
+		}
+	}
+
+	if len(client.placedOrders) != 4 {
+		t.Errorf("expected canceled grid orders to stay canceled, placed %d orders", len(client.placedOrders))
+	}
+	if !grid.IsPaused() {
+		t.Error("expected the grid to pause after its orders were canceled outside it")
+	}
+	for _, level := range grid.State().Levels {
+		if level.OrderID != 0 {
+			t.Errorf("expected no resting order at %s, got %d", level.Price, level.OrderID)
+		}
+	}
+
+	grid.Resume()
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	if len(client.placedOrders) != 8 {
+		t.Errorf("expected the 4 orders placed again on resume, placed %d orders", len(client.placedOrders))
+	}
+}
+
+func TestGridPauseCancelsAndHoldsOrders(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	grid, _ := NewGrid(client, "BTCUSDT", gridParams(""))
+	ctx := context.Background()
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	grid.Pause()
+	if len(client.canceledOrders) != 4 {
+		t.Errorf("expected the 4 resting orders to be canceled, got %v", client.canceledOrders)
+	}
+
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+	if len(client.placedOrders) != 4 {
+		t.Errorf("expected no orders placed while paused, placed %d orders", len(client.placedOrders))
+	}
+}
+
+func TestGridPlacesOrdersCanceledWhileStopped(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	grid, _ := NewGrid(client, "BTCUSDT", gridParams(""))
+	ctx := context.Background()
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	// The shutdown canceled the buy at 90 after the grid had stopped
+	canceled := *client.placedOrders[0]
+	canceled.Status = string(models.OrderStatusCanceled)
+	client.orderManager.TrackOrder(&canceled)
+
+	grid.resumed = true
+	if err := grid.refresh(ctx); err != nil {
+		t.Fatalf("refresh() returned error: %v", err)
+	}
+
+	if grid.IsPaused() || len(client.placedOrders) != 5 {
+		t.Errorf("expected the order to be placed again on start, paused %v placed %d", grid.IsPaused(), len(client.placedOrders))
+	}
+}
@@ -0,0 +1,120 @@
+package trader
+
+import (
+	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/metrics"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Quoting state a strategy reports when it makes markets
+type quotingReporter interface {
+	IsPaused() bool
+	IsQuoting() bool
+	QuotingTime() time.Duration
+	MidPrice() (decimal.Decimal, bool)
+	Position() ordermanager.Position
+}
+
+// Profit a strategy reports when it trades a grid
+type gridReporter interface {
+	GridProfit() (decimal.Decimal, int)
+}
+
+var (
+	_ quotingReporter = (*MarketMaker)(nil)
+	_ gridReporter    = (*Grid)(nil)
+)
+
+var (
+	activeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "active"),
+		"Whether the strategy trading a symbol is running.",
+		[]string{"symbol"}, nil)
+
+	pausedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "paused"),
+		"Whether the strategy trading a symbol is paused.",
+		[]string{"symbol"}, nil)
+
+	quotingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "quoting"),
+		"Whether the strategy has quotes resting on the book.",
+		[]string{"symbol"}, nil)
+
+	quotingSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "quoting_seconds_total"),
+		"Time the strategy has spent quoting.",
+		[]string{"symbol"}, nil)
+
+	inventoryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "inventory"),
+		"Base asset bought minus sold by the strategy's fills.",
+		[]string{"symbol"}, nil)
+
+	pnlDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "pnl"),
+		"Profit in the quote asset if the position were closed at the last mid price.",
+		[]string{"symbol"}, nil)
+
+	gridProfitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "grid_profit"),
+		"Quote asset earned by the grid's completed round trips, before fees.",
+		[]string{"symbol"}, nil)
+
+	gridRoundTripsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "strategy", "grid_round_trips_total"),
+		"Fills that closed an earlier grid fill one level away.",
+		[]string{"symbol"}, nil)
+)
+
+func (r *Runner) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeDesc
+	ch <- pausedDesc
+	ch <- quotingDesc
+	ch <- quotingSecondsDesc
+	ch <- inventoryDesc
+	ch <- pnlDesc
+	ch <- gridProfitDesc
+	ch <- gridRoundTripsDesc
+}
+
+// Report the state of every registered strategy at scrape time
+func (r *Runner) Collect(ch chan<- prometheus.Metric) {
+	for _, strategy := range r.Strategies() {
+		symbol := strategy.Symbol()
+		ch <- prometheus.MustNewConstMetric(activeDesc, prometheus.GaugeValue, boolValue(strategy.IsActive()), symbol)
+
+		if g, ok := strategy.(gridReporter); ok {
+			profit, roundTrips := g.GridProfit()
+			ch <- prometheus.MustNewConstMetric(gridProfitDesc, prometheus.GaugeValue, profit.Float64(), symbol)
+			ch <- prometheus.MustNewConstMetric(gridRoundTripsDesc, prometheus.CounterValue, float64(roundTrips), symbol)
+		}
+
+		q, ok := strategy.(quotingReporter)
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(pausedDesc, prometheus.GaugeValue, boolValue(q.IsPaused()), symbol)
+		ch <- prometheus.MustNewConstMetric(quotingDesc, prometheus.GaugeValue, boolValue(q.IsQuoting()), symbol)
+		ch <- prometheus.MustNewConstMetric(quotingSecondsDesc, prometheus.CounterValue, q.QuotingTime().Seconds(), symbol)
+
+		position := q.Position()
+		ch <- prometheus.MustNewConstMetric(inventoryDesc, prometheus.GaugeValue, position.Base.Float64(), symbol)
+
+		// PnL needs a mark, so it is missing until the first refresh
+		if mid, ok := q.MidPrice(); ok {
+			ch <- prometheus.MustNewConstMetric(pnlDesc, prometheus.GaugeValue, position.PnL(mid).Float64(), symbol)
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package trader
+
+import (
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Prices the market maker's bid and ask from the order book
+type QuoteModel interface {
+	Name() string     // Strategy name used in logs
+	Describe() string // Model and its parameters, logged on start
+	Levels() int      // Book levels per side the model reads
+	Quotes(book *models.ParsedOrderBook, state QuoteState) (Quote, error)
+}
+
+// What the market maker knows about itself when pricing quotes
+type QuoteState struct {
+	Inventory        decimal.Decimal // Base asset bought minus sold
+	OrderQuantity    decimal.Decimal // Quantity of each quote
+	SpreadPercentage decimal.Decimal // Configured spread from the fair value in percent
+}
+
+// Prices to quote before rounding onto ticks
+type Quote struct {
+	Bid       decimal.Decimal
+	Ask       decimal.Decimal
+	FairValue decimal.Decimal // Price the quotes are centred on
+}
+
+var (
+	_ QuoteModel = (*FixedSpread)(nil)
+	_ QuoteModel = (*AvellanedaStoikov)(nil)
+)
+
+// Quotes spread_percentage either side of a fair value estimate
+type FixedSpread struct {
+	fairValue FairValue
+}
+
+func NewFixedSpread(fairValue FairValue) *FixedSpread {
+	return &FixedSpread{fairValue: fairValue}
+}
+
+func (f *FixedSpread) Name() string     { return "market-maker" }
+func (f *FixedSpread) Describe() string { return "fixed spread around " + f.fairValue.Name() }
+func (f *FixedSpread) Levels() int      { return f.fairValue.Levels() }
+
+func (f *FixedSpread) Quotes(book *models.ParsedOrderBook, state QuoteState) (Quote, error) {
+	fairValue, err := f.fairValue.Estimate(book)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	// Quoting around a price outside the touch would cross the book on one side
+	fairValue = decimal.Min(decimal.Max(fairValue, book.Bids[0].Price), book.Asks[0].Price)
+
+	spreadAmount := fairValue.Mul(state.SpreadPercentage).Div(hundred, decimal.Nearest)
+
+	return Quote{
+		Bid:       fairValue.Sub(spreadAmount),
+		Ask:       fairValue.Add(spreadAmount),
+		FairValue: fairValue,
+	}, nil
+}
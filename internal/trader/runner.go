@@ -0,0 +1,124 @@
+package trader
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+)
+
+// Strategy trades a single symbol until stopped
+type Strategy interface {
+	Symbol() string
+	Start()
+	Stop()
+	IsActive() bool
+}
+
+// Strategy that can stop placing orders while it keeps running
+type Pauser interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+}
+
+// Strategy whose quoting settings can change while it runs
+type Quoter interface {
+	Pauser
+	SpreadPercentage() decimal.Decimal
+	SetSpreadPercentage(spreadPercentage decimal.Decimal) error
+	OrderQuantity() string
+	SetOrderQuantity(quantity decimal.Decimal) error
+}
+
+var (
+	_ Strategy = (*MarketMaker)(nil)
+	_ Quoter   = (*MarketMaker)(nil)
+	_ Pauser   = (*Grid)(nil)
+)
+
+// Host several strategies in one process. Strategies share the exchange
+// they were created with, and therefore its connection pool and rate limits.
+type Runner struct {
+	strategies []Strategy
+	mu         sync.Mutex
+}
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Register a strategy; only one strategy may trade each symbol
+func (r *Runner) Add(strategy Strategy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.strategies {
+		if existing.Symbol() == strategy.Symbol() {
+			return fmt.Errorf("a strategy is already trading %s", strategy.Symbol())
+		}
+	}
+
+	r.strategies = append(r.strategies, strategy)
+	return nil
+}
+
+// Registered strategies in the order they were added
+func (r *Runner) Strategies() []Strategy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Strategy(nil), r.strategies...)
+}
+
+// Strategy trading a symbol
+func (r *Runner) Strategy(symbol string) (Strategy, bool) {
+	for _, strategy := range r.Strategies() {
+		if strategy.Symbol() == symbol {
+			return strategy, true
+		}
+	}
+
+	return nil, false
+}
+
+// Symbols traded by the registered strategies
+func (r *Runner) Symbols() []string {
+	strategies := r.Strategies()
+
+	symbols := make([]string, 0, len(strategies))
+	for _, strategy := range strategies {
+		symbols = append(symbols, strategy.Symbol())
+	}
+
+	return symbols
+}
+
+func (r *Runner) StartAll() {
+	for _, strategy := range r.Strategies() {
+		slog.Info("Starting strategy", "symbol", strategy.Symbol())
+		strategy.Start()
+	}
+}
+
+// Stop every active strategy concurrently and wait for all of them
+func (r *Runner) StopAll() {
+	var wg sync.WaitGroup
+
+	for _, strategy := range r.Strategies() {
+		if !strategy.IsActive() {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			slog.Info("Stopping strategy", "symbol", strategy.Symbol())
+			strategy.Stop()
+		}()
+	}
+
+	wg.Wait()
+}
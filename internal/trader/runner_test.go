@@ -0,0 +1,93 @@
+package trader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubStrategy struct {
+	symbol string
+	active bool
+}
+
+func (s *stubStrategy) Symbol() string { return s.symbol }
+func (s *stubStrategy) Start()         { s.active = true }
+func (s *stubStrategy) Stop()          { s.active = false }
+func (s *stubStrategy) IsActive() bool { return s.active }
+
+func TestRunnerHostsOneStrategyPerSymbol(t *testing.T) {
+	runner := NewRunner()
+
+	btc := &stubStrategy{symbol: "BTCUSDT"}
+	eth := &stubStrategy{symbol: "ETHUSDT"}
+
+	if err := runner.Add(btc); err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+
+	if err := runner.Add(eth); err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+
+	if err := runner.Add(&stubStrategy{symbol: "BTCUSDT"}); err == nil {
+		t.Error("expected a second BTCUSDT strategy to be rejected")
+	}
+
+	runner.StartAll()
+	if !btc.active || !eth.active {
+		t.Error("expected every strategy to be started")
+	}
+
+	runner.StopAll()
+	if btc.active || eth.active {
+		t.Error("expected every strategy to be stopped")
+	}
+
+	if symbols := runner.Symbols(); len(symbols) != 2 || symbols[0] != "BTCUSDT" || symbols[1] != "ETHUSDT" {
+		t.Errorf("unexpected symbols: %v", symbols)
+	}
+}
+
+func TestRunnerCollectsStrategyMetrics(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99.00", "1.0")},
+		Asks: []models.PriceLevel{level("101.00", "1.0")},
+	})
+	client.orderManager.TrackOrder(&models.Order{Symbol: "BTCUSDT", OrderID: 100, Side: "BUY", ExecutedQty: "2", CummulativeQuoteQty: "190", Status: "FILLED"})
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+	maker.active = true
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error: %v", err)
+	}
+
+	runner := NewRunner()
+	runner.Add(maker)
+	runner.Add(&stubStrategy{symbol: "ETHUSDT"})
+
+	expected := `
+# HELP binance_strategy_active Whether the strategy trading a symbol is running.
+# TYPE binance_strategy_active gauge
+binance_strategy_active{symbol="BTCUSDT"} 1
+binance_strategy_active{symbol="ETHUSDT"} 0
+# HELP binance_strategy_inventory Base asset bought minus sold by the strategy's fills.
+# TYPE binance_strategy_inventory gauge
+binance_strategy_inventory{symbol="BTCUSDT"} 2
+# HELP binance_strategy_pnl Profit in the quote asset if the position were closed at the last mid price.
+# TYPE binance_strategy_pnl gauge
+binance_strategy_pnl{symbol="BTCUSDT"} 10
+# HELP binance_strategy_quoting Whether the strategy has quotes resting on the book.
+# TYPE binance_strategy_quoting gauge
+binance_strategy_quoting{symbol="BTCUSDT"} 1
+`
+
+	err := testutil.CollectAndCompare(runner, strings.NewReader(expected),
+		"binance_strategy_active", "binance_strategy_inventory", "binance_strategy_pnl", "binance_strategy_quoting")
+	if err != nil {
+		t.Error(err)
+	}
+}
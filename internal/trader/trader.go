@@ -3,44 +3,89 @@ package trader
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
 	"maps"
+	"slices"
 
 	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/indicators"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
 	"github.com/iamramtin/binance-trader/internal/utils"
 )
 
+var (
+	two     = decimal.NewFromInt(2)
+	hundred = decimal.NewFromInt(100)
+)
+
+// Order book levels fetched per side on each refresh, at least
+const orderbookLevels = 10
+
 // Implement simple market making strategy
 type MarketMaker struct {
-	client           *api.BinanceClient // WebSocket API client
+	client           api.Exchange       // Exchange to trade on
 	symbol           string             // Trading symbol
-	spreadPercentage float64            // Spread percentage from mid price (e.g., 0.5 for 0.5%)
+	spreadPercentage decimal.Decimal    // Spread percentage from mid price (e.g., 0.5 for 0.5%)
 	orderQty         string             // Quantity of each order
 	tickSize         string             // Price tick size for the symbol
+	model            QuoteModel         // Prices the quotes from the book
 	active           bool               // Whether the trader is currently active
+	paused           bool               // Whether quoting is suspended while the trader stays active
 	activeOrders     map[int64]string   // Map of active order IDs to side (BUY/SELL)
+	lastMid          decimal.Decimal    // Mid price at the last refresh, zero before the first
+	quotingSince     time.Time          // Start of the current quoting period, zero when not quoting
+	quotingTotal     time.Duration      // Time spent quoting in earlier periods
 	mu               sync.RWMutex       // Mutex for thread safety
 	ctx              context.Context    // Context for cancellation
 	cancel           context.CancelFunc // Cancel function for the context
+	stopped          chan struct{}      // Closed when the trading loop has returned, nil before the first Start
+}
+
+// Configures optional market maker behaviour
+type Option func(*MarketMaker)
+
+// Centre quotes on fairValue instead of the mid price
+func WithFairValue(fairValue FairValue) Option {
+	return WithQuoteModel(NewFixedSpread(fairValue))
+}
+
+// Price quotes with model instead of a fixed spread around the mid price
+func WithQuoteModel(model QuoteModel) Option {
+	return func(m *MarketMaker) {
+		m.model = model
+	}
 }
 
-func New(client *api.BinanceClient, symbol string, spreadPercentage float64, orderQty string, tickSize string) *MarketMaker {
+func New(client api.Exchange, symbol string, spreadPercentage decimal.Decimal, orderQty string, tickSize string, opts ...Option) *MarketMaker {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &MarketMaker{
+	m := &MarketMaker{
 		client:           client,
 		symbol:           symbol,
 		spreadPercentage: spreadPercentage,
 		orderQty:         orderQty,
 		tickSize:         tickSize,
+		model:            NewFixedSpread(MidPrice{}),
 		active:           false,
 		activeOrders:     make(map[int64]string),
 		ctx:              ctx,
 		cancel:           cancel,
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+func (m *MarketMaker) Symbol() string {
+	return m.symbol
 }
 
 func (m *MarketMaker) IsActive() bool {
@@ -50,61 +95,219 @@ func (m *MarketMaker) IsActive() bool {
 	return m.active
 }
 
+// Stop quoting and cancel the resting quotes, keeping the trading loop alive
+func (m *MarketMaker) Pause() {
+	m.mu.Lock()
+	if m.paused {
+		m.mu.Unlock()
+		return
+	}
+
+	m.paused = true
+
+	activeOrdersRead := make(map[int64]string)
+	maps.Copy(activeOrdersRead, m.activeOrders)
+	m.activeOrders = make(map[int64]string)
+	m.trackQuoting()
+	m.mu.Unlock()
+
+	logger := m.logger()
+	logger.Info("Pausing market maker")
+
+	for orderID, side := range activeOrdersRead {
+		logger.Info("Canceling order", "side", side, "orderId", orderID)
+		if _, err := m.client.CancelOrder(m.symbol, orderID); err != nil {
+			logger.Warn("Failed to cancel order", "orderId", orderID, "error", err)
+		}
+	}
+}
+
+// Quote again from the next refresh
+func (m *MarketMaker) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.paused {
+		m.logger().Info("Resuming market maker")
+	}
+
+	m.paused = false
+}
+
+func (m *MarketMaker) IsPaused() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.paused
+}
+
+func (m *MarketMaker) SpreadPercentage() decimal.Decimal {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.spreadPercentage
+}
+
+// Change the spread used from the next refresh
+func (m *MarketMaker) SetSpreadPercentage(spreadPercentage decimal.Decimal) error {
+	if !spreadPercentage.IsPositive() {
+		return fmt.Errorf("spread percentage must be greater than 0, got %s", spreadPercentage)
+	}
+
+	m.mu.Lock()
+	m.spreadPercentage = spreadPercentage
+	m.mu.Unlock()
+
+	m.logger().Info("Spread changed", "spreadPercentage", spreadPercentage)
+	return nil
+}
+
+func (m *MarketMaker) OrderQuantity() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.orderQty
+}
+
+// Change the quantity of each quote from the next refresh
+func (m *MarketMaker) SetOrderQuantity(quantity decimal.Decimal) error {
+	if !quantity.IsPositive() {
+		return fmt.Errorf("order quantity must be greater than 0, got %s", quantity)
+	}
+
+	m.mu.Lock()
+	m.orderQty = quantity.String()
+	m.mu.Unlock()
+
+	m.logger().Info("Order quantity changed", "orderQty", quantity)
+	return nil
+}
+
+// Whether both quotes may be resting on the book: active, not paused and with orders placed
+func (m *MarketMaker) IsQuoting() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return !m.quotingSince.IsZero()
+}
+
+// Total time spent quoting since the market maker was created
+func (m *MarketMaker) QuotingTime() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := m.quotingTotal
+	if !m.quotingSince.IsZero() {
+		total += time.Since(m.quotingSince)
+	}
+
+	return total
+}
+
+// Mid price seen at the last refresh, false before the first one
+func (m *MarketMaker) MidPrice() (decimal.Decimal, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.lastMid, !m.lastMid.IsZero()
+}
+
+// Net result of the fills on the traded symbol
+func (m *MarketMaker) Position() ordermanager.Position {
+	return m.client.GetOrderManager().Position(m.symbol)
+}
+
+// Logger carrying the strategy and symbol on every line
+func (m *MarketMaker) logger() *slog.Logger {
+	return slog.With("strategy", m.model.Name(), "symbol", m.symbol)
+}
+
+// Start or end a quoting period after a change to the state or the quotes, called with mu held
+func (m *MarketMaker) trackQuoting() {
+	quoting := m.active && !m.paused && len(m.activeOrders) > 0
+
+	switch {
+	case quoting && m.quotingSince.IsZero():
+		m.quotingSince = time.Now()
+	case !quoting && !m.quotingSince.IsZero():
+		m.quotingTotal += time.Since(m.quotingSince)
+		m.quotingSince = time.Time{}
+	}
+}
+
 func (m *MarketMaker) Start() {
 	m.mu.Lock()
 	if m.active {
 		m.mu.Unlock()
-		log.Println("Market maker is already running")
+		m.logger().Warn("Market maker is already running")
 		return
 	}
 
+	// A fresh context lets a stopped market maker be started again
 	m.active = true
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	ctx := m.ctx
+	m.stopped = make(chan struct{})
+	stopped := m.stopped
 	m.mu.Unlock()
 
-	go m.tradingLoop()
+	go m.tradingLoop(ctx, stopped)
 }
 
+// Stop the trading loop and cancel the resting quotes. Returns once the loop
+// has exited, so no order is placed afterwards.
 func (m *MarketMaker) Stop() {
 	m.mu.Lock()
 	if !m.active {
 		m.mu.Unlock()
-		log.Println("Market maker is not running")
+		m.logger().Warn("Market maker is not running")
 		return
 	}
 
 	m.active = false
 	m.cancel()
+	stopped := m.stopped
+	m.mu.Unlock()
 
+	// A refresh in progress cancels any order it places from here on
+	if stopped != nil {
+		<-stopped
+	}
+
+	m.mu.Lock()
 	activeOrdersRead := make(map[int64]string)
 	maps.Copy(activeOrdersRead, m.activeOrders)
 
 	// clear active orders
 	m.activeOrders = make(map[int64]string)
+	m.trackQuoting()
 	m.mu.Unlock()
 
-	log.Println("Stopping market maker and canceling all orders")
+	logger := m.logger()
+	logger.Info("Stopping market maker and canceling all orders")
 
-	for orderID, order := range activeOrdersRead {
-		log.Printf("Canceling %s order %d", order, orderID)
+	for orderID, side := range activeOrdersRead {
+		logger.Info("Canceling order", "side", side, "orderId", orderID)
 
-		_, err := m.client.CancelOrder(orderID)
+		_, err := m.client.CancelOrder(m.symbol, orderID)
 		if err != nil {
-			log.Printf("Failed to cancel order %d: %v", orderID, err)
+			logger.Warn("Failed to cancel order", "orderId", orderID, "error", err)
 		}
 	}
-
-	m.mu.Lock()
 }
 
-func (m *MarketMaker) tradingLoop() {
-	log.Printf("Starting market maker for %s with %.2f%% spread", m.symbol, m.spreadPercentage)
+func (m *MarketMaker) tradingLoop(ctx context.Context, stopped chan struct{}) {
+	defer close(stopped)
+
+	logger := m.logger()
+	logger.Info("Starting market maker", "spreadPercentage", m.SpreadPercentage(), "model", m.model.Describe())
 
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
 	// Initial market state update
 	if err := m.updateMarketState(); err != nil {
-		log.Printf("Initial market state update failed: %v", err)
+		logger.Warn("Initial market state update failed", "error", err)
 	}
 
 	for {
@@ -114,21 +317,27 @@ func (m *MarketMaker) tradingLoop() {
 				return
 			}
 
+			if m.IsPaused() {
+				continue
+			}
+
 			// Update market state and place orders
 			if err := m.updateMarketState(); err != nil {
-				log.Printf("Failed to update market state: %v", err)
+				logger.Warn("Failed to update market state", "error", err)
 				continue
 			}
 
-		case <-m.ctx.Done():
-			log.Println("Trading loop stopped due to context cancellation")
+		case <-ctx.Done():
+			logger.Info("Trading loop stopped due to context cancellation")
 			return
 		}
 	}
 }
 
 func (m *MarketMaker) updateMarketState() error {
-	orderbook, err := m.client.GetOrderbook(10)
+	m.syncQuotes()
+
+	orderbook, err := m.client.GetOrderbook(m.symbol, max(orderbookLevels, m.model.Levels()))
 	if err != nil {
 		return fmt.Errorf("failed to get orderbook: %w", err)
 	}
@@ -140,18 +349,46 @@ func (m *MarketMaker) updateMarketState() error {
 	highestBidPrice := orderbook.Bids[0].Price
 	lowestAskPrice := orderbook.Asks[0].Price
 
-	midPrice := (lowestAskPrice + highestBidPrice) / 2
-	spreadAmount := midPrice * (m.spreadPercentage / 100)
+	midPrice := lowestAskPrice.Add(highestBidPrice).Div(two, decimal.Nearest)
 
-	bidPrice := midPrice - spreadAmount
-	askPrice := midPrice + spreadAmount
+	orderQty, err := decimal.NewFromString(m.OrderQuantity())
+	if err != nil || !orderQty.IsPositive() {
+		return fmt.Errorf("invalid order quantity %q, not quoting", m.OrderQuantity())
+	}
 
-	log.Printf("Market: Bid=%.8f, Ask=%.8f, Mid=%.8f", highestBidPrice, lowestAskPrice, midPrice)
+	quote, err := m.model.Quotes(orderbook, QuoteState{
+		Inventory:        m.Position().Base,
+		OrderQuantity:    orderQty,
+		SpreadPercentage: m.SpreadPercentage(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to price quotes: %w", err)
+	}
+
+	// A quote at or through the opposite touch would trade as a taker
+	if tick, err := decimal.NewFromString(m.tickSize); err == nil {
+		if !quote.Bid.LessThan(lowestAskPrice) {
+			quote.Bid = lowestAskPrice.Sub(tick)
+		}
+		if !quote.Ask.GreaterThan(highestBidPrice) {
+			quote.Ask = highestBidPrice.Add(tick)
+		}
+	}
+
+	logger := m.logger()
+	imbalance, _ := indicators.Imbalance(orderbook, 0)
+	logger.Info("Market", "bid", highestBidPrice, "ask", lowestAskPrice, "mid", midPrice,
+		"fairValue", quote.FairValue, "imbalance", fmt.Sprintf("%.3f", imbalance))
+
+	m.mu.Lock()
+	m.lastMid = midPrice
+	m.mu.Unlock()
 
-	askPriceStr := utils.FormatPrice(askPrice, m.tickSize)
-	bidPriceStr := utils.FormatPrice(bidPrice, m.tickSize)
+	// Round each quote onto a tick, away from the mid so it never becomes more aggressive
+	askPriceStr := utils.FormatPrice(quote.Ask, m.tickSize, utils.PriceRounding("SELL"))
+	bidPriceStr := utils.FormatPrice(quote.Bid, m.tickSize, utils.PriceRounding("BUY"))
 
-	log.Printf("Our prices: Bid=%s, Ask=%s", bidPriceStr, askPriceStr)
+	logger.Info("Quotes", "bid", bidPriceStr, "ask", askPriceStr)
 
 	if err := m.refreshOrders(askPriceStr, bidPriceStr); err != nil {
 		return fmt.Errorf("failed to refresh orders: %w", err)
@@ -160,33 +397,66 @@ func (m *MarketMaker) updateMarketState() error {
 	return nil
 }
 
+// Refresh resting quotes from the exchange so fills since the last round
+// are in the position before it is used as inventory
+func (m *MarketMaker) syncQuotes() {
+	m.mu.RLock()
+	orderIDs := slices.Collect(maps.Keys(m.activeOrders))
+	m.mu.RUnlock()
+
+	for _, orderID := range orderIDs {
+		order, err := m.client.GetOrderStatus(m.symbol, orderID)
+		if err != nil {
+			m.logger().Warn("Failed to check order", "orderId", orderID, "error", err)
+			continue
+		}
+
+		switch models.OrderStatus(order.Status) {
+		case models.OrderStatusNew, models.OrderStatusPartiallyFilled:
+			continue
+		}
+
+		m.logger().Info("Quote closed", "orderId", orderID, "status", order.Status, "executedQty", order.ExecutedQty)
+
+		m.mu.Lock()
+		delete(m.activeOrders, orderID)
+		m.trackQuoting()
+		m.mu.Unlock()
+	}
+}
+
 func (m *MarketMaker) refreshOrders(askPrice string, bidPrice string) error {
 	m.mu.RLock()
 	activeOrdersRead := make(map[int64]string)
 	maps.Copy(activeOrdersRead, m.activeOrders)
 	m.mu.RUnlock()
 
-	for orderID, order := range activeOrdersRead {
-		log.Printf("Canceling %s order %d", order, orderID)
+	logger := m.logger()
 
-		_, err := m.client.CancelOrder(orderID)
+	for orderID, side := range activeOrdersRead {
+		logger.Info("Canceling order", "side", side, "orderId", orderID)
+
+		_, err := m.client.CancelOrder(m.symbol, orderID)
 		if err != nil {
-			log.Printf("Failed to cancel order %d: %v", orderID, err)
+			logger.Warn("Failed to cancel order", "orderId", orderID, "error", err)
 		}
 
 		m.mu.Lock()
 		delete(m.activeOrders, orderID)
+		m.trackQuoting()
 		m.mu.Unlock()
 	}
 
-	if err := m.placeNewOrder("BUY", "LIMIT", bidPrice, m.orderQty); err != nil {
+	orderQty := m.OrderQuantity()
+
+	if err := m.placeNewOrder("BUY", "LIMIT", bidPrice, orderQty); err != nil {
 		return fmt.Errorf("failed to place new bid orders: %w", err)
 	}
 
 	// Wait to avoid rate limits
 	time.Sleep(200 * time.Millisecond)
 
-	if err := m.placeNewOrder("SELL", "LIMIT", askPrice, m.orderQty); err != nil {
+	if err := m.placeNewOrder("SELL", "LIMIT", askPrice, orderQty); err != nil {
 		return fmt.Errorf("failed to place new ask orders: %w", err)
 	}
 
@@ -200,15 +470,31 @@ func (m *MarketMaker) placeNewOrder(side string, orderType string, price string,
 		return fmt.Errorf("market maker stopped while refreshing orders")
 	}
 
-	order, err := m.client.PlaceOrder(side, orderType, price, qty)
+	if m.IsPaused() {
+		return fmt.Errorf("market maker paused while refreshing orders")
+	}
+
+	order, err := m.client.PlaceOrder(m.symbol, side, orderType, price, qty)
 	if err != nil {
 		return fmt.Errorf("failed to place %s order: %w", side, err)
 	}
 
-	log.Printf("Placed %s order: %d (%s @ %s)", side, order.OrderID, qty, price)
+	m.logger().Info("Placed order", "side", side, "orderId", order.OrderID, "quantity", qty, "price", price)
 
 	m.mu.Lock()
+	if !m.active || m.paused {
+		// Stop or Pause already swept the quotes, so this one would be left resting
+		m.mu.Unlock()
+
+		if _, err := m.client.CancelOrder(m.symbol, order.OrderID); err != nil {
+			m.logger().Warn("Failed to cancel order", "orderId", order.OrderID, "error", err)
+		}
+
+		return fmt.Errorf("market maker stopped or paused while refreshing orders")
+	}
+
 	m.activeOrders[order.OrderID] = side
+	m.trackQuoting()
 	m.mu.Unlock()
 
 	return nil
@@ -1,25 +1,45 @@
 package trader
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/decimal"
 	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
 	"github.com/iamramtin/binance-trader/internal/utils"
+	"github.com/iamramtin/binance-trader/internal/websocket"
 )
 
 type MockBinanceClient struct {
 	orderbook      *models.ParsedOrderBook
 	placedOrders   []*models.Order
 	canceledOrders []int64
+	orderManager   *ordermanager.Manager
 }
 
-func (m *MockBinanceClient) GetOrderbook(limit int) (*models.ParsedOrderBook, error) {
+var _ api.Exchange = (*MockBinanceClient)(nil)
+
+func NewMockBinanceClient(orderbook *models.ParsedOrderBook) *MockBinanceClient {
+	return &MockBinanceClient{
+		orderbook:    orderbook,
+		orderManager: ordermanager.New(),
+	}
+}
+
+func (m *MockBinanceClient) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
 	return m.orderbook, nil
 }
 
-func (m *MockBinanceClient) PlaceOrder(side, orderType, price, quantity string) (*models.Order, error) {
+func (m *MockBinanceClient) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
 	order := &models.Order{
-		Symbol:  "BTCUSDT",
+		Symbol:  symbol,
 		OrderID: int64(len(m.placedOrders) + 1),
 		Status:  "NEW",
 		Side:    side,
@@ -31,35 +51,60 @@ func (m *MockBinanceClient) PlaceOrder(side, orderType, price, quantity string)
 	return order, nil
 }
 
-func (m *MockBinanceClient) CancelOrder(orderID int64) (*models.Order, error) {
+func (m *MockBinanceClient) CancelOrder(symbol string, orderID int64) (*models.Order, error) {
 	m.canceledOrders = append(m.canceledOrders, orderID)
 	return &models.Order{
+		Symbol:  symbol,
 		OrderID: orderID,
 		Status:  "CANCELED",
 	}, nil
 }
 
+func (m *MockBinanceClient) GetOrderStatus(symbol string, orderID int64) (*models.Order, error) {
+	return m.orderManager.GetOrder(symbol, orderID)
+}
+
+func (m *MockBinanceClient) GetAccountBalance() (*models.AccountResponse, error) {
+	return &models.AccountResponse{Status: 200}, nil
+}
+
+func (m *MockBinanceClient) GetTradingPairBalance(baseAsset string, quoteAsset string) (map[string]decimal.Decimal, error) {
+	return map[string]decimal.Decimal{baseAsset: decimal.Zero, quoteAsset: decimal.Zero}, nil
+}
+
+// Orderbook level from price and quantity strings
+func level(price, quantity string) models.PriceLevel {
+	return models.PriceLevel{
+		Price:    decimal.RequireFromString(price),
+		Quantity: decimal.RequireFromString(quantity),
+	}
+}
+
+func (m *MockBinanceClient) GetOrderManager() *ordermanager.Manager {
+	return m.orderManager
+}
+
 func TestCalculatePrices(t *testing.T) {
 	// Create a mock orderbook
 	orderbook := &models.ParsedOrderBook{
-		Bids: []models.PriceLevel{{Price: 9000.0, Quantity: 1.0}},
-		Asks: []models.PriceLevel{{Price: 9100.0, Quantity: 1.0}},
+		Bids: []models.PriceLevel{level("9000.00", "1.0")},
+		Asks: []models.PriceLevel{level("9100.00", "1.0")},
 	}
 
 	// Calculate mid price
-	midPrice := (orderbook.Bids[0].Price + orderbook.Asks[0].Price) / 2 // 9050.0
+	midPrice := orderbook.Bids[0].Price.Add(orderbook.Asks[0].Price).Div(decimal.NewFromInt(2), decimal.Nearest) // 9050.0
 
 	// Calculate spread amount (1% of mid price)
-	spreadPercentage := 1.0
-	spreadAmount := midPrice * (spreadPercentage / 100) // 90.5
+	spreadPercentage := decimal.NewFromInt(1)
+	spreadAmount := midPrice.Mul(spreadPercentage).Div(decimal.NewFromInt(100), decimal.Nearest) // 90.5
 
 	// Calculate bid and ask prices
-	bidPrice := midPrice - spreadAmount // 8959.5
-	askPrice := midPrice + spreadAmount // 9140.5
+	bidPrice := midPrice.Sub(spreadAmount) // 8959.5
+	askPrice := midPrice.Add(spreadAmount) // 9140.5
 
 	// Format prices
-	bidPriceStr := utils.FormatPrice(bidPrice, "0.01") // 8959.50
-	askPriceStr := utils.FormatPrice(askPrice, "0.01") // 9140.50
+	bidPriceStr := utils.FormatPrice(bidPrice, "0.01", decimal.Floor) // 8959.50
+	askPriceStr := utils.FormatPrice(askPrice, "0.01", decimal.Ceil)  // 9140.50
 
 	// Verify the calculations
 	if bidPriceStr != "8959.50" {
@@ -70,3 +115,375 @@ func TestCalculatePrices(t *testing.T) {
 		t.Errorf("Ask price calculation = %s; want %s", askPriceStr, "9140.50")
 	}
 }
+
+func TestUpdateMarketStateRefreshesQuotes(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("9000.00", "1.0")},
+		Asks: []models.PriceLevel{level("9100.00", "1.0")},
+	})
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+	maker.active = true
+
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error: %v", err)
+	}
+
+	if len(client.placedOrders) != 2 {
+		t.Fatalf("expected 2 placed orders, got %d", len(client.placedOrders))
+	}
+
+	bid, ask := client.placedOrders[0], client.placedOrders[1]
+	if bid.Side != "BUY" || bid.Price != "8959.50" || bid.OrigQty != "0.001" {
+		t.Errorf("unexpected bid: %s %s @ %s", bid.Side, bid.OrigQty, bid.Price)
+	}
+
+	if ask.Side != "SELL" || ask.Price != "9140.50" || ask.OrigQty != "0.001" {
+		t.Errorf("unexpected ask: %s %s @ %s", ask.Side, ask.OrigQty, ask.Price)
+	}
+
+	// The next refresh replaces both quotes
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error: %v", err)
+	}
+
+	sort.Slice(client.canceledOrders, func(i, j int) bool { return client.canceledOrders[i] < client.canceledOrders[j] })
+	if len(client.canceledOrders) != 2 || client.canceledOrders[0] != 1 || client.canceledOrders[1] != 2 {
+		t.Errorf("expected orders 1 and 2 to be canceled, got %v", client.canceledOrders)
+	}
+
+	if len(client.placedOrders) != 4 {
+		t.Errorf("expected 4 placed orders, got %d", len(client.placedOrders))
+	}
+}
+
+// Records the state each quote is priced from
+type recordingModel struct {
+	QuoteModel
+	states []QuoteState
+}
+
+func (m *recordingModel) Quotes(book *models.ParsedOrderBook, state QuoteState) (Quote, error) {
+	m.states = append(m.states, state)
+	return m.QuoteModel.Quotes(book, state)
+}
+
+// Client connected to a venue that fills the first bid it is sent and
+// rejects cancels of filled orders, as Binance does
+func newFillingClient(t *testing.T) *api.BinanceClient {
+	t.Helper()
+
+	type order struct{ side, price, quantity, status string }
+	orders := make(map[string]*order)
+
+	respond := func(method string, params map[string]string) (int, string) {
+		switch method {
+		case "depth":
+			return 200, `{"lastUpdateId":1,"bids":[["9000.00","1.0"]],"asks":[["9100.00","1.0"]]}`
+		case "order.place":
+			id := strconv.Itoa(len(orders) + 1)
+			orders[id] = &order{side: params["side"], price: params["price"], quantity: params["quantity"], status: "NEW"}
+			if id == "1" {
+				orders[id].status = "FILLED"
+			}
+			return 200, fmt.Sprintf(`{"symbol":"BTCUSDT","orderId":%s,"side":%q,"price":%q,"origQty":%q,"status":"NEW"}`,
+				id, params["side"], params["price"], params["quantity"])
+		}
+
+		o, ok := orders[params["orderId"]]
+		if !ok || (method == "order.cancel" && o.status == "FILLED") {
+			return 400, `{"code":-2011,"msg":"Unknown order sent."}`
+		}
+		if method == "order.cancel" {
+			o.status = "CANCELED"
+		}
+
+		executed, quote := "0", "0"
+		if o.status == "FILLED" {
+			price, _ := decimal.NewFromString(o.price)
+			qty, _ := decimal.NewFromString(o.quantity)
+			executed, quote = o.quantity, price.Mul(qty).String()
+		}
+
+		return 200, fmt.Sprintf(`{"symbol":"BTCUSDT","orderId":%s,"side":%q,"price":%q,"origQty":%q,"executedQty":%q,"cummulativeQuoteQty":%q,"status":%q}`,
+			params["orderId"], o.side, o.price, o.quantity, executed, quote, o.status)
+	}
+
+	transport := websocket.NewPipeTransport(func(server websocket.Conn) {
+		for {
+			message, err := server.Read()
+			if err != nil {
+				return
+			}
+
+			var request struct {
+				ID     string         `json:"id"`
+				Method string         `json:"method"`
+				Params map[string]any `json:"params"`
+			}
+			if err := json.Unmarshal(message, &request); err != nil {
+				t.Errorf("server received invalid request: %v", err)
+				return
+			}
+
+			params := make(map[string]string, len(request.Params))
+			for k, v := range request.Params {
+				params[k] = fmt.Sprintf("%v", v)
+			}
+
+			status, body := respond(request.Method, params)
+
+			field := "result"
+			if status != 200 {
+				field = "error"
+			}
+
+			server.Write([]byte(fmt.Sprintf(`{"id":"%s","status":%d,"%s":%s}`, request.ID, status, field, body)))
+		}
+	})
+
+	client := api.New("pipe://exchange", "apiKey", "secretKey", api.WithWebSocketOptions(websocket.WithTransport(transport)))
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func TestFilledQuotesBecomeInventory(t *testing.T) {
+	client := newFillingClient(t)
+	model := &recordingModel{QuoteModel: NewFixedSpread(MidPrice{})}
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01", WithQuoteModel(model))
+	maker.active = true
+
+	for range 2 {
+		if err := maker.updateMarketState(); err != nil {
+			t.Fatalf("updateMarketState() returned error: %v", err)
+		}
+	}
+
+	// The first bid filled between the two refreshes
+	if len(model.states) != 2 || !model.states[0].Inventory.IsZero() || model.states[1].Inventory.String() != "0.001" {
+		t.Errorf("expected quotes priced at inventory 0 then 0.001, got %+v", model.states)
+	}
+
+	if base := maker.Position().Base; base.String() != "0.001" {
+		t.Errorf("expected position of 0.001, got %s", base)
+	}
+
+	if open := client.GetOrderManager().GetActiveOrdersBySymbol("BTCUSDT"); len(open) != 2 {
+		t.Errorf("expected only the latest two quotes open, got %d", len(open))
+	}
+}
+
+func TestUpdateMarketStateEmptyOrderbook(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{})
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+	maker.active = true
+
+	if err := maker.updateMarketState(); err == nil {
+		t.Error("expected an error for an empty orderbook")
+	}
+
+	if len(client.placedOrders) != 0 {
+		t.Errorf("expected no orders to be placed, got %d", len(client.placedOrders))
+	}
+}
+
+func TestUpdateMarketStateInvalidQuantity(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "1")},
+		Asks: []models.PriceLevel{level("101", "1")},
+	})
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "not a number", "0.01")
+	maker.active = true
+
+	if err := maker.updateMarketState(); err == nil {
+		t.Error("expected an error for an invalid order quantity")
+	}
+
+	if len(client.placedOrders) != 0 {
+		t.Errorf("expected no orders to be placed, got %d", len(client.placedOrders))
+	}
+}
+
+func TestQuotesRoundAwayFromMid(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("100.00", "1.0")},
+		Asks: []models.PriceLevel{level("100.03", "1.0")},
+	})
+
+	// Mid is 100.015, between two ticks
+	maker := New(client, "BTCUSDT", decimal.Zero, "0.001", "0.01000000")
+	maker.active = true
+
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error: %v", err)
+	}
+
+	if len(client.placedOrders) != 2 {
+		t.Fatalf("expected 2 placed orders, got %d", len(client.placedOrders))
+	}
+
+	if bid := client.placedOrders[0]; bid.Price != "100.01" {
+		t.Errorf("expected bid rounded down to 100.01, got %s", bid.Price)
+	}
+
+	if ask := client.placedOrders[1]; ask.Price != "100.02" {
+		t.Errorf("expected ask rounded up to 100.02, got %s", ask.Price)
+	}
+}
+
+func TestPauseCancelsQuotesUntilResumed(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("9000.00", "1.0")},
+		Asks: []models.PriceLevel{level("9100.00", "1.0")},
+	})
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+	maker.active = true
+
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error: %v", err)
+	}
+
+	maker.Pause()
+
+	if len(client.canceledOrders) != 2 {
+		t.Errorf("expected both quotes to be canceled, got %v", client.canceledOrders)
+	}
+
+	if err := maker.updateMarketState(); err == nil {
+		t.Error("expected a paused market maker to refuse to quote")
+	}
+
+	if len(client.placedOrders) != 2 {
+		t.Errorf("expected no new orders while paused, got %d", len(client.placedOrders))
+	}
+
+	maker.Resume()
+
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error after resuming: %v", err)
+	}
+
+	if len(client.placedOrders) != 4 {
+		t.Errorf("expected quotes again after resuming, got %d orders", len(client.placedOrders))
+	}
+}
+
+// Exchange whose first order placement waits until released
+type slowPlaceExchange struct {
+	*MockBinanceClient
+	placing chan struct{}
+	release chan struct{}
+}
+
+func (e *slowPlaceExchange) PlaceOrder(symbol, side, orderType, price, quantity string) (*models.Order, error) {
+	select {
+	case e.placing <- struct{}{}:
+		<-e.release
+	default:
+	}
+
+	return e.MockBinanceClient.PlaceOrder(symbol, side, orderType, price, quantity)
+}
+
+func TestStopWaitsForRefreshAndCancelsLateOrders(t *testing.T) {
+	client := &slowPlaceExchange{
+		MockBinanceClient: NewMockBinanceClient(&models.ParsedOrderBook{
+			Bids: []models.PriceLevel{level("9000.00", "1.0")},
+			Asks: []models.PriceLevel{level("9100.00", "1.0")},
+		}),
+		placing: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+	maker.Start()
+	<-client.placing
+
+	stopped := make(chan struct{})
+	go func() {
+		maker.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("expected Stop to wait for the order being placed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(client.release)
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	// The bid landed after Stop began, so it was canceled and no ask followed
+	if len(client.placedOrders) != 1 || len(client.canceledOrders) != 1 || client.canceledOrders[0] != client.placedOrders[0].OrderID {
+		t.Errorf("expected the late bid to be canceled, placed %d and canceled %v", len(client.placedOrders), client.canceledOrders)
+	}
+}
+
+func TestSetSpreadPercentage(t *testing.T) {
+	maker := New(NewMockBinanceClient(&models.ParsedOrderBook{}), "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+
+	if err := maker.SetSpreadPercentage(decimal.Zero); err == nil {
+		t.Error("expected a zero spread to be rejected")
+	}
+
+	if err := maker.SetSpreadPercentage(decimal.RequireFromString("0.25")); err != nil {
+		t.Fatalf("SetSpreadPercentage() returned error: %v", err)
+	}
+
+	if got := maker.SpreadPercentage().String(); got != "0.25" {
+		t.Errorf("expected spread 0.25, got %s", got)
+	}
+}
+
+func TestQuotingTime(t *testing.T) {
+	client := NewMockBinanceClient(&models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("9000.00", "1.0")},
+		Asks: []models.PriceLevel{level("9100.00", "1.0")},
+	})
+
+	maker := New(client, "BTCUSDT", decimal.NewFromInt(1), "0.001", "0.01")
+	maker.active = true
+
+	if _, ok := maker.MidPrice(); ok || maker.IsQuoting() {
+		t.Fatal("expected no mid price and no quotes before the first refresh")
+	}
+
+	if err := maker.updateMarketState(); err != nil {
+		t.Fatalf("updateMarketState() returned error: %v", err)
+	}
+
+	if mid, ok := maker.MidPrice(); !ok || mid.String() != "9050" {
+		t.Errorf("expected mid price 9050, got %s", mid)
+	}
+
+	if !maker.IsQuoting() {
+		t.Error("expected the market maker to be quoting after a refresh")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	maker.Pause()
+
+	quoted := maker.QuotingTime()
+	if maker.IsQuoting() || quoted < 10*time.Millisecond {
+		t.Errorf("expected quoting to stop on pause after at least 10ms, got %v", quoted)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if maker.QuotingTime() != quoted {
+		t.Error("expected quoting time to stand still while paused")
+	}
+}
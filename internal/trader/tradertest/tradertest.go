@@ -0,0 +1,103 @@
+// Package tradertest provides stand-ins for the exchange and strategies in
+// tests of the packages that operate them.
+package tradertest
+
+import (
+	"errors"
+
+	"github.com/iamramtin/binance-trader/internal/api"
+	"github.com/iamramtin/binance-trader/internal/decimal"
+	"github.com/iamramtin/binance-trader/internal/models"
+	"github.com/iamramtin/binance-trader/internal/ordermanager"
+	"github.com/iamramtin/binance-trader/internal/trader"
+)
+
+// Exchange answers with a fixed orderbook and records cancel-alls
+type Exchange struct {
+	Manager  *ordermanager.Manager
+	Canceled []string        // Symbols canceled, in order
+	Failing  map[string]bool // Symbols whose requests time out
+}
+
+var _ api.Operations = (*Exchange)(nil)
+
+func NewExchange() *Exchange {
+	return &Exchange{Manager: ordermanager.New(), Failing: map[string]bool{}}
+}
+
+func (e *Exchange) GetOrderbook(symbol string, limit int) (*models.ParsedOrderBook, error) {
+	if e.Failing[symbol] {
+		return nil, errors.New("timeout")
+	}
+
+	return Book(), nil
+}
+
+func (e *Exchange) GetOrderManager() *ordermanager.Manager {
+	return e.Manager
+}
+
+func (e *Exchange) CancelAllOrders(symbol string) ([]models.Order, error) {
+	if e.Failing[symbol] {
+		return nil, errors.New("timeout")
+	}
+
+	e.Canceled = append(e.Canceled, symbol)
+	return []models.Order{{Symbol: symbol, OrderID: 1, Status: "CANCELED"}}, nil
+}
+
+// Two levels a side around a mid of 100
+func Book() *models.ParsedOrderBook {
+	level := func(price, quantity string) models.PriceLevel {
+		return models.PriceLevel{Price: decimal.RequireFromString(price), Quantity: decimal.RequireFromString(quantity)}
+	}
+
+	return &models.ParsedOrderBook{
+		Bids: []models.PriceLevel{level("99", "2"), level("98", "3")},
+		Asks: []models.PriceLevel{level("101", "1"), level("102", "4")},
+	}
+}
+
+// Quoter is a market maker stand-in that records the controls used
+type Quoter struct {
+	symbol   string
+	Active   bool
+	Paused   bool
+	Spread   decimal.Decimal
+	Quantity string
+}
+
+var _ trader.Quoter = (*Quoter)(nil)
+
+// Active quoter for a symbol at a spread percentage
+func NewQuoter(symbol, spread, quantity string) *Quoter {
+	return &Quoter{symbol: symbol, Active: true, Spread: decimal.RequireFromString(spread), Quantity: quantity}
+}
+
+func (q *Quoter) Symbol() string                    { return q.symbol }
+func (q *Quoter) Start()                            { q.Active = true }
+func (q *Quoter) Stop()                             { q.Active = false }
+func (q *Quoter) IsActive() bool                    { return q.Active }
+func (q *Quoter) Pause()                            { q.Paused = true }
+func (q *Quoter) Resume()                           { q.Paused = false }
+func (q *Quoter) IsPaused() bool                    { return q.Paused }
+func (q *Quoter) SpreadPercentage() decimal.Decimal { return q.Spread }
+func (q *Quoter) OrderQuantity() string             { return q.Quantity }
+
+func (q *Quoter) SetSpreadPercentage(spread decimal.Decimal) error {
+	if !spread.IsPositive() {
+		return errors.New("spread percentage must be greater than 0")
+	}
+
+	q.Spread = spread
+	return nil
+}
+
+func (q *Quoter) SetOrderQuantity(quantity decimal.Decimal) error {
+	if !quantity.IsPositive() {
+		return errors.New("order quantity must be greater than 0")
+	}
+
+	q.Quantity = quantity.String()
+	return nil
+}
@@ -4,13 +4,17 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
-	"math"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
 )
 
 // Current Unix timestamp in milliseconds
@@ -30,8 +34,34 @@ func GenerateHMAC(secretKey string, data string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
-// Generate HMAC using the SHA-256 hash function and a key
-func GenerateSignature(secretKey string, params map[string]string) string {
+// Produces request signatures. The secret key may live outside this process.
+type Signer interface {
+	Sign(payload string) (string, error)
+}
+
+// Signs with HMAC-SHA-256 using a secret key held in memory
+type HMACSigner string
+
+func (s HMACSigner) Sign(payload string) (string, error) {
+	return GenerateHMAC(string(s), payload), nil
+}
+
+// Keep the key out of formatted output
+func (s HMACSigner) String() string {
+	return "HMACSigner([REDACTED])"
+}
+
+// Sign request parameters with a signer
+func GenerateSignature(signer Signer, params map[string]string) (string, error) {
+	if signer == nil {
+		return "", errors.New("no secret key or signer configured")
+	}
+
+	return signer.Sign(SignaturePayload(params))
+}
+
+// Query string Binance signs: every parameter, sorted by name
+func SignaturePayload(params map[string]string) string {
 	// Sort keys alphabetically
 	var keys []string
 	for k := range params {
@@ -44,42 +74,82 @@ func GenerateSignature(secretKey string, params map[string]string) string {
 	for _, k := range keys {
 		queryParts = append(queryParts, fmt.Sprintf("%s=%s", k, params[k]))
 	}
-	queryString := strings.Join(queryParts, "&")
-
-	return GenerateHMAC(secretKey, queryString)
+	return strings.Join(queryParts, "&")
 }
 
-func AuthenticateAPIKeys(apiKey string, secretKey string) error {
-	if apiKey == "" || secretKey == "" {
-		log.Println("Generate Generate HMAC-SHA-256 Key: https://testnet.binance.vision")
-		log.Println("Set BINANCE_API_KEY and BINANCE_SECRET_KEY environment variables.")
+func AuthenticateAPIKeys(apiKey string, signer Signer) error {
+	if apiKey == "" || signer == nil {
+		slog.Warn("Generate an HMAC-SHA-256 key at https://testnet.binance.vision and set BINANCE_API_KEY and BINANCE_SECRET_KEY")
 		return fmt.Errorf("API key and Secret key are required for order operations to work")
 	}
 	return nil
 }
 
 // FormatPrice formats a price according to tick size
-func FormatPrice(price float64, tickSize string) string {
+func FormatPrice(price decimal.Decimal, tickSize string, mode decimal.RoundingMode) string {
 	// Parse tick size
-	tickSizeFloat, err := strconv.ParseFloat(tickSize, 64)
+	tick, err := decimal.NewFromString(tickSize)
 	if err != nil {
-		log.Printf("Error parsing tick size: %v", err)
-		return fmt.Sprintf("%.2f", price) // Fallback to 2 decimal places
+		slog.Warn("Error parsing tick size", "tickSize", tickSize, "error", err)
+		return price.StringFixed(2) // Fallback to 2 decimal places
+	}
+
+	// Round to a multiple of the tick size and format with the tick's decimal places
+	return price.RoundToStep(tick, mode).StringStep(tick)
+}
+
+// FormatQuantity formats a quantity according to lot step size, rounding down
+// so an order never exceeds the balance it was sized from
+func FormatQuantity(quantity decimal.Decimal, stepSize string) string {
+	step, err := decimal.NewFromString(stepSize)
+	if err != nil {
+		slog.Warn("Error parsing step size", "stepSize", stepSize, "error", err)
+		return quantity.String()
+	}
+
+	return quantity.RoundToStep(step, decimal.Floor).StringStep(step)
+}
+
+// PriceRounding picks the rounding that keeps a quote on its own side of the book:
+// bids round down and asks round up, so rounding never makes a quote more aggressive
+func PriceRounding(side string) decimal.RoundingMode {
+	switch side {
+	case "BUY":
+		return decimal.Floor
+	case "SELL":
+		return decimal.Ceil
+	default:
+		return decimal.Nearest
+	}
+}
+
+// Address to listen on, binding to localhost unless a host is given
+func ListenAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// Write data to path through a temporary file in the same directory, so
+// path is only replaced once the new contents are complete
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
 
-	// Round to the nearest tick size
-	nearestPrice := math.Round(price/tickSizeFloat) * tickSizeFloat
-
-	// Calculate the number of decimal places
-	decimalPlaces := 0
-	if tickSizeFloat < 1 {
-		tickStr := fmt.Sprintf("%g", tickSizeFloat)
-		parts := strings.Split(tickStr, ".")
-		if len(parts) > 1 {
-			decimalPlaces = len(parts[1])
-		}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
 
-	// Format the price with the correct number of decimal places
-	return fmt.Sprintf(fmt.Sprintf("%%.%df", decimalPlaces), nearestPrice)
+	return os.Rename(tmp.Name(), path)
 }
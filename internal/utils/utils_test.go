@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"testing"
 	"time"
+
+	"github.com/iamramtin/binance-trader/internal/decimal"
 )
 
 func TestGenerateTimestamp(t *testing.T) {
@@ -122,8 +124,9 @@ func TestGenerateSignature(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GenerateSignature(tt.secretKey, tt.params); got != tt.want {
-				t.Errorf("GenerateSignature() = %v, want %v", got, tt.want)
+			got, err := GenerateSignature(HMACSigner(tt.secretKey), tt.params)
+			if err != nil || got != tt.want {
+				t.Errorf("GenerateSignature() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -164,7 +167,12 @@ func TestAuthenticateAPIKeys(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := AuthenticateAPIKeys(tt.apiKey, tt.secretKey)
+			var signer Signer
+			if tt.secretKey != "" {
+				signer = HMACSigner(tt.secretKey)
+			}
+
+			err := AuthenticateAPIKeys(tt.apiKey, signer)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AuthenticateAPIKeys() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -175,57 +183,126 @@ func TestAuthenticateAPIKeys(t *testing.T) {
 func TestFormatPrice(t *testing.T) {
 	tests := []struct {
 		name     string
-		price    float64
+		price    string
 		tickSize string
+		mode     decimal.RoundingMode
 		want     string
 	}{
 		{
 			name:     "whole number tick size",
-			price:    123.456,
+			price:    "123.456",
 			tickSize: "1",
+			mode:     decimal.Nearest,
 			want:     "123",
 		},
 		{
 			name:     "decimal tick size",
-			price:    123.456,
+			price:    "123.456",
 			tickSize: "0.01",
+			mode:     decimal.Nearest,
 			want:     "123.46",
 		},
 		{
 			name:     "small tick size",
-			price:    0.12345678,
+			price:    "0.12345678",
 			tickSize: "0.00000001",
-			want:     "0", // The FormatPrice function doesn't handle small decimal places correctly
+			mode:     decimal.Nearest,
+			want:     "0.12345678",
+		},
+		{
+			name:     "binance tick size format",
+			price:    "8959.5",
+			tickSize: "0.01000000",
+			mode:     decimal.Nearest,
+			want:     "8959.50",
 		},
 		{
 			name:     "round up",
-			price:    123.456,
+			price:    "123.456",
+			tickSize: "0.1",
+			mode:     decimal.Nearest,
+			want:     "123.5",
+		},
+		{
+			name:     "round to nearest",
+			price:    "123.451",
+			tickSize: "0.1",
+			mode:     decimal.Nearest,
+			want:     "123.5",
+		},
+		{
+			name:     "floor",
+			price:    "123.459",
+			tickSize: "0.1",
+			mode:     decimal.Floor,
+			want:     "123.4",
+		},
+		{
+			name:     "ceil",
+			price:    "123.401",
 			tickSize: "0.1",
+			mode:     decimal.Ceil,
 			want:     "123.5",
 		},
 		{
-			name:     "round down",
-			price:    123.451,
+			name:     "no float error",
+			price:    "0.3",
 			tickSize: "0.1",
-			want:     "123.5", // Note: this is actually rounding to nearest, so 123.451 rounds to 123.5
+			mode:     decimal.Floor,
+			want:     "0.3", // On floats 0.3/0.1 is 2.9999999999999996 and floors to 0.2
 		},
 		{
 			name:     "invalid tick size",
-			price:    123.456,
+			price:    "123.456",
 			tickSize: "invalid",
+			mode:     decimal.Nearest,
 			want:     "123.46", // Falls back to 2 decimal places
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := FormatPrice(tt.price, tt.tickSize); got != tt.want {
+			price := decimal.RequireFromString(tt.price)
+			if got := FormatPrice(price, tt.tickSize, tt.mode); got != tt.want {
 				t.Errorf("FormatPrice() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestFormatQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity string
+		stepSize string
+		want     string
+	}{
+		{name: "rounds down", quantity: "0.0019999", stepSize: "0.00001000", want: "0.00199"},
+		{name: "aligned", quantity: "1.5", stepSize: "0.1", want: "1.5"},
+		{name: "whole lots", quantity: "12.9", stepSize: "1", want: "12"},
+		{name: "invalid step size", quantity: "0.001", stepSize: "invalid", want: "0.001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quantity := decimal.RequireFromString(tt.quantity)
+			if got := FormatQuantity(quantity, tt.stepSize); got != tt.want {
+				t.Errorf("FormatQuantity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceRounding(t *testing.T) {
+	if PriceRounding("BUY") != decimal.Floor {
+		t.Error("expected bids to round down")
+	}
+
+	if PriceRounding("SELL") != decimal.Ceil {
+		t.Error("expected asks to round up")
+	}
+}
+
 // Helper function to get absolute difference between two int64 values
 func abs(x int64) int64 {
 	if x < 0 {
@@ -247,3 +324,18 @@ func TestMain(m *testing.M) {
 
 	os.Exit(exitCode)
 }
+
+func TestListenAddrDefaultsToLocalhost(t *testing.T) {
+	tests := map[string]string{
+		":8081":          "127.0.0.1:8081",
+		"0.0.0.0:8081":   "0.0.0.0:8081",
+		"localhost:9000": "localhost:9000",
+		"[::1]:9000":     "[::1]:9000",
+	}
+
+	for addr, want := range tests {
+		if got := ListenAddr(addr); got != want {
+			t.Errorf("ListenAddr(%q) = %q; want %q", addr, got, want)
+		}
+	}
+}
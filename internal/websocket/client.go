@@ -4,71 +4,129 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
 	"github.com/iamramtin/binance-trader/internal/models"
 )
 
 // Handle WebSocket responses
 type ResponseHandler func(response []byte)
 
+// Dispatch queue carrying API responses in arrival order
+const responseQueue = "responses"
+
+// Configure optional client behaviour
+type Option func(*Client)
+
 // WebSocket client
 type Client struct {
-	connection       *websocket.Conn
+	connection       *wsConn // Current live connection
 	reconnecting     bool
 	url              string
 	apiKey           string
 	secretKey        string
 	requestID        string                     // Incremental request ID
 	responseHandlers map[string]ResponseHandler // Maps request IDs to response handlers
+	dispatcher       *Dispatcher                // Ordered, bounded delivery of inbound messages
+	heartbeat        HeartbeatConfig            // Ping, deadline and rotation settings
+	transport        Transport                  // Opens new connections
+	recorder         *Recorder                  // Optional wire-level session recorder
+	recordConn       int                        // Connection ID this client's frames are recorded under
+	latency          atomic.Int64               // Last measured ping round trip in nanoseconds
 	mu               sync.RWMutex               // Mutex for thread safety
 	done             chan struct{}              // Channel to signal shutdown
 }
 
+// Single underlying WebSocket connection
+type wsConn struct {
+	conn        Conn
+	connectedAt time.Time     // Time the connection was established
+	closed      chan struct{} // Closed once the connection is retired
+	closeOnce   sync.Once
+}
+
+func (cn *wsConn) close() {
+	cn.closeOnce.Do(func() {
+		close(cn.closed)
+		cn.conn.Close()
+	})
+}
+
 // Create a new WebSocket client
-func New(url, apiKey, secretKey string) *Client {
-	return &Client{
+func New(url, apiKey, secretKey string, opts ...Option) *Client {
+	c := &Client{
 		url:              url,
 		apiKey:           apiKey,
 		secretKey:        secretKey,
 		responseHandlers: make(map[string]ResponseHandler),
+		dispatcher:       NewDispatcher(),
+		heartbeat:        DefaultHeartbeatConfig(),
 		done:             make(chan struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.transport == nil {
+		c.transport = &GorillaTransport{WriteWait: c.heartbeat.WriteWait}
+	}
+
+	// Responses are handed to waiting callers quickly, so backpressure is preferable to loss
+	c.dispatcher.Register(responseQueue, QueueOptions{Capacity: 1024, Policy: Block}, c.handleMessage)
+
+	return c
 }
 
 // Establish a WebSocket connection to Binance API
 func (c *Client) Connect(ctx context.Context) error {
-	log.Printf("Connecting to Binance WebSocket API: %s", c.url)
+	slog.Info("Connecting to Binance WebSocket API", "url", c.url)
 
-	// Create a websocket dialer
-	dialer := websocket.Dialer{}
+	// Replay dials connections in the order they were first connected
+	if c.recorder != nil {
+		c.recordConn = c.recorder.register()
+	}
 
-	// Connect to the websocket
-	connection, _, err := dialer.DialContext(ctx, c.url, nil)
+	cn, err := c.dial(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
 
-	c.connection = connection
+	c.mu.Lock()
+	c.connection = cn
+	c.mu.Unlock()
 
-	go c.readMessages()
+	c.startConnection(cn)
+	go c.rotationLoop()
 
-	log.Println("Connected to Binance WebSocket API")
+	slog.Info("Connected to Binance WebSocket API")
 	return nil
 }
 
 func (c *Client) Close() {
 	close(c.done) // close channel
 
+	c.mu.Lock()
 	if c.connection != nil {
-		c.connection.Close()
+		c.connection.close()
 	}
+	c.mu.Unlock()
+
+	c.dispatcher.Close()
+
+	slog.Info("WebSocket connection closed")
+}
+
+// Whether a live connection is available for requests
+func (c *Client) Connected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-	log.Println("WebSocket connection closed")
+	return c.connection != nil && !c.reconnecting
 }
 
 func (c *Client) SendRequest(method string, params any, handler ResponseHandler) (string, error) {
@@ -99,60 +157,117 @@ func (c *Client) SendRequest(method string, params any, handler ResponseHandler)
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
 		c.mu.RUnlock()
+		c.CancelRequest(requestID)
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	log.Printf("Sending request: %s", string(requestJSON))
+	// Parameters carry the API key and signature, so only the method is logged
+	slog.Debug("Sending request", "requestId", requestID, "method", method)
+
+	if c.recorder != nil {
+		c.recorder.Record(c.recordConn, Outbound, requestJSON)
+	}
 
 	c.mu.RUnlock()
 	c.mu.Lock()
 
 	// Ensure connection is still valid
-	if c.connection == nil {
+	cn := c.connection
+	if cn == nil {
+		delete(c.responseHandlers, requestID)
 		c.mu.Unlock()
 		return "", fmt.Errorf("WebSocket connection is not established")
 	}
 
 	// Send the request
-	err = c.connection.WriteMessage(websocket.TextMessage, requestJSON)
+	err = cn.conn.Write(requestJSON)
 	c.mu.Unlock()
 
 	if err != nil {
+		c.CancelRequest(requestID)
+
 		// If we failed to write, attempt to reconnect
-		log.Printf("Error sending request: %v, attempting reconnect", err)
-		c.attemptReconnect()
+		slog.Warn("Error sending request, attempting reconnect", "requestId", requestID, "method", method, "error", err)
+		c.attemptReconnect(cn)
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
 
 	return requestID, nil
 }
 
+// Forget the handler of a request whose response is no longer awaited,
+// such as one that timed out
+func (c *Client) CancelRequest(requestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.responseHandlers, requestID)
+}
+
 func (c *Client) Ping() error {
 	_, err := c.SendRequest("ping", nil, func(response []byte) {
-		log.Println("Received pong response")
+		slog.Debug("Received pong response")
 	})
 
 	return err
 }
 
-// Read messages from the WebSocket connection
-func (c *Client) readMessages() {
-	for {
-		select {
-		case <-c.done:
-			return
+// Queue depth and handler latency of inbound message dispatch
+func (c *Client) DispatchStats() []QueueStats {
+	return c.dispatcher.Stats()
+}
+
+// Dial a new connection with heartbeat handlers installed
+func (c *Client) dial(ctx context.Context) (*wsConn, error) {
+	conn, err := c.transport.Dial(ctx, c.url)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &wsConn{
+		conn:        conn,
+		connectedAt: time.Now(),
+		closed:      make(chan struct{}),
+	}
+
+	c.installHeartbeatHandlers(cn)
+
+	return cn, nil
+}
 
-		default:
-			_, message, err := c.connection.ReadMessage()
-			if err != nil {
-				log.Printf("Error reading message: %v", err)
+// Start the reader and heartbeat for a connection
+func (c *Client) startConnection(cn *wsConn) {
+	go c.readMessages(cn)
+	go c.runHeartbeat(cn)
+}
 
-				c.attemptReconnect()
+// Read messages from the WebSocket connection
+func (c *Client) readMessages(cn *wsConn) {
+	for {
+		message, err := cn.conn.Read()
+		if err != nil {
+			select {
+			case <-c.done:
+				return
+			case <-cn.closed:
+				// Connection was retired on purpose
 				return
+			default:
 			}
 
-			go c.handleMessage(message)
+			slog.Warn("Error reading message", "error", err)
+
+			c.attemptReconnect(cn)
+			return
+		}
+
+		c.extendReadDeadline(cn)
+
+		if c.recorder != nil {
+			c.recorder.Record(c.recordConn, Inbound, message)
 		}
+
+		c.dispatcher.Dispatch(responseQueue, message)
 	}
 }
 
@@ -161,12 +276,12 @@ func (c *Client) handleMessage(message []byte) {
 	// Parse the message
 	var response models.WebSocketResponse
 	if err := json.Unmarshal(message, &response); err != nil {
-		log.Printf("Error parsing response: %v", err)
+		slog.Warn("Error parsing response", "error", err)
 		return
 	}
 
 	if response.Error != nil {
-		log.Printf("API Error: Code %d - %s", response.Error.Code, response.Error.Msg)
+		slog.Warn("API error", "requestId", response.ID, "code", response.Error.Code, "msg", response.Error.Msg)
 	}
 
 	// Find the corresponding handler for ID
@@ -188,24 +303,31 @@ func (c *Client) handleMessage(message []byte) {
 	}
 
 	if response.Status == 200 {
-		log.Printf("Received success response for ID: %v", response.ID)
+		slog.Debug("Received success response", "requestId", response.ID)
 	}
 }
 
-func (c *Client) attemptReconnect() {
+// Replace a failed connection, unless it has already been replaced
+func (c *Client) attemptReconnect(failed *wsConn) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Check if already reconnecting
-	if c.reconnecting {
+	// Check if already reconnecting or the connection was already replaced
+	if c.reconnecting || c.connection != failed {
 		return
 	}
 
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
 	c.reconnecting = true
 
 	// Close the existing connection if any
 	if c.connection != nil {
-		c.connection.Close()
+		c.connection.close()
 		c.connection = nil
 	}
 
@@ -216,26 +338,22 @@ func (c *Client) attemptReconnect() {
 		delay := 1 * time.Second
 
 		for attempts < maxAttempts {
-			log.Printf("Attempting to reconnect (attempt %d/%d)", attempts+1, maxAttempts)
-
-			// Create a new dialer
-			dialer := websocket.Dialer{
-				HandshakeTimeout: 10 * time.Second,
-			}
+			slog.Info("Attempting to reconnect", "attempt", attempts+1, "maxAttempts", maxAttempts)
 
 			// Try to connect
-			conn, _, err := dialer.Dial(c.url, nil)
+			cn, err := c.dial(context.Background())
 			if err == nil {
 				// Successful reconnection
 				c.mu.Lock()
-				c.connection = conn
+				c.connection = cn
 				c.reconnecting = false
 				c.mu.Unlock()
 
-				log.Println("Successfully reconnected")
+				slog.Info("Successfully reconnected")
+				reconnects.WithLabelValues("api", "success").Inc()
 
-				// Restart the message reader
-				go c.readMessages()
+				// Restart the message reader and heartbeat
+				c.startConnection(cn)
 
 				// Notify subscribers that we've reconnected
 				// Implementation depends on your design
@@ -243,13 +361,20 @@ func (c *Client) attemptReconnect() {
 				return
 			}
 
-			log.Printf("Reconnection failed: %v", err)
+			slog.Warn("Reconnection failed", "error", err)
+			reconnects.WithLabelValues("api", "failure").Inc()
 			attempts++
-			time.Sleep(delay)
+
+			select {
+			case <-c.done:
+				return
+			case <-time.After(delay):
+			}
+
 			delay *= 2 // Exponential backoff
 		}
 
-		log.Println("Failed to reconnect after maximum attempts")
+		slog.Error("Failed to reconnect after maximum attempts")
 		c.mu.Lock()
 		c.reconnecting = false
 		c.mu.Unlock()
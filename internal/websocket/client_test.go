@@ -0,0 +1,31 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCancelRequestForgetsHandler(t *testing.T) {
+	// Never answers, like an exchange that is too slow
+	url, _ := newTestServer(t, readUntilClosed)
+
+	client := New(url, "", "")
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	requestID, err := client.SendRequest("time", nil, func([]byte) {})
+	if err != nil {
+		t.Fatalf("SendRequest() returned error: %v", err)
+	}
+
+	client.CancelRequest(requestID)
+
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	if len(client.responseHandlers) != 0 {
+		t.Errorf("expected no handlers after the request was abandoned, got %d", len(client.responseHandlers))
+	}
+}
@@ -0,0 +1,264 @@
+package websocket
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// What to do when a queue is full
+type OverflowPolicy int
+
+const (
+	Block             OverflowPolicy = iota // Wait for space, applying backpressure to the reader
+	DropNewest                              // Discard the incoming message
+	DropOldest                              // Discard the oldest queued message to make room
+	CloseSubscription                       // Discard the incoming message and report it, so the consumer can be closed rather than skip a message
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	case CloseSubscription:
+		return "close-subscription"
+	default:
+		return "unknown"
+	}
+}
+
+// Default number of messages buffered per queue
+const defaultQueueCapacity = 256
+
+// Capacity and overflow behaviour of a dispatch queue
+type QueueOptions struct {
+	Capacity int            // Maximum number of buffered messages
+	Policy   OverflowPolicy // Behaviour when the queue is full
+}
+
+// Process a single message
+type MessageHandler func(message []byte)
+
+// Snapshot of a queue's metrics
+type QueueStats struct {
+	Key               string
+	Policy            OverflowPolicy
+	Depth             int // Messages currently buffered
+	Capacity          int
+	Processed         uint64
+	Dropped           uint64
+	AvgHandlerLatency time.Duration
+	MaxHandlerLatency time.Duration
+}
+
+// Bounded FIFO queue drained by a single worker
+type queue struct {
+	key       string
+	options   QueueOptions
+	items     chan []byte
+	handler   MessageHandler
+	stop      chan struct{} // Closed to stop the worker
+	done      chan struct{} // Closed once the worker has exited
+	mu        sync.Mutex    // Protects the metrics below
+	processed uint64
+	dropped   uint64
+	totalTime time.Duration
+	maxTime   time.Duration
+}
+
+// Dispatcher delivers messages in order per key on bounded queues.
+// Each key has its own worker, so a slow consumer only delays its own key.
+type Dispatcher struct {
+	queues map[string]*queue
+	mu     sync.RWMutex
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		queues: make(map[string]*queue),
+	}
+}
+
+// Register a handler for a key and start its worker
+func (d *Dispatcher) Register(key string, options QueueOptions, handler MessageHandler) {
+	if options.Capacity <= 0 {
+		options.Capacity = defaultQueueCapacity
+	}
+
+	q := &queue{
+		key:     key,
+		options: options,
+		items:   make(chan []byte, options.Capacity),
+		handler: handler,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	d.mu.Lock()
+	old := d.queues[key]
+	d.queues[key] = q
+	d.mu.Unlock()
+
+	if old != nil {
+		old.shutdown()
+	}
+
+	go q.run()
+}
+
+// Stop a key's worker and discard anything still queued
+func (d *Dispatcher) Remove(key string) {
+	d.mu.Lock()
+	q, exists := d.queues[key]
+	delete(d.queues, key)
+	d.mu.Unlock()
+
+	if exists {
+		q.shutdown()
+	}
+}
+
+// Stop every worker
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	queues := d.queues
+	d.queues = make(map[string]*queue)
+	d.mu.Unlock()
+
+	for _, q := range queues {
+		q.shutdown()
+	}
+}
+
+// Queue a message for its key, returning false if it was dropped or the key is unknown
+func (d *Dispatcher) Dispatch(key string, message []byte) bool {
+	d.mu.RLock()
+	q, exists := d.queues[key]
+	d.mu.RUnlock()
+
+	if !exists {
+		return false
+	}
+
+	return q.push(message)
+}
+
+// Metrics for every queue, sorted by key
+func (d *Dispatcher) Stats() []QueueStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := make([]QueueStats, 0, len(d.queues))
+	for _, q := range d.queues {
+		stats = append(stats, q.stats())
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Key < stats[j].Key })
+
+	return stats
+}
+
+func (q *queue) push(message []byte) bool {
+	switch q.options.Policy {
+	case DropNewest, CloseSubscription:
+		select {
+		case q.items <- message:
+			return true
+		default:
+			q.recordDrop()
+			return false
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case q.items <- message:
+				return true
+			default:
+			}
+
+			// Make room by discarding the head of the queue
+			select {
+			case <-q.items:
+				q.recordDrop()
+			default:
+			}
+		}
+
+	default:
+		select {
+		case q.items <- message:
+			return true
+		case <-q.stop:
+			return false
+		}
+	}
+}
+
+func (q *queue) run() {
+	defer close(q.done)
+
+	for {
+		select {
+		case <-q.stop:
+			return
+
+		case message := <-q.items:
+			start := time.Now()
+			q.handler(message)
+			q.recordProcessed(time.Since(start))
+		}
+	}
+}
+
+func (q *queue) shutdown() {
+	close(q.stop)
+	<-q.done
+}
+
+func (q *queue) recordProcessed(latency time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.processed++
+	q.totalTime += latency
+	q.maxTime = max(q.maxTime, latency)
+}
+
+func (q *queue) recordDrop() {
+	q.mu.Lock()
+	q.dropped++
+	dropped := q.dropped
+	q.mu.Unlock()
+
+	// Avoid flooding the log under sustained overload
+	if dropped&(dropped-1) == 0 {
+		slog.Warn("Dispatch queue is full", "queue", q.key, "dropped", dropped)
+	}
+}
+
+func (q *queue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := QueueStats{
+		Key:               q.key,
+		Policy:            q.options.Policy,
+		Depth:             len(q.items),
+		Capacity:          q.options.Capacity,
+		Processed:         q.processed,
+		Dropped:           q.dropped,
+		MaxHandlerLatency: q.maxTime,
+	}
+
+	if q.processed > 0 {
+		stats.AvgHandlerLatency = q.totalTime / time.Duration(q.processed)
+	}
+
+	return stats
+}
@@ -0,0 +1,101 @@
+package websocket
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcherPreservesOrder(t *testing.T) {
+	dispatcher := NewDispatcher()
+	defer dispatcher.Close()
+
+	var mu sync.Mutex
+	var received []int
+
+	dispatcher.Register("depth", QueueOptions{Capacity: 8, Policy: Block}, func(message []byte) {
+		n, _ := strconv.Atoi(string(message))
+
+		mu.Lock()
+		received = append(received, n)
+		mu.Unlock()
+	})
+
+	for i := range 100 {
+		if !dispatcher.Dispatch("depth", []byte(strconv.Itoa(i))) {
+			t.Fatalf("Dispatch() dropped message %d under Block policy", i)
+		}
+	}
+
+	waitFor(t, 2*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 100
+	})
+
+	for i, n := range received {
+		if n != i {
+			t.Fatalf("message %d delivered out of order: got %d", i, n)
+		}
+	}
+
+	if stats := dispatcher.Stats(); len(stats) != 1 || stats[0].Processed != 100 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDispatcherDropPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		want   string // Message left in the queue once it is full
+	}{
+		{name: "drop newest", policy: DropNewest, want: "1"},
+		{name: "drop oldest", policy: DropOldest, want: "3"},
+		{name: "close subscription", policy: CloseSubscription, want: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := NewDispatcher()
+			defer dispatcher.Close()
+
+			release := make(chan struct{})
+			delivered := make(chan string, 4)
+
+			dispatcher.Register("ticker", QueueOptions{Capacity: 1, Policy: tt.policy}, func(message []byte) {
+				<-release
+				delivered <- string(message)
+			})
+
+			// The worker takes the first message and blocks, leaving one queue slot
+			dispatcher.Dispatch("ticker", []byte("0"))
+			waitFor(t, time.Second, func() bool { return dispatcher.Stats()[0].Depth == 0 })
+
+			dispatcher.Dispatch("ticker", []byte("1"))
+			dispatcher.Dispatch("ticker", []byte("2"))
+			dispatcher.Dispatch("ticker", []byte("3"))
+
+			if dropped := dispatcher.Stats()[0].Dropped; dropped != 2 {
+				t.Errorf("expected 2 dropped messages, got %d", dropped)
+			}
+
+			close(release)
+			<-delivered
+
+			if got := <-delivered; got != tt.want {
+				t.Errorf("expected %s to survive, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDispatcherUnknownKey(t *testing.T) {
+	dispatcher := NewDispatcher()
+	defer dispatcher.Close()
+
+	if dispatcher.Dispatch("missing", []byte("x")) {
+		t.Error("expected Dispatch() to an unknown key to return false")
+	}
+}
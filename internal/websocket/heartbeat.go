@@ -0,0 +1,196 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Delay before retrying a failed connection rotation
+const rotationRetryDelay = 30 * time.Second
+
+// Heartbeat, deadline and rotation settings
+type HeartbeatConfig struct {
+	PingInterval     time.Duration // Interval between ping frames, 0 disables pings
+	PongWait         time.Duration // Max silence before the connection is considered dead, 0 disables read deadlines
+	WriteWait        time.Duration // Deadline for writing a single frame
+	MaxConnectionAge time.Duration // Rotate the connection once it reaches this age, 0 disables rotation
+	RotationGrace    time.Duration // How long a rotated-out connection stays open to drain in-flight responses
+}
+
+// Heartbeat settings suitable for the Binance WebSocket API.
+// The server sends a ping every 20s and drops connections after 24h.
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		PingInterval:     15 * time.Second,
+		PongWait:         60 * time.Second,
+		WriteWait:        10 * time.Second,
+		MaxConnectionAge: 23 * time.Hour,
+		RotationGrace:    30 * time.Second,
+	}
+}
+
+// Override the default heartbeat settings
+func WithHeartbeat(config HeartbeatConfig) Option {
+	return func(c *Client) {
+		c.heartbeat = config
+	}
+}
+
+// Round trip time of the most recent ping, zero until the first pong arrives
+func (c *Client) Latency() time.Duration {
+	return time.Duration(c.latency.Load())
+}
+
+// Underlying gorilla connection, nil for connections without WebSocket control frames
+func (cn *wsConn) gorilla() *websocket.Conn {
+	if g, ok := cn.conn.(*gorillaConn); ok {
+		return g.conn
+	}
+
+	return nil
+}
+
+// Install ping and pong handlers that keep the read deadline alive
+func (c *Client) installHeartbeatHandlers(cn *wsConn) {
+	ws := cn.gorilla()
+	if ws == nil {
+		return
+	}
+
+	c.extendReadDeadline(cn)
+
+	ws.SetPingHandler(func(appData string) error {
+		c.extendReadDeadline(cn)
+
+		err := ws.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(c.heartbeat.WriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+
+		return err
+	})
+
+	ws.SetPongHandler(func(appData string) error {
+		c.extendReadDeadline(cn)
+
+		// Pings carry their send time so the round trip can be measured
+		sentAt, err := strconv.ParseInt(appData, 10, 64)
+		if err == nil {
+			c.latency.Store(time.Now().UnixNano() - sentAt)
+		}
+
+		return nil
+	})
+}
+
+// Push the read deadline forward after any sign of life
+func (c *Client) extendReadDeadline(cn *wsConn) {
+	if ws := cn.gorilla(); ws != nil && c.heartbeat.PongWait > 0 {
+		ws.SetReadDeadline(time.Now().Add(c.heartbeat.PongWait))
+	}
+}
+
+// Send ping frames until the connection is retired
+func (c *Client) runHeartbeat(cn *wsConn) {
+	ws := cn.gorilla()
+	if ws == nil || c.heartbeat.PingInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.heartbeat.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+
+		case <-cn.closed:
+			return
+
+		case <-ticker.C:
+			payload := strconv.FormatInt(time.Now().UnixNano(), 10)
+			deadline := time.Now().Add(c.heartbeat.WriteWait)
+
+			if err := ws.WriteControl(websocket.PingMessage, []byte(payload), deadline); err != nil {
+				slog.Warn("Error sending ping, attempting reconnect", "error", err)
+				c.attemptReconnect(cn)
+				return
+			}
+		}
+	}
+}
+
+// Rotate the connection before the server drops it
+func (c *Client) rotationLoop() {
+	if c.heartbeat.MaxConnectionAge <= 0 {
+		return
+	}
+
+	for {
+		wait := c.heartbeat.MaxConnectionAge
+
+		c.mu.RLock()
+		if c.connection != nil {
+			wait = time.Until(c.connection.connectedAt.Add(c.heartbeat.MaxConnectionAge))
+		}
+		c.mu.RUnlock()
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-c.done:
+			timer.Stop()
+			return
+
+		case <-timer.C:
+			if err := c.rotate(); err != nil {
+				slog.Warn("Connection rotation failed", "error", err)
+
+				select {
+				case <-c.done:
+					return
+				case <-time.After(rotationRetryDelay):
+				}
+			}
+		}
+	}
+}
+
+// Open a new connection, switch traffic to it and drain the old one
+func (c *Client) rotate() error {
+	c.mu.RLock()
+	reconnecting := c.reconnecting || c.connection == nil
+	c.mu.RUnlock()
+
+	// A reconnect already produces a fresh connection
+	if reconnecting {
+		return nil
+	}
+
+	slog.Info("Rotating WebSocket connection")
+
+	cn, err := c.dial(context.Background())
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.connection
+	c.connection = cn
+	c.mu.Unlock()
+
+	c.startConnection(cn)
+
+	// Responses to requests sent on the old connection still arrive there
+	if old != nil {
+		time.AfterFunc(c.heartbeat.RotationGrace, old.close)
+	}
+
+	slog.Info("WebSocket connection rotated")
+	return nil
+}
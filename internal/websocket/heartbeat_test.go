@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Start a test server and count the connections it accepts
+func newTestServer(t *testing.T, handle func(conn *websocket.Conn)) (string, *atomic.Int32) {
+	t.Helper()
+
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		connections.Add(1)
+		handle(conn)
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), &connections
+}
+
+// Read frames so the default ping handler answers with pongs
+func readUntilClosed(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// Wait for a condition or fail the test
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("condition not met before timeout")
+}
+
+func TestHeartbeatMeasuresLatency(t *testing.T) {
+	url, _ := newTestServer(t, readUntilClosed)
+
+	client := New(url, "", "", WithHeartbeat(HeartbeatConfig{
+		PingInterval: 20 * time.Millisecond,
+		PongWait:     time.Second,
+		WriteWait:    time.Second,
+	}))
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	waitFor(t, 2*time.Second, func() bool { return client.Latency() > 0 })
+}
+
+func TestHeartbeatReconnectsDeadConnection(t *testing.T) {
+	// Never read, so pings are never answered
+	url, connections := newTestServer(t, func(conn *websocket.Conn) {
+		time.Sleep(2 * time.Second)
+	})
+
+	client := New(url, "", "", WithHeartbeat(HeartbeatConfig{
+		PingInterval: 20 * time.Millisecond,
+		PongWait:     100 * time.Millisecond,
+		WriteWait:    time.Second,
+	}))
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	waitFor(t, 3*time.Second, func() bool { return connections.Load() >= 2 })
+}
+
+func TestConnectionRotation(t *testing.T) {
+	url, connections := newTestServer(t, readUntilClosed)
+
+	client := New(url, "", "", WithHeartbeat(HeartbeatConfig{
+		WriteWait:        time.Second,
+		MaxConnectionAge: 100 * time.Millisecond,
+		RotationGrace:    50 * time.Millisecond,
+	}))
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	client.mu.RLock()
+	first := client.connection
+	client.mu.RUnlock()
+
+	waitFor(t, 2*time.Second, func() bool { return connections.Load() >= 2 })
+
+	// The rotated-out connection is retired after the grace period
+	select {
+	case <-first.closed:
+	case <-time.After(time.Second):
+		t.Error("old connection was not closed after rotation")
+	}
+
+	if _, err := client.SendRequest("ping", nil, nil); err != nil {
+		t.Errorf("SendRequest() after rotation returned error: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package websocket
+
+import (
+	"github.com/iamramtin/binance-trader/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reconnect attempts by client (api or stream) and result (success or failure)
+var reconnects = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "ws",
+	Name:      "reconnects_total",
+	Help:      "Reconnection attempts after a dropped WebSocket connection, by client and result.",
+}, []string{"client", "result"})
@@ -0,0 +1,190 @@
+package websocket
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Direction of a recorded frame
+type Direction string
+
+const (
+	Outbound Direction = "out" // Sent by the client
+	Inbound  Direction = "in"  // Received from the server
+)
+
+// Placeholder written in place of credentials
+const redacted = "REDACTED"
+
+// Request parameters that must never be written to a recording
+var sensitiveParams = []string{"apiKey", "signature"}
+
+// Single frame in a session recording
+type RecordedFrame struct {
+	Offset    time.Duration   `json:"offset"` // Monotonic time since the recording started
+	Conn      int             `json:"conn"`   // Client connection, numbered in the order clients connected
+	Direction Direction       `json:"dir"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Recorder writes every frame of a session to a gzip-compressed JSONL file.
+// One recorder can be shared by every client in a connection pool.
+type Recorder struct {
+	file     *os.File
+	gz       *gzip.Writer
+	encoder  *json.Encoder
+	start    time.Time // Carries a monotonic clock reading
+	nextConn int       // ID of the next client to connect
+	mu       sync.Mutex
+}
+
+// Create a recording at path, truncating any existing file
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	gz := gzip.NewWriter(file)
+
+	return &Recorder{
+		file:    file,
+		gz:      gz,
+		encoder: json.NewEncoder(gz),
+		start:   time.Now(),
+	}, nil
+}
+
+// Record frames sent and received by the client
+func WithRecorder(recorder *Recorder) Option {
+	return func(c *Client) {
+		c.recorder = recorder
+	}
+}
+
+// Number a newly connecting client
+func (r *Recorder) register() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextConn
+	r.nextConn++
+
+	return id
+}
+
+// Append a frame of a connection with credentials redacted
+func (r *Recorder) Record(conn int, direction Direction, data []byte) {
+	frame := RecordedFrame{
+		Conn:      conn,
+		Direction: direction,
+		Data:      redact(data),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	frame.Offset = time.Since(r.start)
+
+	if err := r.encoder.Encode(frame); err != nil {
+		slog.Warn("Error recording frame", "error", err)
+		return
+	}
+
+	// A session that exits without Close, or is killed, keeps every frame
+	if err := r.gz.Flush(); err != nil {
+		slog.Warn("Error flushing recording", "error", err)
+	}
+}
+
+// Flush and close the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.gz.Close(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("failed to flush recording: %w", err)
+	}
+
+	return r.file.Close()
+}
+
+// Read every frame from a recording, including one cut short before Close
+func LoadRecording(path string) ([]RecordedFrame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress recording: %w", err)
+	}
+	defer gz.Close()
+
+	var frames []RecordedFrame
+
+	decoder := json.NewDecoder(bufio.NewReader(gz))
+	for decoder.More() {
+		var frame RecordedFrame
+		err := decoder.Decode(&frame)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			// The session ended without Close, after its last flushed frame
+			slog.Warn("Recording was not closed, reading the frames up to where it ends", "path", path, "frames", len(frames))
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse recorded frame %d: %w", len(frames), err)
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// Replace credentials in a frame's params, keeping non-JSON frames as strings
+func redact(data []byte) json.RawMessage {
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		quoted, _ := json.Marshal(string(data))
+		return quoted
+	}
+
+	rawParams, exists := message["params"]
+	if !exists {
+		return json.RawMessage(data)
+	}
+
+	var params map[string]any
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return json.RawMessage(data)
+	}
+
+	changed := false
+	for _, key := range sensitiveParams {
+		if _, exists := params[key]; exists {
+			params[key] = redacted
+			changed = true
+		}
+	}
+
+	if !changed {
+		return json.RawMessage(data)
+	}
+
+	message["params"], _ = json.Marshal(params)
+	out, _ := json.Marshal(message)
+
+	return out
+}
@@ -0,0 +1,211 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Answer every request with a response carrying its method name
+func echoServer(conn *websocket.Conn) {
+	for {
+		var request models.WebSocketRequest
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+			`{"id":"%s","status":200,"result":{"method":"%s"}}`, request.ID, request.Method)))
+	}
+}
+
+// Send a request and wait for its raw response
+func roundTrip(t *testing.T, client *Client, method string, params any) string {
+	t.Helper()
+
+	responseCh := make(chan []byte, 1)
+	requestID, err := client.SendRequest(method, params, func(response []byte) {
+		responseCh <- response
+	})
+	if err != nil {
+		t.Fatalf("SendRequest(%s) returned error: %v", method, err)
+	}
+
+	select {
+	case response := <-responseCh:
+		var wsResponse models.WebSocketResponse
+		json.Unmarshal(response, &wsResponse)
+
+		if wsResponse.ID != requestID {
+			t.Errorf("response ID %s does not match request ID %s", wsResponse.ID, requestID)
+		}
+
+		return string(wsResponse.Result)
+
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for %s response", method)
+		return ""
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	url, _ := newTestServer(t, echoServer)
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+
+	client := New(url, "", "", WithRecorder(recorder))
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+
+	roundTrip(t, client, "account.status", map[string]string{"apiKey": "key123", "signature": "sig456", "timestamp": "1"})
+	roundTrip(t, client, "depth", map[string]any{"symbol": "BTCUSDT", "limit": 5})
+
+	client.Close()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	frames, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("LoadRecording() returned error: %v", err)
+	}
+
+	if len(frames) != 4 {
+		t.Fatalf("expected 4 recorded frames, got %d", len(frames))
+	}
+
+	for i, frame := range frames {
+		if strings.Contains(string(frame.Data), "key123") || strings.Contains(string(frame.Data), "sig456") {
+			t.Errorf("frame %d leaks credentials: %s", i, frame.Data)
+		}
+
+		if i > 0 && frame.Offset < frames[i-1].Offset {
+			t.Errorf("frame %d offset is not monotonic", i)
+		}
+	}
+
+	if frames[0].Direction != Outbound || frames[1].Direction != Inbound {
+		t.Errorf("unexpected directions: %s, %s", frames[0].Direction, frames[1].Direction)
+	}
+
+	// Replay the session without a server
+	replay := New("replay://", "", "", WithReplay(frames))
+	if err := replay.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() with replay returned error: %v", err)
+	}
+	defer replay.Close()
+
+	if got := roundTrip(t, replay, "account.status", nil); got != `{"method":"account.status"}` {
+		t.Errorf("unexpected replayed result: %s", got)
+	}
+
+	if got := roundTrip(t, replay, "depth", nil); got != `{"method":"depth"}` {
+		t.Errorf("unexpected replayed result: %s", got)
+	}
+}
+
+func TestReplayDivergence(t *testing.T) {
+	frames := []RecordedFrame{
+		{Direction: Outbound, Data: json.RawMessage(`{"id":"a","method":"depth"}`)},
+		{Direction: Inbound, Data: json.RawMessage(`{"id":"a","status":200}`)},
+	}
+
+	client := New("replay://", "", "", WithReplay(frames))
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.SendRequest("order.place", nil, nil); err == nil {
+		t.Error("expected a request that differs from the recording to fail")
+	}
+}
+
+func TestRecordAndReplayConnectionPool(t *testing.T) {
+	url, _ := newTestServer(t, echoServer)
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+
+	// Two pooled clients share the recorder, interleaving their frames
+	methods := []string{"order.place", "depth"}
+	clients := make([]*Client, len(methods))
+	for i := range clients {
+		clients[i] = New(url, "", "", WithRecorder(recorder))
+		if err := clients[i].Connect(context.Background()); err != nil {
+			t.Fatalf("Connect() returned error: %v", err)
+		}
+	}
+
+	roundTrip(t, clients[1], methods[1], nil)
+	roundTrip(t, clients[0], methods[0], nil)
+
+	for _, client := range clients {
+		client.Close()
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	frames, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("LoadRecording() returned error: %v", err)
+	}
+
+	// Each replayed client gets the connection it was recorded on
+	replay := WithReplay(frames)
+	for i, method := range methods {
+		client := New("replay://", "", "", replay)
+		if err := client.Connect(context.Background()); err != nil {
+			t.Fatalf("Connect() with replay returned error: %v", err)
+		}
+		defer client.Close()
+
+		if got := roundTrip(t, client, method, nil); got != fmt.Sprintf(`{"method":"%s"}`, method) {
+			t.Errorf("client %d: unexpected replayed result: %s", i, got)
+		}
+	}
+
+	if err := New("replay://", "", "", replay).Connect(context.Background()); err == nil {
+		t.Error("expected a third connection to be refused")
+	}
+}
+
+func TestLoadRecordingWithoutClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+
+	// A session that exits early never finishes the gzip stream
+	recorder.Record(0, Outbound, []byte(`{"id":"1","method":"ping"}`))
+	recorder.Record(0, Inbound, []byte(`{"id":"1","status":200}`))
+
+	frames, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("LoadRecording() returned error: %v", err)
+	}
+
+	if len(frames) != 2 || frames[1].Direction != Inbound {
+		t.Errorf("expected both frames of the unfinished recording, got %+v", frames)
+	}
+
+	recorder.Close()
+}
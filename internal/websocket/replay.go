@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Feed a recorded session back to the client instead of dialing the network.
+// Requests must be sent in the recorded order; inbound frames are released
+// as soon as the request that preceded them is written. Clients given the
+// same option share one recording, so a whole connection pool can be
+// replayed as long as its clients connect in the recorded order.
+func WithReplay(frames []RecordedFrame) Option {
+	transport := NewReplayTransport(frames)
+
+	return func(c *Client) {
+		c.transport = transport
+
+		// Heartbeats and rotation are not part of a recording
+		c.heartbeat = HeartbeatConfig{}
+	}
+}
+
+// Transport that serves each recorded connection to one Dial, in the order
+// they were connected
+type ReplayTransport struct {
+	conns  [][]RecordedFrame // Frames of each recorded connection
+	dialed int               // Connections served so far
+	mu     sync.Mutex
+}
+
+func NewReplayTransport(frames []RecordedFrame) *ReplayTransport {
+	var conns [][]RecordedFrame
+	for _, frame := range frames {
+		for len(conns) <= frame.Conn {
+			conns = append(conns, nil)
+		}
+
+		conns[frame.Conn] = append(conns[frame.Conn], frame)
+	}
+
+	return &ReplayTransport{conns: conns}
+}
+
+func (t *ReplayTransport) Dial(ctx context.Context, url string) (Conn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dialed >= len(t.conns) {
+		return nil, fmt.Errorf("replay: recording holds %d connections, all in use", len(t.conns))
+	}
+
+	frames := t.conns[t.dialed]
+	t.dialed++
+
+	return newReplayConn(frames), nil
+}
+
+// Connection that plays back a recording
+type replayConn struct {
+	frames  []RecordedFrame
+	pos     int               // Index of the next unplayed frame
+	ids     map[string]string // Maps recorded request IDs to the IDs sent during replay
+	inbound chan []byte
+	closed  chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+func newReplayConn(frames []RecordedFrame) *replayConn {
+	r := &replayConn{
+		frames:  frames,
+		ids:     make(map[string]string),
+		inbound: make(chan []byte, len(frames)),
+		closed:  make(chan struct{}),
+	}
+
+	// Frames received before the first request, e.g. unsolicited events
+	r.releaseInbound()
+
+	return r
+}
+
+func (r *replayConn) Read() ([]byte, error) {
+	select {
+	case message := <-r.inbound:
+		return message, nil
+	case <-r.closed:
+		return nil, io.EOF
+	}
+}
+
+// Match a request against the next recorded request and release its responses
+func (r *replayConn) Write(data []byte) error {
+	var request struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return fmt.Errorf("replay: failed to parse request: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pos >= len(r.frames) {
+		return fmt.Errorf("replay: recording exhausted at %s request", request.Method)
+	}
+
+	var recorded struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(r.frames[r.pos].Data, &recorded); err != nil {
+		return fmt.Errorf("replay: failed to parse recorded request %d: %w", r.pos, err)
+	}
+
+	if recorded.Method != request.Method {
+		return fmt.Errorf("replay diverged at frame %d: expected %s request, got %s", r.pos, recorded.Method, request.Method)
+	}
+
+	r.ids[recorded.ID] = request.ID
+	r.pos++
+	r.releaseInboundLocked()
+
+	return nil
+}
+
+func (r *replayConn) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+func (r *replayConn) releaseInbound() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.releaseInboundLocked()
+}
+
+// Queue inbound frames up to the next recorded request, rewriting response IDs
+func (r *replayConn) releaseInboundLocked() {
+	for r.pos < len(r.frames) && r.frames[r.pos].Direction == Inbound {
+		r.inbound <- r.rewriteID(r.frames[r.pos].Data)
+		r.pos++
+	}
+}
+
+func (r *replayConn) rewriteID(data json.RawMessage) []byte {
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		// Non-JSON frames are recorded as strings
+		var text string
+		if json.Unmarshal(data, &text) == nil {
+			return []byte(text)
+		}
+
+		return data
+	}
+
+	var id string
+	if err := json.Unmarshal(message["id"], &id); err != nil {
+		return data
+	}
+
+	replayID, exists := r.ids[id]
+	if !exists {
+		return data
+	}
+
+	message["id"], _ = json.Marshal(replayID)
+	out, _ := json.Marshal(message)
+
+	return out
+}
@@ -0,0 +1,479 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Binance limits incoming control messages to 5 per second per connection
+const controlMessageInterval = 200 * time.Millisecond
+
+// Timeout waiting for a control request acknowledgement
+const controlTimeout = 5 * time.Second
+
+// Market data streams client for the combined streams endpoint
+type StreamClient struct {
+	connection  *websocket.Conn
+	url         string
+	heartbeat   HeartbeatConfig
+	nextID      uint64                               // Next control request ID
+	pending     map[uint64]chan models.StreamMessage // Maps control request IDs to response channels
+	handlers    map[string]streamHandler             // Maps stream names to event handlers
+	dispatcher  *Dispatcher                          // Per-stream ordered delivery
+	lastControl time.Time                            // Time the last control message was sent
+	controlMu   sync.Mutex                           // Serialises control messages for rate limiting
+	mu          sync.RWMutex                         // Mutex for thread safety
+	done        chan struct{}                        // Channel to signal shutdown
+}
+
+// Delivery state for a single stream
+type streamHandler struct {
+	policy OverflowPolicy
+	stop   chan struct{} // Closed to release a delivery blocked on a slow consumer
+	close  func()        // Close the subscriber channel
+}
+
+// Adjust the dispatch queue of a subscription
+type SubscribeOption func(*QueueOptions)
+
+// Buffer up to n events for the subscription
+func WithQueueCapacity(n int) SubscribeOption {
+	return func(o *QueueOptions) {
+		o.Capacity = n
+	}
+}
+
+// Choose what happens when the subscription's queue is full. Block stalls
+// every stream on the connection, and its heartbeat, until the consumer
+// catches up.
+func WithOverflowPolicy(policy OverflowPolicy) SubscribeOption {
+	return func(o *QueueOptions) {
+		o.Policy = policy
+	}
+}
+
+// Typed market data subscription
+type Subscription[T any] struct {
+	Stream string   // Stream name, e.g. btcusdt@trade
+	C      <-chan T // Decoded events, closed on unsubscribe
+	client *StreamClient
+}
+
+// Stop receiving events for this subscription
+func (s *Subscription[T]) Unsubscribe() error {
+	return s.client.Unsubscribe(s.Stream)
+}
+
+// Create a new market data streams client.
+// The URL should point at the combined streams path, e.g. wss://stream.binance.com:9443/stream
+func NewStreamClient(url string) *StreamClient {
+	return &StreamClient{
+		url:        url,
+		heartbeat:  DefaultHeartbeatConfig(),
+		nextID:     1,
+		pending:    make(map[uint64]chan models.StreamMessage),
+		handlers:   make(map[string]streamHandler),
+		dispatcher: NewDispatcher(),
+		done:       make(chan struct{}),
+	}
+}
+
+// Establish a connection to the market data streams endpoint
+func (s *StreamClient) Connect(ctx context.Context) error {
+	slog.Info("Connecting to Binance market data streams", "url", s.url)
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to market data streams: %w", err)
+	}
+
+	s.mu.Lock()
+	s.connection = conn
+	s.mu.Unlock()
+
+	go s.readMessages(conn)
+
+	slog.Info("Connected to Binance market data streams")
+	return nil
+}
+
+func (s *StreamClient) Close() {
+	close(s.done)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connection != nil {
+		s.connection.Close()
+	}
+
+	for stream := range s.handlers {
+		s.removeHandler(stream)
+	}
+
+	s.dispatcher.Close()
+
+	slog.Info("Market data streams connection closed")
+}
+
+// Subscribe to raw trades for a symbol. A consumer that falls behind loses
+// the oldest trades by default.
+func (s *StreamClient) Trades(symbol string, opts ...SubscribeOption) (*Subscription[models.TradeEvent], error) {
+	return subscribe[models.TradeEvent](s, streamName(symbol, "trade"), DropOldest, opts)
+}
+
+// Subscribe to aggregated trades for a symbol. A consumer that falls behind
+// loses the oldest trades by default.
+func (s *StreamClient) AggTrades(symbol string, opts ...SubscribeOption) (*Subscription[models.AggTradeEvent], error) {
+	return subscribe[models.AggTradeEvent](s, streamName(symbol, "aggTrade"), DropOldest, opts)
+}
+
+// Subscribe to best bid and ask updates for a symbol. Stale updates are dropped by default.
+func (s *StreamClient) BookTicker(symbol string, opts ...SubscribeOption) (*Subscription[models.BookTickerEvent], error) {
+	return subscribe[models.BookTickerEvent](s, streamName(symbol, "bookTicker"), DropOldest, opts)
+}
+
+// Subscribe to candlesticks for a symbol, e.g. interval "1m"
+func (s *StreamClient) Klines(symbol string, interval string, opts ...SubscribeOption) (*Subscription[models.KlineEvent], error) {
+	return subscribe[models.KlineEvent](s, streamName(symbol, "kline_"+interval), DropOldest, opts)
+}
+
+// Subscribe to 100ms diff depth updates for a symbol.
+// A gap in diff updates corrupts a local book, so by default a consumer that
+// falls behind has its channel closed instead, to resubscribe from a snapshot.
+func (s *StreamClient) DepthUpdates(symbol string, opts ...SubscribeOption) (*Subscription[models.DepthUpdateEvent], error) {
+	return subscribe[models.DepthUpdateEvent](s, streamName(symbol, "depth@100ms"), CloseSubscription, opts)
+}
+
+// Subscribe to 100ms top of book snapshots for a symbol (5, 10 or 20 levels)
+func (s *StreamClient) PartialDepth(symbol string, levels int, opts ...SubscribeOption) (*Subscription[models.OrderbookDepth], error) {
+	return subscribe[models.OrderbookDepth](s, streamName(symbol, fmt.Sprintf("depth%d@100ms", levels)), DropOldest, opts)
+}
+
+// Subscribe to the rolling 24h mini ticker for a symbol
+func (s *StreamClient) MiniTicker(symbol string, opts ...SubscribeOption) (*Subscription[models.MiniTickerEvent], error) {
+	return subscribe[models.MiniTickerEvent](s, streamName(symbol, "miniTicker"), DropOldest, opts)
+}
+
+// Queue depth and handler latency for every subscription
+func (s *StreamClient) DispatchStats() []QueueStats {
+	return s.dispatcher.Stats()
+}
+
+// Stop receiving events for the given streams
+func (s *StreamClient) Unsubscribe(streams ...string) error {
+	if err := s.control("UNSUBSCRIBE", streams, nil); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stream := range streams {
+		s.removeHandler(stream)
+	}
+
+	return nil
+}
+
+// Streams the server currently has subscribed on this connection
+func (s *StreamClient) ListSubscriptions() ([]string, error) {
+	var streams []string
+	if err := s.control("LIST_SUBSCRIPTIONS", nil, &streams); err != nil {
+		return nil, err
+	}
+
+	return streams, nil
+}
+
+// Register a typed handler and subscribe to the stream
+func subscribe[T any](s *StreamClient, stream string, policy OverflowPolicy, opts []SubscribeOption) (*Subscription[T], error) {
+	options := QueueOptions{Capacity: defaultQueueCapacity, Policy: policy}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Buffered so a consumer that is briefly busy does not hold up the queue
+	ch := make(chan T, options.Capacity)
+	stop := make(chan struct{})
+
+	s.mu.Lock()
+	if _, exists := s.handlers[stream]; exists {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("already subscribed to %s", stream)
+	}
+
+	s.handlers[stream] = streamHandler{
+		policy: options.Policy,
+		stop:   stop,
+		close:  func() { close(ch) },
+	}
+
+	// Decode on the stream's own worker so events reach the consumer in order
+	s.dispatcher.Register(stream, options, func(data []byte) {
+		var event T
+		if err := json.Unmarshal(data, &event); err != nil {
+			slog.Warn("Error parsing market data event", "stream", stream, "error", err)
+			return
+		}
+
+		select {
+		case ch <- event:
+		case <-stop:
+		}
+	})
+	s.mu.Unlock()
+
+	if err := s.control("SUBSCRIBE", []string{stream}, nil); err != nil {
+		s.mu.Lock()
+		s.removeHandler(stream)
+		s.mu.Unlock()
+
+		return nil, err
+	}
+
+	return &Subscription[T]{Stream: stream, C: ch, client: s}, nil
+}
+
+// Stop delivery for a stream and close its channel. Callers must hold s.mu.
+func (s *StreamClient) removeHandler(stream string) {
+	handler, exists := s.handlers[stream]
+	if !exists {
+		return
+	}
+
+	close(handler.stop)
+	s.dispatcher.Remove(stream)
+	handler.close()
+	delete(s.handlers, stream)
+}
+
+// Stream names use the lowercase symbol
+func streamName(symbol string, suffix string) string {
+	return strings.ToLower(symbol) + "@" + suffix
+}
+
+// Send a rate-limited control request and wait for its acknowledgement
+func (s *StreamClient) control(method string, params []string, result any) error {
+	s.controlMu.Lock()
+	if wait := controlMessageInterval - time.Since(s.lastControl); wait > 0 {
+		time.Sleep(wait)
+	}
+	s.lastControl = time.Now()
+	s.controlMu.Unlock()
+
+	s.mu.Lock()
+	conn := s.connection
+	if conn == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("market data streams connection is not established")
+	}
+
+	request := models.StreamRequest{
+		Method: method,
+		Params: params,
+		ID:     s.nextID,
+	}
+	s.nextID++
+
+	responseCh := make(chan models.StreamMessage, 1)
+	s.pending[request.ID] = responseCh
+
+	if s.heartbeat.WriteWait > 0 {
+		conn.SetWriteDeadline(time.Now().Add(s.heartbeat.WriteWait))
+	}
+	err := conn.WriteJSON(request)
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, request.ID)
+		s.mu.Unlock()
+	}()
+
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case response := <-responseCh:
+		if response.Error != nil {
+			return fmt.Errorf("%s failed: %d - %s", method, response.Error.Code, response.Error.Msg)
+		}
+
+		if result != nil {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("error parsing %s result: %w", method, err)
+			}
+		}
+
+		return nil
+
+	case <-time.After(controlTimeout):
+		return fmt.Errorf("timeout waiting for %s acknowledgement", method)
+
+	case <-s.done:
+		return fmt.Errorf("market data streams client closed")
+	}
+}
+
+// Dial a new connection that answers server pings
+func (s *StreamClient) dial(ctx context.Context) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.extendReadDeadline(conn)
+
+	conn.SetPingHandler(func(appData string) error {
+		s.extendReadDeadline(conn)
+
+		err := conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(s.heartbeat.WriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+
+		return err
+	})
+
+	return conn, nil
+}
+
+func (s *StreamClient) extendReadDeadline(conn *websocket.Conn) {
+	if s.heartbeat.PongWait > 0 {
+		conn.SetReadDeadline(time.Now().Add(s.heartbeat.PongWait))
+	}
+}
+
+// Read and route messages in arrival order
+func (s *StreamClient) readMessages(conn *websocket.Conn) {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+
+			slog.Warn("Error reading market data message", "error", err)
+			go s.reconnect()
+			return
+		}
+
+		s.extendReadDeadline(conn)
+
+		var msg models.StreamMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			slog.Warn("Error parsing market data message", "error", err)
+			continue
+		}
+
+		s.route(msg)
+	}
+}
+
+// Queue an event for its stream or deliver a response to its waiting request
+func (s *StreamClient) route(msg models.StreamMessage) {
+	if msg.Stream != "" {
+		// Blocks only for subscriptions that chose Block, so no locks are held here
+		if !s.dispatcher.Dispatch(msg.Stream, msg.Data) {
+			s.closeLagging(msg.Stream)
+		}
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if msg.ID != nil {
+		if responseCh, exists := s.pending[*msg.ID]; exists {
+			responseCh <- msg
+		}
+	}
+}
+
+// Close a subscription with the CloseSubscription policy whose queue overflowed, so its
+// consumer can resubscribe rather than miss an event
+func (s *StreamClient) closeLagging(stream string) {
+	s.mu.Lock()
+	handler, exists := s.handlers[stream]
+	if !exists || handler.policy != CloseSubscription {
+		s.mu.Unlock()
+		return
+	}
+
+	slog.Warn("Closing market data subscription that fell behind", "stream", stream)
+	s.removeHandler(stream)
+	s.mu.Unlock()
+
+	// The acknowledgement arrives on this reader, so it is not awaited here
+	go func() {
+		if err := s.control("UNSUBSCRIBE", []string{stream}, nil); err != nil {
+			slog.Warn("Failed to unsubscribe lagging stream", "stream", stream, "error", err)
+		}
+	}()
+}
+
+// Reconnect with exponential backoff and restore subscriptions
+func (s *StreamClient) reconnect() {
+	s.mu.Lock()
+	if s.connection != nil {
+		s.connection.Close()
+		s.connection = nil
+	}
+	s.mu.Unlock()
+
+	delay := 1 * time.Second
+
+	for attempt := 1; ; attempt++ {
+		slog.Info("Attempting to reconnect market data streams", "attempt", attempt)
+
+		conn, err := s.dial(context.Background())
+		if err == nil {
+			s.mu.Lock()
+			s.connection = conn
+			streams := make([]string, 0, len(s.handlers))
+			for stream := range s.handlers {
+				streams = append(streams, stream)
+			}
+			s.mu.Unlock()
+
+			go s.readMessages(conn)
+
+			if len(streams) > 0 {
+				if err := s.control("SUBSCRIBE", streams, nil); err != nil {
+					slog.Error("Failed to restore market data subscriptions", "error", err)
+				}
+			}
+
+			slog.Info("Market data streams reconnected")
+			reconnects.WithLabelValues("stream", "success").Inc()
+			return
+		}
+
+		slog.Warn("Market data reconnection failed", "error", err)
+		reconnects.WithLabelValues("stream", "failure").Inc()
+
+		select {
+		case <-s.done:
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, time.Minute)
+	}
+}
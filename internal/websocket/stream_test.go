@@ -0,0 +1,195 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/iamramtin/binance-trader/internal/models"
+)
+
+// Acknowledge control requests and publish one trade per subscribed stream
+func fakeStreamServer(conn *websocket.Conn) {
+	var subscribed []string
+
+	for {
+		var request models.StreamRequest
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		switch request.Method {
+		case "SUBSCRIBE":
+			subscribed = append(subscribed, request.Params...)
+			conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"result":null,"id":%d}`, request.ID)))
+
+			for _, stream := range request.Params {
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+					`{"stream":"%s","data":{"e":"trade","E":1,"s":"BTCUSDT","t":42,"p":"40000.01","q":"0.5","T":1,"m":true,"M":true}}`,
+					stream)))
+			}
+
+		case "LIST_SUBSCRIPTIONS":
+			result := `[]`
+			if len(subscribed) > 0 {
+				result = fmt.Sprintf(`["%s"]`, subscribed[0])
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"result":%s,"id":%d}`, result, request.ID)))
+
+		default:
+			conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+				`{"error":{"code":2,"msg":"Invalid request"},"id":%d}`, request.ID)))
+		}
+	}
+}
+
+func TestStreamClientTrades(t *testing.T) {
+	url, _ := newTestServer(t, fakeStreamServer)
+
+	client := NewStreamClient(url)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	sub, err := client.Trades("BTCUSDT")
+	if err != nil {
+		t.Fatalf("Trades() returned error: %v", err)
+	}
+
+	if sub.Stream != "btcusdt@trade" {
+		t.Errorf("expected stream btcusdt@trade, got %s", sub.Stream)
+	}
+
+	select {
+	case trade := <-sub.C:
+		if trade.TradeID != 42 || trade.Price != "40000.01" || !trade.IsBuyerMaker {
+			t.Errorf("unexpected trade event: %+v", trade)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for trade event")
+	}
+
+	streams, err := client.ListSubscriptions()
+	if err != nil {
+		t.Fatalf("ListSubscriptions() returned error: %v", err)
+	}
+
+	if len(streams) != 1 || streams[0] != "btcusdt@trade" {
+		t.Errorf("unexpected subscriptions: %v", streams)
+	}
+
+	if _, err := client.Trades("btcusdt"); err == nil {
+		t.Error("expected duplicate subscription to fail")
+	}
+}
+
+func TestStreamClientControlError(t *testing.T) {
+	url, _ := newTestServer(t, fakeStreamServer)
+
+	client := NewStreamClient(url)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	// The fake server rejects UNSUBSCRIBE
+	if err := client.Unsubscribe("btcusdt@trade"); err == nil {
+		t.Error("expected rejected control request to return an error")
+	}
+}
+
+func TestStreamClientControlRateLimit(t *testing.T) {
+	url, _ := newTestServer(t, fakeStreamServer)
+
+	client := NewStreamClient(url)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	for range 3 {
+		if _, err := client.ListSubscriptions(); err != nil {
+			t.Fatalf("ListSubscriptions() returned error: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 2*controlMessageInterval {
+		t.Errorf("expected control messages to be spaced out, took %v", elapsed)
+	}
+}
+
+// Acknowledge control requests and publish a burst of events per subscribed stream
+func floodStreamServer(conn *websocket.Conn) {
+	for {
+		var request models.StreamRequest
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		result := "null"
+		if request.Method == "LIST_SUBSCRIPTIONS" {
+			result = "[]"
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"result":%s,"id":%d}`, result, request.ID)))
+
+		if request.Method != "SUBSCRIBE" {
+			continue
+		}
+
+		for _, stream := range request.Params {
+			for i := range 20 {
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"stream":"%s","data":{"u":%d}}`, stream, i)))
+			}
+		}
+	}
+}
+
+func TestStreamClientSlowConsumers(t *testing.T) {
+	url, _ := newTestServer(t, floodStreamServer)
+
+	client := NewStreamClient(url)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer client.Close()
+
+	// Neither subscription is read while the server floods both
+	trades, err := client.Trades("BTCUSDT", WithQueueCapacity(2))
+	if err != nil {
+		t.Fatalf("Trades() returned error: %v", err)
+	}
+
+	depth, err := client.DepthUpdates("BTCUSDT", WithQueueCapacity(2))
+	if err != nil {
+		t.Fatalf("DepthUpdates() returned error: %v", err)
+	}
+
+	// The reader still answers control requests
+	if _, err := client.ListSubscriptions(); err != nil {
+		t.Fatalf("expected a slow consumer not to stall the connection: %v", err)
+	}
+
+	// Depth updates must not skip, so the lagging subscription is closed
+	timeout := time.After(2 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-depth.C:
+		case <-timeout:
+			t.Fatal("expected the lagging depth subscription to be closed")
+		}
+	}
+
+	// Trades keep flowing with the oldest dropped
+	select {
+	case _, ok := <-trades.C:
+		if !ok {
+			t.Error("expected the trades subscription to stay open")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected buffered trades")
+	}
+}
@@ -0,0 +1,144 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transport opens message-oriented connections for a Client
+type Transport interface {
+	Dial(ctx context.Context, url string) (Conn, error)
+}
+
+// Conn carries whole text messages in both directions
+type Conn interface {
+	Read() ([]byte, error)
+	Write(data []byte) error
+	Close() error
+}
+
+// Use a custom transport instead of gorilla/websocket
+func WithTransport(transport Transport) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// Transport backed by gorilla/websocket, the default
+type GorillaTransport struct {
+	WriteWait time.Duration // Deadline for writing a single message
+}
+
+// Connection backed by gorilla/websocket
+type gorillaConn struct {
+	conn      *websocket.Conn
+	writeWait time.Duration
+}
+
+func (t *GorillaTransport) Dial(ctx context.Context, url string) (Conn, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gorillaConn{conn: conn, writeWait: t.WriteWait}, nil
+}
+
+func (g *gorillaConn) Read() ([]byte, error) {
+	_, message, err := g.conn.ReadMessage()
+	return message, err
+}
+
+func (g *gorillaConn) Write(data []byte) error {
+	if g.writeWait > 0 {
+		g.conn.SetWriteDeadline(time.Now().Add(g.writeWait))
+	}
+
+	return g.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (g *gorillaConn) Close() error {
+	return g.conn.Close()
+}
+
+// Error returned when using a closed pipe
+var ErrPipeClosed = errors.New("pipe closed")
+
+// In-memory transport that hands the server side of each connection to a handler.
+// Useful for scripting exchange responses in tests without a network.
+type PipeTransport struct {
+	handler func(server Conn)
+}
+
+func NewPipeTransport(handler func(server Conn)) *PipeTransport {
+	return &PipeTransport{handler: handler}
+}
+
+func (t *PipeTransport) Dial(ctx context.Context, url string) (Conn, error) {
+	client, server := Pipe()
+	go t.handler(server)
+
+	return client, nil
+}
+
+// One end of an in-memory connection
+type pipeConn struct {
+	in     <-chan []byte
+	out    chan<- []byte
+	closed chan struct{} // Shared by both ends
+	once   *sync.Once
+}
+
+// Create a connected pair of in-memory connections.
+// Closing either end closes both.
+func Pipe() (Conn, Conn) {
+	aToB := make(chan []byte, 16)
+	bToA := make(chan []byte, 16)
+	closed := make(chan struct{})
+	once := &sync.Once{}
+
+	a := &pipeConn{in: bToA, out: aToB, closed: closed, once: once}
+	b := &pipeConn{in: aToB, out: bToA, closed: closed, once: once}
+
+	return a, b
+}
+
+func (p *pipeConn) Read() ([]byte, error) {
+	select {
+	case message := <-p.in:
+		return message, nil
+	case <-p.closed:
+		return nil, io.EOF
+	}
+}
+
+func (p *pipeConn) Write(data []byte) error {
+	message := append([]byte(nil), data...)
+
+	select {
+	case <-p.closed:
+		return ErrPipeClosed
+	default:
+	}
+
+	select {
+	case p.out <- message:
+		return nil
+	case <-p.closed:
+		return ErrPipeClosed
+	}
+}
+
+func (p *pipeConn) Close() error {
+	p.once.Do(func() { close(p.closed) })
+	return nil
+}